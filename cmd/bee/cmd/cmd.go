@@ -13,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/localstore"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/sirupsen/logrus"
@@ -23,6 +24,8 @@ import (
 const (
 	optionNameDataDir                    = "data-dir"
 	optionNameCacheCapacity              = "cache-capacity"
+	optionNameCacheGCPolicy              = "cache-gc-policy"
+	optionNameCacheGCProtect             = "cache-gc-protect"
 	optionNameDBOpenFilesLimit           = "db-open-files-limit"
 	optionNameDBBlockCacheCapacity       = "db-block-cache-capacity"
 	optionNameDBWriteBufferSize          = "db-write-buffer-size"
@@ -48,12 +51,16 @@ const (
 	optionNameTracingServiceName         = "tracing-service-name"
 	optionNameVerbosity                  = "verbosity"
 	optionNameGlobalPinningEnabled       = "global-pinning-enable"
+	optionNamePssMailboxEnabled          = "pss-mailbox-enable"
 	optionNamePaymentThreshold           = "payment-threshold"
 	optionNamePaymentTolerance           = "payment-tolerance"
 	optionNamePaymentEarly               = "payment-early"
 	optionNameResolverEndpoints          = "resolver-options"
 	optionNameBootnodeMode               = "bootnode-mode"
 	optionNameGatewayMode                = "gateway-mode"
+	optionNameGatewayMaxUploadSize       = "gateway-max-upload-size"
+	optionNameGatewayContentTypes        = "gateway-content-types"
+	optionNameGatewayMaxManifestFiles    = "gateway-max-manifest-files"
 	optionNameClefSignerEnable           = "clef-signer-enable"
 	optionNameClefSignerEndpoint         = "clef-signer-endpoint"
 	optionNameClefSignerEthereumAddress  = "clef-signer-ethereum-address"
@@ -71,6 +78,20 @@ const (
 	optionNameBlockTime                  = "block-time"
 	optionWarmUpTime                     = "warmup-time"
 	optionNameMainNet                    = "mainnet"
+	optionNameWebhookURL                 = "webhook-url"
+	optionNameWebhookBatchTTLThreshold   = "webhook-batch-ttl-threshold"
+	optionNameWebhookChequebookThreshold = "webhook-chequebook-threshold"
+	optionNameRestrictedAPI              = "restricted"
+	optionNameAdminPasswordHash          = "admin-password"
+	optionNameRateLimitWindow            = "rate-limit-window"
+	optionNameRateLimitIPRequests        = "rate-limit-ip-requests"
+	optionNameRateLimitIPUpload          = "rate-limit-ip-upload"
+	optionNameRateLimitIPDownload        = "rate-limit-ip-download"
+	optionNameRateLimitTokenRequests     = "rate-limit-token-requests"
+	optionNameRateLimitTokenUpload       = "rate-limit-token-upload"
+	optionNameRateLimitTokenDownload     = "rate-limit-token-download"
+	optionNameRateLimitTrustedProxies    = "rate-limit-trusted-proxies"
+	optionNameAccessStatsSampleRate      = "access-stats-sample-rate"
 )
 
 func init() {
@@ -201,6 +222,8 @@ func (c *command) setHomeDir() (err error) {
 func (c *command) setAllFlags(cmd *cobra.Command) {
 	cmd.Flags().String(optionNameDataDir, filepath.Join(c.homeDir, ".bee"), "data directory")
 	cmd.Flags().Uint64(optionNameCacheCapacity, 1000000, fmt.Sprintf("cache capacity in chunks, multiply by %d to get approximate capacity in bytes", swarm.ChunkSize))
+	cmd.Flags().String(optionNameCacheGCPolicy, localstore.GCPolicyLRU, "cache garbage collection policy, lru or lfu")
+	cmd.Flags().Duration(optionNameCacheGCProtect, 0, "do not evict the cached chunks requested within the duration unless the cache exceeds its capacity by 10%")
 	cmd.Flags().Uint64(optionNameDBOpenFilesLimit, 200, "number of open files allowed by database")
 	cmd.Flags().Uint64(optionNameDBBlockCacheCapacity, 32*1024*1024, "size of block cache of the database in bytes")
 	cmd.Flags().Uint64(optionNameDBWriteBufferSize, 32*1024*1024, "size of the database write buffer in bytes")
@@ -226,11 +249,15 @@ func (c *command) setAllFlags(cmd *cobra.Command) {
 	cmd.Flags().String(optionNameVerbosity, "info", "log verbosity level 0=silent, 1=error, 2=warn, 3=info, 4=debug, 5=trace")
 	cmd.Flags().String(optionWelcomeMessage, "", "send a welcome message string during handshakes")
 	cmd.Flags().Bool(optionNameGlobalPinningEnabled, false, "enable global pinning")
+	cmd.Flags().Bool(optionNamePssMailboxEnabled, false, "keep undeliverable pss messages for the recipients to query later")
 	cmd.Flags().String(optionNamePaymentThreshold, "100000000", "threshold in BZZ where you expect to get paid from your peers")
 	cmd.Flags().String(optionNamePaymentTolerance, "100000000", "excess debt above payment threshold in BZZ where you disconnect from your peer")
 	cmd.Flags().String(optionNamePaymentEarly, "10000000", "amount in BZZ below the peers payment threshold when we initiate settlement")
 	cmd.Flags().StringSlice(optionNameResolverEndpoints, []string{}, "ENS compatible API endpoint for a TLD and with contract address, can be repeated, format [tld:][contract-addr@]url")
 	cmd.Flags().Bool(optionNameGatewayMode, false, "disable a set of sensitive features in the api")
+	cmd.Flags().Int64(optionNameGatewayMaxUploadSize, 0, "maximal size in bytes of the uploads in gateway mode, unlimited if zero")
+	cmd.Flags().StringSlice(optionNameGatewayContentTypes, []string{}, "media types allowed to be uploaded in gateway mode, type/* allows all the subtypes, any if empty")
+	cmd.Flags().Int(optionNameGatewayMaxManifestFiles, 0, "maximal number of files in the directories uploaded in gateway mode, unlimited if zero")
 	cmd.Flags().Bool(optionNameBootnodeMode, false, "cause the node to always accept incoming connections")
 	cmd.Flags().Bool(optionNameClefSignerEnable, false, "enable clef signer")
 	cmd.Flags().String(optionNameClefSignerEndpoint, "", "clef signer endpoint")
@@ -249,6 +276,20 @@ func (c *command) setAllFlags(cmd *cobra.Command) {
 	cmd.Flags().String(optionNameSwapDeploymentGasPrice, "", "gas price in wei to use for deployment and funding")
 	cmd.Flags().Duration(optionWarmUpTime, time.Minute*20, "time to warmup the node before pull/push protocols can be kicked off.")
 	cmd.Flags().Bool(optionNameMainNet, false, "triggers connect to main net bootnodes.")
+	cmd.Flags().StringSlice(optionNameWebhookURL, []string{}, "endpoints to post the node events to")
+	cmd.Flags().Duration(optionNameWebhookBatchTTLThreshold, 24*time.Hour, "time to live of a postage batch below which a webhook event is sent")
+	cmd.Flags().String(optionNameWebhookChequebookThreshold, "", "available chequebook balance below which a webhook event is sent, not checked if empty")
+	cmd.Flags().Bool(optionNameRestrictedAPI, false, "enable the api access with the bearer keys issued to the admin")
+	cmd.Flags().String(optionNameAdminPasswordHash, "", "bcrypt hash of the admin password issuing the api keys")
+	cmd.Flags().Duration(optionNameRateLimitWindow, time.Minute, "time window of the api rate limits, the clients are not limited if zero")
+	cmd.Flags().Int64(optionNameRateLimitIPRequests, 0, "api requests allowed per client ip address within the rate limit window, unlimited if zero")
+	cmd.Flags().Int64(optionNameRateLimitIPUpload, 0, "bytes a client ip address may upload within the rate limit window, unlimited if zero")
+	cmd.Flags().Int64(optionNameRateLimitIPDownload, 0, "bytes a client ip address may download within the rate limit window, unlimited if zero")
+	cmd.Flags().Int64(optionNameRateLimitTokenRequests, 0, "api requests allowed per bearer key within the rate limit window, unlimited if zero")
+	cmd.Flags().Int64(optionNameRateLimitTokenUpload, 0, "bytes a bearer key may upload within the rate limit window, unlimited if zero")
+	cmd.Flags().Int64(optionNameRateLimitTokenDownload, 0, "bytes a bearer key may download within the rate limit window, unlimited if zero")
+	cmd.Flags().StringSlice(optionNameRateLimitTrustedProxies, []string{}, "ip addresses or cidr ranges of the reverse proxies in front of the api, their X-Forwarded-For headers are used to tell the client ip address")
+	cmd.Flags().Int(optionNameAccessStatsSampleRate, 0, "count the requested content for the debug api hot content report, sampling one in the number of requests, disabled if zero")
 }
 
 func newLogger(cmd *cobra.Command, verbosity string) (logging.Logger, error) {
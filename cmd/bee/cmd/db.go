@@ -12,10 +12,23 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/ethersphere/bee/pkg/integrity"
 	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/node"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/postage/batchstore"
+	"github.com/ethersphere/bee/pkg/statestore/leveldb"
+	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/spf13/cobra"
 )
 
+const (
+	optionNameExportRoot        = "root"
+	optionNameExportPinned      = "pinned"
+	optionNameExportSince       = "since"
+	optionNameExportCompression = "compression"
+)
+
 func (c *command) initDBCmd() {
 	cmd := &cobra.Command{
 		Use:   "db",
@@ -24,6 +37,9 @@ func (c *command) initDBCmd() {
 
 	dbExportCmd(cmd)
 	dbImportCmd(cmd)
+	dbVerifyCmd(cmd)
+	dbBackupStateCmd(cmd)
+	dbRestoreStateCmd(cmd)
 
 	c.root.AddCommand(cmd)
 }
@@ -54,6 +70,11 @@ func dbExportCmd(cmd *cobra.Command) {
 				return errors.New("no data-dir provided")
 			}
 
+			o, err := exportOptions(cmd)
+			if err != nil {
+				return err
+			}
+
 			logger.Infof("starting export process with data-dir at %s", dataDir)
 
 			path := filepath.Join(dataDir, "localstore")
@@ -74,7 +95,7 @@ func dbExportCmd(cmd *cobra.Command) {
 				defer f.Close()
 				out = f
 			}
-			c, err := storer.Export(out)
+			c, err := storer.ExportSelected(cmd.Context(), out, o)
 			if err != nil {
 				return fmt.Errorf("error exporting database: %v", err)
 			}
@@ -86,9 +107,60 @@ func dbExportCmd(cmd *cobra.Command) {
 	}
 	c.Flags().String(optionNameDataDir, "", "data directory")
 	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	c.Flags().StringSlice(optionNameExportRoot, nil, "export only the chunks under the root references, can be repeated")
+	c.Flags().Bool(optionNameExportPinned, false, "export only the pinned chunks")
+	c.Flags().String(optionNameExportSince, "", "export only the chunks stored after the previous export in the file")
+	c.Flags().String(optionNameExportCompression, localstore.CompressionNone, "compression of the export, empty, gzip or zstd")
 	cmd.AddCommand(c)
 }
 
+// exportOptions constructs the export options from the flags of the export command.
+func exportOptions(cmd *cobra.Command) (o localstore.ExportOptions, err error) {
+	roots, err := cmd.Flags().GetStringSlice(optionNameExportRoot)
+	if err != nil {
+		return o, fmt.Errorf("get root: %v", err)
+	}
+	for _, r := range roots {
+		root, err := swarm.ParseHexAddress(r)
+		if err != nil {
+			return o, fmt.Errorf("invalid root reference %q: %v", r, err)
+		}
+		o.Roots = append(o.Roots, root)
+	}
+
+	o.Pinned, err = cmd.Flags().GetBool(optionNameExportPinned)
+	if err != nil {
+		return o, fmt.Errorf("get pinned: %v", err)
+	}
+
+	since, err := cmd.Flags().GetString(optionNameExportSince)
+	if err != nil {
+		return o, fmt.Errorf("get since: %v", err)
+	}
+	if since != "" {
+		f, err := os.Open(since)
+		if err != nil {
+			return o, fmt.Errorf("error opening previous export file: %s", err)
+		}
+		defer f.Close()
+
+		m, err := localstore.ReadExportManifest(f)
+		if err != nil {
+			return o, fmt.Errorf("read previous export manifest: %v", err)
+		}
+		if m == nil {
+			return o, errors.New("previous export has no manifest")
+		}
+		o.Since = m.BinIDs
+	}
+
+	o.Compression, err = cmd.Flags().GetString(optionNameExportCompression)
+	if err != nil {
+		return o, fmt.Errorf("get compression: %v", err)
+	}
+	return o, nil
+}
+
 func dbImportCmd(cmd *cobra.Command) {
 	c := &cobra.Command{
 		Use:   "import <filename>",
@@ -134,12 +206,213 @@ func dbImportCmd(cmd *cobra.Command) {
 				defer f.Close()
 				in = f
 			}
-			c, err := storer.Import(cmd.Context(), in)
+			report, err := storer.ImportWithReport(cmd.Context(), in)
 			if err != nil {
 				return fmt.Errorf("error importing database: %v", err)
 			}
 
-			fmt.Printf("database imported %d records successfully\n", c)
+			for name, reason := range report.Skipped {
+				fmt.Printf("skipped %s: %s\n", name, reason)
+			}
+			for _, name := range report.Missing {
+				fmt.Printf("missing %s\n", name)
+			}
+			for _, name := range report.Mismatched {
+				fmt.Printf("checksum mismatch %s\n", name)
+			}
+			fmt.Printf("database imported %d records, skipped %d, missing %d and mismatched %d\n", report.Imported, len(report.Skipped), len(report.Missing), len(report.Mismatched))
+
+			return nil
+		},
+	}
+	c.Flags().String(optionNameDataDir, "", "data directory")
+	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	cmd.AddCommand(c)
+}
+
+func dbVerifyCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "verify <reference>",
+		Short: "Verify that the content under the reference is fully present and intact in the DB",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if (len(args)) != 1 {
+				return cmd.Help()
+			}
+			root, err := swarm.ParseHexAddress(args[0])
+			if err != nil {
+				return fmt.Errorf("parse reference: %v", err)
+			}
+			v, err := cmd.Flags().GetString(optionNameVerbosity)
+			if err != nil {
+				return fmt.Errorf("get verbosity: %v", err)
+			}
+			v = strings.ToLower(v)
+			logger, err := newLogger(cmd, v)
+			if err != nil {
+				return fmt.Errorf("new logger: %v", err)
+			}
+			dataDir, err := cmd.Flags().GetString(optionNameDataDir)
+			if err != nil {
+				return fmt.Errorf("get data-dir: %v", err)
+			}
+			if dataDir == "" {
+				return errors.New("no data-dir provided")
+			}
+
+			logger.Infof("starting verification of %s with data-dir at %s", root, dataDir)
+
+			stateStore, err := node.InitStateStore(logger, dataDir)
+			if err != nil {
+				return fmt.Errorf("statestore: %w", err)
+			}
+			defer stateStore.Close()
+
+			batchStore, err := batchstore.New(stateStore, nil, logger)
+			if err != nil {
+				return fmt.Errorf("batchstore: %w", err)
+			}
+
+			path := filepath.Join(dataDir, "localstore")
+
+			storer, err := localstore.New(path, nil, nil, nil, logger)
+			if err != nil {
+				return fmt.Errorf("localstore: %w", err)
+			}
+			defer storer.Close()
+
+			r, err := integrity.New(storer, postage.ValidStamp(batchStore)).Verify(cmd.Context(), root)
+			if err != nil {
+				return fmt.Errorf("error verifying content: %v", err)
+			}
+
+			for _, a := range r.Missing {
+				cmd.Printf("missing: %s\n", a)
+			}
+			for _, a := range r.Corrupt {
+				cmd.Printf("corrupt: %s\n", a)
+			}
+			for _, a := range r.Unstamped {
+				cmd.Printf("unstamped: %s\n", a)
+			}
+			cmd.Printf("verified %d chunks: %d missing, %d corrupt, %d unstamped\n", r.Total, len(r.Missing), len(r.Corrupt), len(r.Unstamped))
+			if r.Incomplete {
+				cmd.Println("verification incomplete: content below missing or corrupt chunks could not be verified")
+			}
+
+			if !r.OK() {
+				return errors.New("content verification failed")
+			}
+			return nil
+		},
+	}
+	c.Flags().String(optionNameDataDir, "", "data directory")
+	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	cmd.AddCommand(c)
+}
+
+func dbBackupStateCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "backup-state <filename>",
+		Short: "Back up the state store of a stopped node to a file, use the debug API while the node runs. Use \"-\" as filename in order to write to STDOUT",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if (len(args)) != 1 {
+				return cmd.Help()
+			}
+			v, err := cmd.Flags().GetString(optionNameVerbosity)
+			if err != nil {
+				return fmt.Errorf("get verbosity: %v", err)
+			}
+			v = strings.ToLower(v)
+			logger, err := newLogger(cmd, v)
+			if err != nil {
+				return fmt.Errorf("new logger: %v", err)
+			}
+			dataDir, err := cmd.Flags().GetString(optionNameDataDir)
+			if err != nil {
+				return fmt.Errorf("get data-dir: %v", err)
+			}
+			if dataDir == "" {
+				return errors.New("no data-dir provided")
+			}
+
+			logger.Infof("starting state store backup with data-dir at %s", dataDir)
+
+			stateStore, err := node.InitStateStore(logger, dataDir)
+			if err != nil {
+				return fmt.Errorf("statestore: %w", err)
+			}
+			defer stateStore.Close()
+
+			var out io.Writer
+			if args[0] == "-" {
+				out = os.Stdout
+			} else {
+				f, err := os.Create(args[0])
+				if err != nil {
+					return fmt.Errorf("error opening output file: %s", err)
+				}
+				defer f.Close()
+				out = f
+			}
+			c, err := leveldb.Backup(stateStore.DB(), out)
+			if err != nil {
+				return fmt.Errorf("error backing up state store: %v", err)
+			}
+
+			logger.Infof("state store backed up %d entries successfully", c)
+
+			return nil
+		},
+	}
+	c.Flags().String(optionNameDataDir, "", "data directory")
+	c.Flags().String(optionNameVerbosity, "info", "verbosity level")
+	cmd.AddCommand(c)
+}
+
+func dbRestoreStateCmd(cmd *cobra.Command) {
+	c := &cobra.Command{
+		Use:   "restore-state <filename>",
+		Short: "Restore the state store from a backup file to a data-dir without one. Use \"-\" as filename in order to feed from STDIN",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if (len(args)) != 1 {
+				return cmd.Help()
+			}
+			v, err := cmd.Flags().GetString(optionNameVerbosity)
+			if err != nil {
+				return fmt.Errorf("get verbosity: %v", err)
+			}
+			v = strings.ToLower(v)
+			logger, err := newLogger(cmd, v)
+			if err != nil {
+				return fmt.Errorf("new logger: %v", err)
+			}
+			dataDir, err := cmd.Flags().GetString(optionNameDataDir)
+			if err != nil {
+				return fmt.Errorf("get data-dir: %v", err)
+			}
+			if dataDir == "" {
+				return errors.New("no data-dir provided")
+			}
+
+			logger.Infof("starting state store restore with data-dir at %s", dataDir)
+
+			var in io.Reader
+			if args[0] == "-" {
+				in = os.Stdin
+			} else {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return fmt.Errorf("error opening input file: %s", err)
+				}
+				defer f.Close()
+				in = f
+			}
+			c, err := leveldb.Restore(filepath.Join(dataDir, "statestore"), in, logger)
+			if err != nil {
+				return fmt.Errorf("error restoring state store: %v", err)
+			}
+
+			logger.Infof("state store restored %d entries successfully", c)
 
 			return nil
 		},
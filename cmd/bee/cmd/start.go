@@ -160,6 +160,8 @@ inability to use, or your interaction with other nodes or the software.`)
 			b, err := node.NewBee(c.config.GetString(optionNameP2PAddr), signerConfig.publicKey, signerConfig.signer, networkID, logger, signerConfig.libp2pPrivateKey, signerConfig.pssPrivateKey, &node.Options{
 				DataDir:                    c.config.GetString(optionNameDataDir),
 				CacheCapacity:              c.config.GetUint64(optionNameCacheCapacity),
+				CacheGCPolicy:              c.config.GetString(optionNameCacheGCPolicy),
+				CacheGCProtect:             c.config.GetDuration(optionNameCacheGCProtect),
 				DBOpenFilesLimit:           c.config.GetUint64(optionNameDBOpenFilesLimit),
 				DBBlockCacheCapacity:       c.config.GetUint64(optionNameDBBlockCacheCapacity),
 				DBWriteBufferSize:          c.config.GetUint64(optionNameDBWriteBufferSize),
@@ -179,11 +181,15 @@ inability to use, or your interaction with other nodes or the software.`)
 				TracingServiceName:         c.config.GetString(optionNameTracingServiceName),
 				Logger:                     logger,
 				GlobalPinningEnabled:       c.config.GetBool(optionNameGlobalPinningEnabled),
+				PssMailboxEnabled:          c.config.GetBool(optionNamePssMailboxEnabled),
 				PaymentThreshold:           c.config.GetString(optionNamePaymentThreshold),
 				PaymentTolerance:           c.config.GetString(optionNamePaymentTolerance),
 				PaymentEarly:               c.config.GetString(optionNamePaymentEarly),
 				ResolverConnectionCfgs:     resolverCfgs,
 				GatewayMode:                c.config.GetBool(optionNameGatewayMode),
+				GatewayMaxUploadSize:       c.config.GetInt64(optionNameGatewayMaxUploadSize),
+				GatewayContentTypes:        c.config.GetStringSlice(optionNameGatewayContentTypes),
+				GatewayMaxManifestFiles:    c.config.GetInt(optionNameGatewayMaxManifestFiles),
 				BootnodeMode:               bootNode,
 				SwapEndpoint:               c.config.GetString(optionNameSwapEndpoint),
 				SwapFactoryAddress:         c.config.GetString(optionNameSwapFactoryAddress),
@@ -199,6 +205,20 @@ inability to use, or your interaction with other nodes or the software.`)
 				DeployGasPrice:             c.config.GetString(optionNameSwapDeploymentGasPrice),
 				WarmupTime:                 c.config.GetDuration(optionWarmUpTime),
 				ChainID:                    networkConfig.chainID,
+				WebhookEndpoints:           c.config.GetStringSlice(optionNameWebhookURL),
+				WebhookBatchTTLThreshold:   c.config.GetDuration(optionNameWebhookBatchTTLThreshold),
+				WebhookChequebookThreshold: c.config.GetString(optionNameWebhookChequebookThreshold),
+				Restricted:                 c.config.GetBool(optionNameRestrictedAPI),
+				AdminPasswordHash:          c.config.GetString(optionNameAdminPasswordHash),
+				RateLimitWindow:            c.config.GetDuration(optionNameRateLimitWindow),
+				RateLimitIPRequests:        c.config.GetInt64(optionNameRateLimitIPRequests),
+				RateLimitIPUpload:          c.config.GetInt64(optionNameRateLimitIPUpload),
+				RateLimitIPDownload:        c.config.GetInt64(optionNameRateLimitIPDownload),
+				RateLimitTokenRequests:     c.config.GetInt64(optionNameRateLimitTokenRequests),
+				RateLimitTokenUpload:       c.config.GetInt64(optionNameRateLimitTokenUpload),
+				RateLimitTokenDownload:     c.config.GetInt64(optionNameRateLimitTokenDownload),
+				RateLimitTrustedProxies:    c.config.GetStringSlice(optionNameRateLimitTrustedProxies),
+				AccessStatsSampleRate:      c.config.GetInt(optionNameAccessStatsSampleRate),
 			})
 			if err != nil {
 				return err
@@ -0,0 +1,237 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package accessstats counts the requests of the root references and the
+// chunks over time windows to report the most requested content. The
+// requests are sampled and the number of counted addresses is bounded,
+// so the counts are estimates.
+package accessstats
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// maxAddresses is the maximal number of the addresses counted
+// in a time window, the least requested ones are dropped above it.
+const maxAddresses = 10000
+
+var (
+	// DefaultWindows are the time windows of the counts if not
+	// specified otherwise.
+	DefaultWindows = []time.Duration{time.Hour, 24 * time.Hour}
+
+	// ErrUnknownWindow is returned when reporting the counts of
+	// a time window that is not counted.
+	ErrUnknownWindow = errors.New("unknown window")
+)
+
+// Options are the options of the Service.
+type Options struct {
+	// SampleRate is the number of the requests per counted one,
+	// all the requests are counted if it is less than two.
+	SampleRate int
+	// Windows are the time windows of the counts,
+	// DefaultWindows if empty.
+	Windows []time.Duration
+}
+
+// Entry is the estimated number of requests of an address.
+type Entry struct {
+	Address swarm.Address
+	Count   uint64
+}
+
+// Service counts the requests of the root references and the chunks.
+// The methods of a nil Service do nothing, so that the counting can be
+// disabled by not constructing one.
+type Service struct {
+	sampleRate int
+	windows    []time.Duration
+
+	mu     sync.Mutex
+	roots  map[time.Duration]*counter
+	chunks map[time.Duration]*counter
+	now    func() time.Time
+}
+
+// New constructs a new Service.
+func New(o Options) *Service {
+	windows := o.Windows
+	if len(windows) == 0 {
+		windows = DefaultWindows
+	}
+	s := &Service{
+		sampleRate: o.SampleRate,
+		windows:    windows,
+		roots:      make(map[time.Duration]*counter),
+		chunks:     make(map[time.Duration]*counter),
+		now:        time.Now,
+	}
+	now := s.now()
+	for _, w := range windows {
+		s.roots[w] = newCounter(w, now)
+		s.chunks[w] = newCounter(w, now)
+	}
+	return s
+}
+
+// Windows returns the time windows of the counts.
+func (s *Service) Windows() []time.Duration {
+	if s == nil {
+		return nil
+	}
+	return s.windows
+}
+
+// RecordRoot counts a request of the content under the root reference.
+func (s *Service) RecordRoot(addr swarm.Address) {
+	if s == nil {
+		return
+	}
+	s.record(s.roots, addr)
+}
+
+// RecordChunk counts a request of the chunk.
+func (s *Service) RecordChunk(addr swarm.Address) {
+	if s == nil {
+		return
+	}
+	s.record(s.chunks, addr)
+}
+
+func (s *Service) record(counters map[time.Duration]*counter, addr swarm.Address) {
+	amount := uint64(1)
+	if s.sampleRate > 1 {
+		if rand.Intn(s.sampleRate) != 0 {
+			return
+		}
+		amount = uint64(s.sampleRate)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for _, c := range counters {
+		c.add(now, addr.ByteString(), amount)
+	}
+}
+
+// Top returns at most limit of the most requested root references and
+// chunks within the time window.
+func (s *Service) Top(window time.Duration, limit int) (roots, chunks []Entry, err error) {
+	if s == nil {
+		return nil, nil, ErrUnknownWindow
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.roots[window]
+	if !ok {
+		return nil, nil, ErrUnknownWindow
+	}
+	now := s.now()
+	return r.top(now, limit), s.chunks[window].top(now, limit), nil
+}
+
+// counter counts the requests within consecutive fixed time windows.
+// The count within the sliding window is estimated from the counts of
+// the current and the previous fixed windows.
+type counter struct {
+	window time.Duration
+	start  time.Time
+	prev   map[string]uint64
+	cur    map[string]uint64
+}
+
+func newCounter(window time.Duration, now time.Time) *counter {
+	return &counter{
+		window: window,
+		start:  now,
+		prev:   make(map[string]uint64),
+		cur:    make(map[string]uint64),
+	}
+}
+
+// rotate starts a new fixed window if the current one has passed.
+func (c *counter) rotate(now time.Time) {
+	elapsed := now.Sub(c.start)
+	if elapsed < c.window {
+		return
+	}
+	if elapsed < 2*c.window {
+		c.prev = c.cur
+	} else {
+		c.prev = make(map[string]uint64)
+	}
+	c.cur = make(map[string]uint64)
+	c.start = c.start.Add(elapsed.Truncate(c.window))
+}
+
+func (c *counter) add(now time.Time, key string, amount uint64) {
+	c.rotate(now)
+	c.cur[key] += amount
+	if len(c.cur) > maxAddresses {
+		prune(c.cur)
+	}
+}
+
+func (c *counter) top(now time.Time, limit int) []Entry {
+	c.rotate(now)
+
+	// the share of the previous fixed window
+	// still within the sliding window
+	share := 1 - float64(now.Sub(c.start))/float64(c.window)
+
+	counts := make(map[string]uint64, len(c.cur))
+	for k, n := range c.prev {
+		counts[k] = uint64(float64(n) * share)
+	}
+	for k, n := range c.cur {
+		counts[k] += n
+	}
+
+	entries := make([]Entry, 0, len(counts))
+	for k, n := range counts {
+		if n == 0 {
+			continue
+		}
+		entries = append(entries, Entry{
+			Address: swarm.NewAddress([]byte(k)),
+			Count:   n,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return bytes.Compare(entries[i].Address.Bytes(), entries[j].Address.Bytes()) < 0
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries
+}
+
+// prune drops the least requested half of the counts.
+func prune(counts map[string]uint64) {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return counts[keys[i]] < counts[keys[j]]
+	})
+	for _, k := range keys[:len(keys)/2] {
+		delete(counts, k)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package accessstats_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/accessstats"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestTop(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	s := accessstats.New(accessstats.Options{
+		Windows: []time.Duration{time.Hour},
+	})
+	accessstats.SetNow(s, func() time.Time { return now })
+
+	a := swarm.MustParseHexAddress("aa")
+	b := swarm.MustParseHexAddress("bb")
+	c := swarm.MustParseHexAddress("cc")
+
+	for i := 0; i < 4; i++ {
+		s.RecordRoot(a)
+	}
+	s.RecordRoot(b)
+	s.RecordRoot(c)
+	s.RecordChunk(b)
+	s.RecordChunk(b)
+
+	top := func(t *testing.T, limit int, wantRoots, wantChunks []accessstats.Entry) {
+		t.Helper()
+
+		roots, chunks, err := s.Top(time.Hour, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(roots, wantRoots) {
+			t.Fatalf("got roots %v, want %v", roots, wantRoots)
+		}
+		if !reflect.DeepEqual(chunks, wantChunks) {
+			t.Fatalf("got chunks %v, want %v", chunks, wantChunks)
+		}
+	}
+
+	t.Run("limit", func(t *testing.T) {
+		top(t, 2,
+			[]accessstats.Entry{{Address: a, Count: 4}, {Address: b, Count: 1}},
+			[]accessstats.Entry{{Address: b, Count: 2}},
+		)
+	})
+
+	t.Run("previous window", func(t *testing.T) {
+		// a quarter of the previous window is within the sliding one
+		now = now.Add(time.Hour + 45*time.Minute)
+		s.RecordRoot(c)
+		top(t, 0,
+			[]accessstats.Entry{{Address: a, Count: 1}, {Address: c, Count: 1}},
+			[]accessstats.Entry{},
+		)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		now = now.Add(2 * time.Hour)
+		top(t, 0, []accessstats.Entry{}, []accessstats.Entry{})
+	})
+
+	t.Run("unknown window", func(t *testing.T) {
+		if _, _, err := s.Top(time.Minute, 0); !errors.Is(err, accessstats.ErrUnknownWindow) {
+			t.Fatalf("got error %v, want %v", err, accessstats.ErrUnknownWindow)
+		}
+	})
+}
+
+func TestSampling(t *testing.T) {
+	s := accessstats.New(accessstats.Options{
+		SampleRate: 10,
+	})
+
+	a := swarm.MustParseHexAddress("aa")
+	for i := 0; i < 10000; i++ {
+		s.RecordChunk(a)
+	}
+
+	_, chunks, err := s.Top(accessstats.DefaultWindows[0], 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 1 {
+		t.Fatalf("got %d chunks, want 1", len(chunks))
+	}
+	// the count is a multiple of the sample rate close to the requests
+	if n := chunks[0].Count; n%10 != 0 || n < 8000 || n > 12000 {
+		t.Fatalf("got count %d, want about 10000", n)
+	}
+}
+
+func TestNil(t *testing.T) {
+	var s *accessstats.Service
+	s.RecordRoot(swarm.MustParseHexAddress("aa"))
+	s.RecordChunk(swarm.MustParseHexAddress("aa"))
+	if _, _, err := s.Top(time.Hour, 0); !errors.Is(err, accessstats.ErrUnknownWindow) {
+		t.Fatalf("got error %v, want %v", err, accessstats.ErrUnknownWindow)
+	}
+}
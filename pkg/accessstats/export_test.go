@@ -0,0 +1,18 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package accessstats
+
+import "time"
+
+// SetNow sets the time function and starts the
+// time windows at the time it returns.
+func SetNow(s *Service, now func() time.Time) {
+	s.now = now
+	for _, counters := range []map[time.Duration]*counter{s.roots, s.chunks} {
+		for _, c := range counters {
+			c.start = now()
+		}
+	}
+}
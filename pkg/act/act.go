@@ -0,0 +1,384 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package act provides access control of uploaded content through
+// access control tries.
+//
+// An access control trie (ACT) is a manifest which holds the reference of
+// the protected content encrypted with an access key. Every grantee has an
+// entry in the manifest under a lookup key derived with Diffie-Hellman from
+// the publisher and grantee keys, holding the access key encrypted with a key
+// derived in the same manner. Only the publisher and the grantees are able to
+// derive the keys needed to decrypt the content reference.
+//
+// Every version of the trie has a random salt mixed into all the key
+// derivations, so that the lookup keys and the keystreams of a version do
+// not reveal anything about the other versions.
+package act
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/encryption"
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/manifest"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// PublisherMetadataKey is the root metadata key of the publisher public key.
+	PublisherMetadataKey = "act-publisher"
+	// ReferenceMetadataKey is the root metadata key of the encrypted content reference.
+	ReferenceMetadataKey = "act-reference"
+	// GranteesMetadataKey is the root metadata key of the encrypted grantee list reference.
+	GranteesMetadataKey = "act-grantees"
+	// SaltMetadataKey is the root metadata key of the random salt of the
+	// access control trie version.
+	SaltMetadataKey = "act-salt"
+)
+
+const (
+	// publicKeyLength is the length of the compressed public key.
+	publicKeyLength = 33
+	// saltLength is the length of the random salt of a version.
+	saltLength = 32
+)
+
+var (
+	// ErrNotGrantee is returned when the node key is not a grantee of the
+	// access control trie.
+	ErrNotGrantee = errors.New("act: not a grantee")
+	// ErrNotPublisher is returned when an operation that is reserved for
+	// the publisher is performed with a different node key.
+	ErrNotPublisher = errors.New("act: not the publisher")
+	// ErrInvalid is returned when the manifest is not an access control trie.
+	ErrInvalid = errors.New("act: invalid access control trie")
+)
+
+var (
+	lookupKeySalt        = []byte{0}
+	accessKeyDecryptSalt = []byte{1}
+	granteeListSalt      = []byte{2}
+)
+
+// Interface defines access control trie operations.
+type Interface interface {
+	// Create creates an access control trie that protects the reference
+	// with the publisher and the provided public keys as grantees.
+	Create(ctx context.Context, ls file.LoadSaver, reference swarm.Address, grantees ...*ecdsa.PublicKey) (swarm.Address, error)
+	// Lookup returns the reference protected by the access control trie
+	// if the node key is one of its grantees.
+	Lookup(ctx context.Context, ls file.LoadSaver, act swarm.Address) (swarm.Address, error)
+	// Grantees returns the grantees of the access control trie. It is
+	// reserved for the publisher.
+	Grantees(ctx context.Context, ls file.LoadSaver, act swarm.Address) ([]*ecdsa.PublicKey, error)
+	// Update adds and revokes grantees of the access control trie and
+	// returns the reference of the new access control trie. The access
+	// key is rotated if any grantee is revoked. It is reserved for the
+	// publisher.
+	Update(ctx context.Context, ls file.LoadSaver, act swarm.Address, add, revoke []*ecdsa.PublicKey) (swarm.Address, error)
+}
+
+// Service is the implementation of the Interface.
+type Service struct {
+	key *ecdsa.PrivateKey
+	dh  crypto.DH
+}
+
+// New creates a new access control service which uses the provided node
+// key as the publisher key and for the grantee key derivation.
+func New(key *ecdsa.PrivateKey) *Service {
+	return &Service{
+		key: key,
+		dh:  crypto.NewDH(key),
+	}
+}
+
+// Create implements Interface.Create method.
+func (s *Service) Create(ctx context.Context, ls file.LoadSaver, reference swarm.Address, grantees ...*ecdsa.PublicKey) (swarm.Address, error) {
+	accessKey := encryption.GenerateRandomKey(encryption.KeyLength)
+	return s.store(ctx, ls, reference, accessKey, grantees)
+}
+
+// Lookup implements Interface.Lookup method.
+func (s *Service) Lookup(ctx context.Context, ls file.LoadSaver, act swarm.Address) (swarm.Address, error) {
+	m, meta, err := s.load(ctx, ls, act)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	accessKey, err := s.accessKey(ctx, m, meta)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	ref, err := transcrypt(accessKey, meta.reference)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	return swarm.NewAddress(ref), nil
+}
+
+// Grantees implements Interface.Grantees method.
+func (s *Service) Grantees(ctx context.Context, ls file.LoadSaver, act swarm.Address) ([]*ecdsa.PublicKey, error) {
+	_, meta, err := s.load(ctx, ls, act)
+	if err != nil {
+		return nil, err
+	}
+	if !s.isPublisher(meta.publisher) {
+		return nil, ErrNotPublisher
+	}
+	return s.grantees(ctx, ls, meta)
+}
+
+// Update implements Interface.Update method.
+func (s *Service) Update(ctx context.Context, ls file.LoadSaver, act swarm.Address, add, revoke []*ecdsa.PublicKey) (swarm.Address, error) {
+	m, meta, err := s.load(ctx, ls, act)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	if !s.isPublisher(meta.publisher) {
+		return swarm.ZeroAddress, ErrNotPublisher
+	}
+	accessKey, err := s.accessKey(ctx, m, meta)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	ref, err := transcrypt(accessKey, meta.reference)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	grantees, err := s.grantees(ctx, ls, meta)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+
+	var updated []*ecdsa.PublicKey
+	for _, g := range grantees {
+		if !containsKey(revoke, g) {
+			updated = append(updated, g)
+		}
+	}
+	updated = append(updated, add...)
+
+	if len(revoke) > 0 {
+		// revoked grantees may still hold the previous access key
+		accessKey = encryption.GenerateRandomKey(encryption.KeyLength)
+	}
+	return s.store(ctx, ls, swarm.NewAddress(ref), accessKey, updated)
+}
+
+// store saves a new access control trie protecting the reference with the
+// access key for the publisher and the grantees.
+func (s *Service) store(ctx context.Context, ls file.LoadSaver, reference swarm.Address, accessKey encryption.Key, grantees []*ecdsa.PublicKey) (swarm.Address, error) {
+	var unique []*ecdsa.PublicKey
+	for _, g := range append([]*ecdsa.PublicKey{&s.key.PublicKey}, grantees...) {
+		if !containsKey(unique, g) {
+			unique = append(unique, g)
+		}
+	}
+
+	// the nodes of the trie are obfuscated with random keys, so that even
+	// the nodes without entries are not shared between versions
+	m, err := manifest.NewDefaultManifest(ls, true)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("create manifest: %w", err)
+	}
+
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("generate salt: %w", err)
+	}
+
+	encryptedRef, err := transcrypt(accessKey, reference.Bytes())
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	granteeList, err := s.encryptGrantees(unique, salt)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	granteeListRef, err := ls.Save(ctx, granteeList)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("save grantee list: %w", err)
+	}
+
+	rootMetadata := map[string]string{
+		PublisherMetadataKey: hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(&s.key.PublicKey)),
+		ReferenceMetadataKey: hex.EncodeToString(encryptedRef),
+		GranteesMetadataKey:  hex.EncodeToString(granteeListRef),
+		SaltMetadataKey:      hex.EncodeToString(salt),
+	}
+	if err := m.Add(ctx, manifest.RootPath, manifest.NewEntry(swarm.ZeroAddress, rootMetadata)); err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("add root metadata: %w", err)
+	}
+
+	for _, g := range unique {
+		lookupKey, accessKeyDecryptKey, err := s.keys(g, salt)
+		if err != nil {
+			return swarm.ZeroAddress, err
+		}
+		encryptedAccessKey, err := transcrypt(accessKeyDecryptKey, accessKey)
+		if err != nil {
+			return swarm.ZeroAddress, err
+		}
+		err = m.Add(ctx, hex.EncodeToString(lookupKey), manifest.NewEntry(swarm.NewAddress(encryptedAccessKey), nil))
+		if err != nil {
+			return swarm.ZeroAddress, fmt.Errorf("add grantee: %w", err)
+		}
+	}
+
+	return m.Store(ctx)
+}
+
+type rootMetadata struct {
+	publisher *ecdsa.PublicKey
+	reference []byte
+	grantees  []byte
+	salt      []byte
+}
+
+// load opens the access control trie and parses its root metadata.
+func (s *Service) load(ctx context.Context, ls file.LoadSaver, act swarm.Address) (manifest.Interface, *rootMetadata, error) {
+	m, err := manifest.NewDefaultManifestReference(act, ls)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	e, err := m.Lookup(ctx, manifest.RootPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	var (
+		meta = new(rootMetadata)
+		md   = e.Metadata()
+	)
+	publisher, err := hex.DecodeString(md[PublisherMetadataKey])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: publisher: %v", ErrInvalid, err)
+	}
+	meta.publisher, err = crypto.DecodeSecp256k1PublicKey(publisher)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: publisher: %v", ErrInvalid, err)
+	}
+	meta.reference, err = hex.DecodeString(md[ReferenceMetadataKey])
+	if err != nil || len(meta.reference) == 0 {
+		return nil, nil, fmt.Errorf("%w: reference", ErrInvalid)
+	}
+	meta.grantees, err = hex.DecodeString(md[GranteesMetadataKey])
+	if err != nil || len(meta.grantees) == 0 {
+		return nil, nil, fmt.Errorf("%w: grantees", ErrInvalid)
+	}
+	meta.salt, err = hex.DecodeString(md[SaltMetadataKey])
+	if err != nil || len(meta.salt) != saltLength {
+		return nil, nil, fmt.Errorf("%w: salt", ErrInvalid)
+	}
+	return m, meta, nil
+}
+
+// accessKey looks up and decrypts the access key of the node key.
+func (s *Service) accessKey(ctx context.Context, m manifest.Interface, meta *rootMetadata) (encryption.Key, error) {
+	lookupKey, accessKeyDecryptKey, err := s.keys(meta.publisher, meta.salt)
+	if err != nil {
+		return nil, err
+	}
+	e, err := m.Lookup(ctx, hex.EncodeToString(lookupKey))
+	if err != nil {
+		if errors.Is(err, manifest.ErrNotFound) {
+			return nil, ErrNotGrantee
+		}
+		return nil, err
+	}
+	return transcrypt(accessKeyDecryptKey, e.Reference().Bytes())
+}
+
+// keys derives the lookup key and the access key decryption key shared
+// between the node key and the provided public key for the version salt.
+func (s *Service) keys(pub *ecdsa.PublicKey, salt []byte) (lookupKey, accessKeyDecryptKey []byte, err error) {
+	lookupKey, err = s.dh.SharedKey(pub, saltWith(lookupKeySalt, salt))
+	if err != nil {
+		return nil, nil, fmt.Errorf("lookup key: %w", err)
+	}
+	accessKeyDecryptKey, err = s.dh.SharedKey(pub, saltWith(accessKeyDecryptSalt, salt))
+	if err != nil {
+		return nil, nil, fmt.Errorf("access key decryption key: %w", err)
+	}
+	return lookupKey, accessKeyDecryptKey, nil
+}
+
+// encryptGrantees serializes and encrypts the grantee list so that only the
+// publisher is able to read it.
+func (s *Service) encryptGrantees(grantees []*ecdsa.PublicKey, salt []byte) ([]byte, error) {
+	key, err := s.dh.SharedKey(&s.key.PublicKey, saltWith(granteeListSalt, salt))
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, 0, len(grantees)*publicKeyLength)
+	for _, g := range grantees {
+		data = append(data, crypto.EncodeSecp256k1PublicKey(g)...)
+	}
+	return transcrypt(key, data)
+}
+
+// grantees loads and decrypts the grantee list.
+func (s *Service) grantees(ctx context.Context, ls file.LoadSaver, meta *rootMetadata) ([]*ecdsa.PublicKey, error) {
+	encrypted, err := ls.Load(ctx, meta.grantees)
+	if err != nil {
+		return nil, fmt.Errorf("load grantee list: %w", err)
+	}
+	key, err := s.dh.SharedKey(&s.key.PublicKey, saltWith(granteeListSalt, meta.salt))
+	if err != nil {
+		return nil, err
+	}
+	data, err := transcrypt(key, encrypted)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%publicKeyLength != 0 {
+		return nil, fmt.Errorf("%w: grantee list length %d", ErrInvalid, len(data))
+	}
+	grantees := make([]*ecdsa.PublicKey, 0, len(data)/publicKeyLength)
+	for i := 0; i < len(data); i += publicKeyLength {
+		g, err := crypto.DecodeSecp256k1PublicKey(data[i : i+publicKeyLength])
+		if err != nil {
+			return nil, fmt.Errorf("%w: grantee: %v", ErrInvalid, err)
+		}
+		grantees = append(grantees, g)
+	}
+	return grantees, nil
+}
+
+func (s *Service) isPublisher(pub *ecdsa.PublicKey) bool {
+	return equalKeys(pub, &s.key.PublicKey)
+}
+
+// saltWith returns the key derivation salt of the purpose within the
+// access control trie version.
+func saltWith(purpose, salt []byte) []byte {
+	return append(append(make([]byte, 0, len(purpose)+len(salt)), purpose...), salt...)
+}
+
+// transcrypt encrypts or decrypts the data with the key, as both operations
+// are the same for the symmetric encryption.
+func transcrypt(key encryption.Key, data []byte) ([]byte, error) {
+	return encryption.New(key, 0, 0, sha3.NewLegacyKeccak256).Encrypt(data)
+}
+
+func containsKey(keys []*ecdsa.PublicKey, k *ecdsa.PublicKey) bool {
+	for _, key := range keys {
+		if equalKeys(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalKeys(a, b *ecdsa.PublicKey) bool {
+	return bytes.Equal(crypto.EncodeSecp256k1PublicKey(a), crypto.EncodeSecp256k1PublicKey(b))
+}
@@ -0,0 +1,178 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package act_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/act"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/loadsave"
+	"github.com/ethersphere/bee/pkg/manifest"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestAccessControl(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		ls        = loadsave.New(mock.NewStorer(), storage.ModePutUpload, false)
+		reference = swarm.MustParseHexAddress("f4a1b9c1d6a6c41bda3e3c0e2e1c7e1c2c0e6d8fbd8d7b0a4c9b6f04a2bc3d5e")
+
+		publisherKey = newKey(t)
+		granteeKey   = newKey(t)
+		otherKey     = newKey(t)
+
+		publisher = act.New(publisherKey)
+		grantee   = act.New(granteeKey)
+		other     = act.New(otherKey)
+	)
+
+	ref, err := publisher.Create(ctx, ls, reference, &granteeKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("publisher lookup", func(t *testing.T) {
+		assertLookup(t, publisher, ls, ref, reference)
+	})
+
+	t.Run("grantee lookup", func(t *testing.T) {
+		assertLookup(t, grantee, ls, ref, reference)
+	})
+
+	t.Run("not grantee lookup", func(t *testing.T) {
+		if _, err := other.Lookup(ctx, ls, ref); !errors.Is(err, act.ErrNotGrantee) {
+			t.Fatalf("got error %v, want %v", err, act.ErrNotGrantee)
+		}
+	})
+
+	t.Run("grantees", func(t *testing.T) {
+		grantees, err := publisher.Grantees(ctx, ls, ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertGrantees(t, grantees, &publisherKey.PublicKey, &granteeKey.PublicKey)
+
+		if _, err := grantee.Grantees(ctx, ls, ref); !errors.Is(err, act.ErrNotPublisher) {
+			t.Fatalf("got error %v, want %v", err, act.ErrNotPublisher)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		newRef, err := publisher.Update(ctx, ls, ref, []*ecdsa.PublicKey{&otherKey.PublicKey}, []*ecdsa.PublicKey{&granteeKey.PublicKey})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if newRef.Equal(ref) {
+			t.Fatal("update did not create a new access control trie")
+		}
+
+		assertLookup(t, publisher, ls, newRef, reference)
+		assertLookup(t, other, ls, newRef, reference)
+		if _, err := grantee.Lookup(ctx, ls, newRef); !errors.Is(err, act.ErrNotGrantee) {
+			t.Fatalf("got error %v, want %v", err, act.ErrNotGrantee)
+		}
+
+		grantees, err := publisher.Grantees(ctx, ls, newRef)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertGrantees(t, grantees, &publisherKey.PublicKey, &otherKey.PublicKey)
+
+		// the previous access control trie is left intact
+		assertLookup(t, grantee, ls, ref, reference)
+	})
+
+	t.Run("versions unlinkable", func(t *testing.T) {
+		// the access key is kept when no grantee is revoked, but the
+		// entries of the versions must still differ
+		newRef, err := publisher.Update(ctx, ls, ref, []*ecdsa.PublicKey{&otherKey.PublicKey}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertLookup(t, grantee, ls, newRef, reference)
+
+		addrs := manifestAddresses(t, ls, ref)
+		for a := range manifestAddresses(t, ls, newRef) {
+			if addrs[a] {
+				t.Fatalf("address %x shared between versions", a)
+			}
+		}
+	})
+
+	t.Run("update not publisher", func(t *testing.T) {
+		_, err := grantee.Update(ctx, ls, ref, []*ecdsa.PublicKey{&otherKey.PublicKey}, nil)
+		if !errors.Is(err, act.ErrNotPublisher) {
+			t.Fatalf("got error %v, want %v", err, act.ErrNotPublisher)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := grantee.Lookup(ctx, ls, reference); !errors.Is(err, act.ErrInvalid) {
+			t.Fatalf("got error %v, want %v", err, act.ErrInvalid)
+		}
+	})
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func manifestAddresses(t *testing.T, ls file.LoadSaver, ref swarm.Address) map[string]bool {
+	t.Helper()
+
+	m, err := manifest.NewDefaultManifestReference(ref, ls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addrs := make(map[string]bool)
+	err = m.IterateAddresses(context.Background(), func(a swarm.Address) error {
+		if !a.IsZero() {
+			addrs[a.ByteString()] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addrs
+}
+
+func assertLookup(t *testing.T, s act.Interface, ls file.LoadSaver, ref, want swarm.Address) {
+	t.Helper()
+
+	got, err := s.Lookup(context.Background(), ls, ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(want) {
+		t.Fatalf("got reference %s, want %s", got, want)
+	}
+}
+
+func assertGrantees(t *testing.T, got []*ecdsa.PublicKey, want ...*ecdsa.PublicKey) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d grantees, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].X.Cmp(want[i].X) != 0 || got[i].Y.Cmp(want[i].Y) != 0 {
+			t.Fatalf("grantee %d mismatch", i)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/accessstats"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/logging"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	testingc "github.com/ethersphere/bee/pkg/storage/testing"
+	"github.com/ethersphere/bee/pkg/tags"
+)
+
+// TestAccessStats validates that the downloaded root references
+// and chunks are counted.
+func TestAccessStats(t *testing.T) {
+	var (
+		stats        = accessstats.New(accessstats.Options{})
+		storerMock   = mock.NewStorer()
+		logger       = logging.New(ioutil.Discard, 0)
+		client, _, _ = newTestServer(t, testServerOptions{
+			Storer:      storerMock,
+			Tags:        tags.NewTags(statestore.NewStateStore(), logger),
+			Logger:      logger,
+			AccessStats: stats,
+		})
+		chunk = testingc.GenerateTestRandomChunk()
+	)
+
+	if _, err := storerMock.Put(context.Background(), storage.ModePutUpload, chunk); err != nil {
+		t.Fatal(err)
+	}
+
+	jsonhttptest.Request(t, client, http.MethodGet, "/bytes/"+chunk.Address().String(), http.StatusOK)
+	jsonhttptest.Request(t, client, http.MethodGet, "/chunks/"+chunk.Address().String(), http.StatusOK)
+
+	roots, chunks, err := stats.Top(time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []accessstats.Entry{{Address: chunk.Address(), Count: 1}}
+	if !reflect.DeepEqual(roots, want) {
+		t.Fatalf("got roots %v, want %v", roots, want)
+	}
+	if !reflect.DeepEqual(chunks, want) {
+		t.Fatalf("got chunks %v, want %v", chunks, want)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/act"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/file/loadsave"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+type actGranteesResponse struct {
+	Grantees []string `json:"grantees"`
+}
+
+type actGranteesPatchRequest struct {
+	Add    []string `json:"add"`
+	Revoke []string `json:"revoke"`
+}
+
+type actReferenceResponse struct {
+	Reference swarm.Address `json:"reference"`
+}
+
+// requestAct returns true if the content of the request should be protected
+// or accessed through an access control trie.
+func requestAct(r *http.Request) bool {
+	return strings.ToLower(r.Header.Get(SwarmActHeader)) == "true"
+}
+
+// actLookup returns the reference protected by the access control trie at
+// the given address.
+func (s *server) actLookup(r *http.Request, address swarm.Address) (swarm.Address, error) {
+	ls := loadsave.New(s.storer, storage.ModePutRequest, false)
+	return s.act.Lookup(r.Context(), ls, address)
+}
+
+// actCreate protects the reference with a new access control trie which has
+// the node as the only grantee and returns the address of the trie.
+func (s *server) actCreate(r *http.Request, putter storage.Storer, reference swarm.Address) (swarm.Address, error) {
+	ls := loadsave.New(putter, requestModePut(r), false)
+	return s.act.Create(r.Context(), ls, reference)
+}
+
+func (s *server) actGranteesGetHandler(w http.ResponseWriter, r *http.Request) {
+	address, err := swarm.ParseHexAddress(mux.Vars(r)["address"])
+	if err != nil {
+		s.logger.Debugf("act grantees: parse address: %v", err)
+		s.logger.Error("act grantees: parse address")
+		jsonhttp.BadRequest(w, "invalid address")
+		return
+	}
+
+	ls := loadsave.New(s.storer, storage.ModePutRequest, false)
+	grantees, err := s.act.Grantees(r.Context(), ls, address)
+	if err != nil {
+		s.logger.Debugf("act grantees: get grantees of %s: %v", address, err)
+		s.logger.Error("act grantees: get grantees")
+		switch {
+		case errors.Is(err, act.ErrNotPublisher):
+			jsonhttp.Forbidden(w, "not the publisher")
+		case errors.Is(err, act.ErrInvalid):
+			jsonhttp.NotFound(w, nil)
+		default:
+			jsonhttp.InternalServerError(w, nil)
+		}
+		return
+	}
+
+	resp := actGranteesResponse{Grantees: make([]string, 0, len(grantees))}
+	for _, g := range grantees {
+		resp.Grantees = append(resp.Grantees, hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(g)))
+	}
+	jsonhttp.OK(w, resp)
+}
+
+func (s *server) actGranteesPatchHandler(w http.ResponseWriter, r *http.Request) {
+	address, err := swarm.ParseHexAddress(mux.Vars(r)["address"])
+	if err != nil {
+		s.logger.Debugf("act grantees patch: parse address: %v", err)
+		s.logger.Error("act grantees patch: parse address")
+		jsonhttp.BadRequest(w, "invalid address")
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		if jsonhttp.HandleBodyReadError(err, w) {
+			return
+		}
+		s.logger.Debugf("act grantees patch: read request body: %v", err)
+		s.logger.Error("act grantees patch: read request body")
+		jsonhttp.InternalServerError(w, "cannot read request")
+		return
+	}
+
+	var req actGranteesPatchRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.logger.Debugf("act grantees patch: unmarshal request: %v", err)
+		s.logger.Error("act grantees patch: unmarshal request")
+		jsonhttp.BadRequest(w, "invalid request")
+		return
+	}
+	add, err := parseGrantees(req.Add)
+	if err != nil {
+		s.logger.Debugf("act grantees patch: parse grantees to add: %v", err)
+		s.logger.Error("act grantees patch: parse grantees to add")
+		jsonhttp.BadRequest(w, "invalid grantee")
+		return
+	}
+	revoke, err := parseGrantees(req.Revoke)
+	if err != nil {
+		s.logger.Debugf("act grantees patch: parse grantees to revoke: %v", err)
+		s.logger.Error("act grantees patch: parse grantees to revoke")
+		jsonhttp.BadRequest(w, "invalid grantee")
+		return
+	}
+
+	batch, err := requestPostageBatchId(r)
+	if err != nil {
+		s.logger.Debugf("act grantees patch: postage batch id: %v", err)
+		s.logger.Error("act grantees patch: postage batch id")
+		jsonhttp.BadRequest(w, "invalid postage batch id")
+		return
+	}
+
+	putter, err := newStamperPutter(s.storer, s.post, s.signer, batch)
+	if err != nil {
+		s.logger.Debugf("act grantees patch: putter: %v", err)
+		s.logger.Error("act grantees patch: putter")
+		switch {
+		case errors.Is(err, postage.ErrNotFound):
+			jsonhttp.BadRequest(w, "batch not found")
+		case errors.Is(err, postage.ErrNotUsable):
+			jsonhttp.BadRequest(w, "batch not usable yet")
+		default:
+			jsonhttp.BadRequest(w, nil)
+		}
+		return
+	}
+
+	ls := loadsave.New(putter, requestModePut(r), false)
+	reference, err := s.act.Update(r.Context(), ls, address, add, revoke)
+	if err != nil {
+		s.logger.Debugf("act grantees patch: update %s: %v", address, err)
+		s.logger.Error("act grantees patch: update")
+		switch {
+		case errors.Is(err, act.ErrNotPublisher):
+			jsonhttp.Forbidden(w, "not the publisher")
+		case errors.Is(err, act.ErrInvalid):
+			jsonhttp.NotFound(w, nil)
+		case errors.Is(err, postage.ErrBucketFull):
+			jsonhttp.PaymentRequired(w, "batch is overissued")
+		default:
+			jsonhttp.InternalServerError(w, nil)
+		}
+		return
+	}
+
+	jsonhttp.OK(w, actReferenceResponse{Reference: reference})
+}
+
+// parseGrantees parses the hex encoded public keys of grantees.
+func parseGrantees(grantees []string) ([]*ecdsa.PublicKey, error) {
+	keys := make([]*ecdsa.PublicKey, 0, len(grantees))
+	for _, g := range grantees {
+		b, err := hex.DecodeString(g)
+		if err != nil {
+			return nil, fmt.Errorf("decode grantee %q: %w", g, err)
+		}
+		key, err := crypto.DecodeSecp256k1PublicKey(b)
+		if err != nil {
+			return nil, fmt.Errorf("parse grantee %q: %w", g, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
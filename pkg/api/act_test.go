@@ -0,0 +1,129 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/act"
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/logging"
+	mockpost "github.com/ethersphere/bee/pkg/postage/mock"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/tags"
+)
+
+func TestAct(t *testing.T) {
+	var (
+		data    = []byte("some highly confidential text")
+		storer  = mock.NewStorer()
+		logger  = logging.New(ioutil.Discard, 0)
+		newNode = func(t *testing.T) (*http.Client, string) {
+			t.Helper()
+
+			key, err := crypto.GenerateSecp256k1Key()
+			if err != nil {
+				t.Fatal(err)
+			}
+			client, _, _ := newTestServer(t, testServerOptions{
+				Storer: storer,
+				Tags:   tags.NewTags(statestore.NewStateStore(), logger),
+				Logger: logger,
+				Post:   mockpost.New(mockpost.WithAcceptAll()),
+				Act:    act.New(key),
+			})
+			return client, hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(&key.PublicKey))
+		}
+		publisher, publisherKey = newNode(t)
+		grantee, granteeKey     = newNode(t)
+	)
+
+	var uploadResp api.BytesPostResponse
+	jsonhttptest.Request(t, publisher, http.MethodPost, "/bytes", http.StatusCreated,
+		jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+		jsonhttptest.WithRequestHeader(api.SwarmActHeader, "true"),
+		jsonhttptest.WithRequestBody(bytes.NewReader(data)),
+		jsonhttptest.WithUnmarshalJSONResponse(&uploadResp),
+	)
+	actRef := uploadResp.Reference
+
+	t.Run("publisher download", func(t *testing.T) {
+		jsonhttptest.Request(t, publisher, http.MethodGet, "/bytes/"+actRef.String(), http.StatusOK,
+			jsonhttptest.WithRequestHeader(api.SwarmActHeader, "true"),
+			jsonhttptest.WithExpectedResponse(data),
+		)
+	})
+
+	t.Run("not grantee download", func(t *testing.T) {
+		jsonhttptest.Request(t, grantee, http.MethodGet, "/bytes/"+actRef.String(), http.StatusForbidden,
+			jsonhttptest.WithRequestHeader(api.SwarmActHeader, "true"),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "not a grantee",
+				Code:    http.StatusForbidden,
+			}),
+		)
+	})
+
+	var patchResp api.ActReferenceResponse
+	jsonhttptest.Request(t, publisher, http.MethodPatch, "/act/"+actRef.String()+"/grantees", http.StatusOK,
+		jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+		jsonhttptest.WithJSONRequestBody(api.ActGranteesPatchRequest{
+			Add: []string{granteeKey},
+		}),
+		jsonhttptest.WithUnmarshalJSONResponse(&patchResp),
+	)
+	newActRef := patchResp.Reference
+
+	t.Run("grantee download", func(t *testing.T) {
+		jsonhttptest.Request(t, grantee, http.MethodGet, "/bytes/"+newActRef.String(), http.StatusOK,
+			jsonhttptest.WithRequestHeader(api.SwarmActHeader, "true"),
+			jsonhttptest.WithExpectedResponse(data),
+		)
+	})
+
+	t.Run("grantees", func(t *testing.T) {
+		jsonhttptest.Request(t, publisher, http.MethodGet, "/act/"+newActRef.String()+"/grantees", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.ActGranteesResponse{
+				Grantees: []string{publisherKey, granteeKey},
+			}),
+		)
+		jsonhttptest.Request(t, grantee, http.MethodGet, "/act/"+newActRef.String()+"/grantees", http.StatusForbidden)
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		var resp api.ActReferenceResponse
+		jsonhttptest.Request(t, publisher, http.MethodPatch, "/act/"+newActRef.String()+"/grantees", http.StatusOK,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithJSONRequestBody(api.ActGranteesPatchRequest{
+				Revoke: []string{granteeKey},
+			}),
+			jsonhttptest.WithUnmarshalJSONResponse(&resp),
+		)
+		jsonhttptest.Request(t, grantee, http.MethodGet, "/bytes/"+resp.Reference.String(), http.StatusForbidden,
+			jsonhttptest.WithRequestHeader(api.SwarmActHeader, "true"),
+		)
+	})
+
+	t.Run("invalid grantee", func(t *testing.T) {
+		jsonhttptest.Request(t, publisher, http.MethodPatch, "/act/"+newActRef.String()+"/grantees", http.StatusBadRequest,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithJSONRequestBody(api.ActGranteesPatchRequest{
+				Add: []string{"not a key"},
+			}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "invalid grantee",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
@@ -20,6 +21,9 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/ethersphere/bee/pkg/accessstats"
+	"github.com/ethersphere/bee/pkg/act"
+	"github.com/ethersphere/bee/pkg/auth"
 	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/feeds"
 	"github.com/ethersphere/bee/pkg/file/pipeline/builder"
@@ -48,6 +52,7 @@ const (
 	SwarmFeedIndexNextHeader  = "Swarm-Feed-Index-Next"
 	SwarmCollectionHeader     = "Swarm-Collection"
 	SwarmPostageBatchIdHeader = "Swarm-Postage-Batch-Id"
+	SwarmActHeader            = "Swarm-Act"
 )
 
 // The size of buffer used for prefetching content with Langos.
@@ -86,23 +91,36 @@ type Service interface {
 }
 
 type server struct {
+	pssCursor uint64 // cursor of the last buffered pss message, accessed atomically and first for the alignment
+
 	tags            *tags.Tags
 	storer          storage.Storer
 	resolver        resolver.Interface
 	pss             pss.Interface
 	traversal       traversal.Traverser
 	pinning         pinning.Interface
-	steward         steward.Reuploader
+	steward         steward.Interface
+	act             act.Interface
+	accessStats     *accessstats.Service
+	auth            *auth.Authenticator
 	logger          logging.Logger
 	tracer          *tracing.Tracer
 	feedFactory     feeds.Factory
 	signer          crypto.Signer
 	post            postage.Service
+	batchStore      postage.Storer
 	postageContract postagecontract.Interface
 	Options
 	http.Handler
 	metrics metrics
 
+	ipQuotas     clientQuotas
+	tokenQuotas  clientQuotas
+	uploadPolicy uploadPolicy
+
+	pssBuffersMu sync.Mutex
+	pssBuffers   map[pss.Topic]*pssBuffer // recent messages of the polled topics
+
 	wsWg sync.WaitGroup // wait for all websockets to close on exit
 	quit chan struct{}
 }
@@ -111,6 +129,28 @@ type Options struct {
 	CORSAllowedOrigins []string
 	GatewayMode        bool
 	WsPingPeriod       time.Duration
+	BlockTime          time.Duration
+	// RateLimitWindow is the time window of the rate limits,
+	// the clients are not limited if it is zero.
+	RateLimitWindow time.Duration
+	// RateLimitIP limits the clients by their IP address.
+	RateLimitIP RateLimits
+	// RateLimitToken limits the clients authenticated with a bearer key.
+	RateLimitToken RateLimits
+	// RateLimitTrustedProxies are the networks of the reverse proxies whose
+	// X-Forwarded-For headers tell the IP address of the client. The
+	// header is ignored if empty.
+	RateLimitTrustedProxies []*net.IPNet
+	// GatewayMaxUploadSize is the maximal size in bytes of the uploaded
+	// content in gateway mode, not limited if zero.
+	GatewayMaxUploadSize int64
+	// GatewayContentTypes are the media types allowed to be uploaded in
+	// gateway mode, any if empty. The "type/*" patterns allow all the
+	// subtypes of the type.
+	GatewayContentTypes []string
+	// GatewayMaxManifestFiles is the maximal number of files in the
+	// uploaded directories in gateway mode, not limited if zero.
+	GatewayMaxManifestFiles int
 }
 
 const (
@@ -119,7 +159,7 @@ const (
 )
 
 // New will create a and initialize a new API service.
-func New(tags *tags.Tags, storer storage.Storer, resolver resolver.Interface, pss pss.Interface, traversalService traversal.Traverser, pinning pinning.Interface, feedFactory feeds.Factory, post postage.Service, postageContract postagecontract.Interface, steward steward.Reuploader, signer crypto.Signer, logger logging.Logger, tracer *tracing.Tracer, o Options) Service {
+func New(tags *tags.Tags, storer storage.Storer, resolver resolver.Interface, pss pss.Interface, traversalService traversal.Traverser, pinning pinning.Interface, feedFactory feeds.Factory, post postage.Service, batchStore postage.Storer, postageContract postagecontract.Interface, steward steward.Interface, act act.Interface, accessStats *accessstats.Service, authenticator *auth.Authenticator, signer crypto.Signer, logger logging.Logger, tracer *tracing.Tracer, o Options) Service {
 	s := &server{
 		tags:            tags,
 		storer:          storer,
@@ -129,15 +169,25 @@ func New(tags *tags.Tags, storer storage.Storer, resolver resolver.Interface, ps
 		pinning:         pinning,
 		feedFactory:     feedFactory,
 		post:            post,
+		batchStore:      batchStore,
 		postageContract: postageContract,
 		steward:         steward,
+		act:             act,
+		accessStats:     accessStats,
+		auth:            authenticator,
 		signer:          signer,
 		Options:         o,
 		logger:          logger,
 		tracer:          tracer,
 		metrics:         newMetrics(),
+		ipQuotas:        newClientQuotas(o.RateLimitWindow, o.RateLimitIP),
+		tokenQuotas:     newClientQuotas(o.RateLimitWindow, o.RateLimitToken),
+		uploadPolicy:    newUploadPolicy(o),
 		quit:            make(chan struct{}),
 	}
+	// the cursors are greater than the ones used before a restart
+	// unless more than a message per millisecond was received
+	s.pssCursor = uint64(time.Now().UnixNano() / int64(time.Millisecond))
 
 	s.setupRouting()
 
@@ -221,8 +271,11 @@ func requestModePut(r *http.Request) storage.ModePut {
 	return storage.ModePutUpload
 }
 
+// requestEncrypt returns true if the content of the request should be
+// encrypted, which is always the case for content protected by an access
+// control trie.
 func requestEncrypt(r *http.Request) bool {
-	return strings.ToLower(r.Header.Get(SwarmEncryptHeader)) == "true"
+	return strings.ToLower(r.Header.Get(SwarmEncryptHeader)) == "true" || requestAct(r)
 }
 
 func requestPostageBatchId(r *http.Request) ([]byte, error) {
@@ -12,19 +12,24 @@ import (
 	"io"
 	"io/ioutil"
 	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/accessstats"
+	"github.com/ethersphere/bee/pkg/act"
 	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/auth"
 	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/feeds"
 	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/pinning"
 	"github.com/ethersphere/bee/pkg/postage"
+	mockbatchstore "github.com/ethersphere/bee/pkg/postage/batchstore/mock"
 	mockpost "github.com/ethersphere/bee/pkg/postage/mock"
 	"github.com/ethersphere/bee/pkg/postage/postagecontract"
 	"github.com/ethersphere/bee/pkg/pss"
@@ -64,13 +69,25 @@ type testServerOptions struct {
 	Tags               *tags.Tags
 	GatewayMode        bool
 	WsPingPeriod       time.Duration
+	BlockTime          time.Duration
 	Logger             logging.Logger
 	PreventRedirect    bool
 	Feeds              feeds.Factory
 	CORSAllowedOrigins []string
 	PostageContract    postagecontract.Interface
 	Post               postage.Service
-	Steward            steward.Reuploader
+	BatchStore         postage.Storer
+	Steward            steward.Interface
+	Act                act.Interface
+	Auth               *auth.Authenticator
+	AccessStats        *accessstats.Service
+	RateLimitWindow    time.Duration
+	RateLimitIP        api.RateLimits
+	RateLimitToken     api.RateLimits
+	TrustedProxies     []*net.IPNet
+	GatewayMaxUpload   int64
+	GatewayTypes       []string
+	GatewayMaxFiles    int
 }
 
 func newTestServer(t *testing.T, o testServerOptions) (*http.Client, *websocket.Conn, string) {
@@ -90,10 +107,22 @@ func newTestServer(t *testing.T, o testServerOptions) (*http.Client, *websocket.
 	if o.Post == nil {
 		o.Post = mockpost.New()
 	}
-	s := api.New(o.Tags, o.Storer, o.Resolver, o.Pss, o.Traversal, o.Pinning, o.Feeds, o.Post, o.PostageContract, o.Steward, signer, o.Logger, nil, api.Options{
+	if o.BatchStore == nil {
+		o.BatchStore = mockbatchstore.New()
+	}
+	s := api.New(o.Tags, o.Storer, o.Resolver, o.Pss, o.Traversal, o.Pinning, o.Feeds, o.Post, o.BatchStore, o.PostageContract, o.Steward, o.Act, o.AccessStats, o.Auth, signer, o.Logger, nil, api.Options{
 		CORSAllowedOrigins: o.CORSAllowedOrigins,
 		GatewayMode:        o.GatewayMode,
 		WsPingPeriod:       o.WsPingPeriod,
+		BlockTime:          o.BlockTime,
+		RateLimitWindow:    o.RateLimitWindow,
+		RateLimitIP:        o.RateLimitIP,
+		RateLimitToken:     o.RateLimitToken,
+
+		RateLimitTrustedProxies: o.TrustedProxies,
+		GatewayMaxUploadSize:    o.GatewayMaxUpload,
+		GatewayContentTypes:     o.GatewayTypes,
+		GatewayMaxManifestFiles: o.GatewayMaxFiles,
 	})
 	ts := httptest.NewServer(s)
 	t.Cleanup(ts.Close)
@@ -209,7 +238,7 @@ func TestParseName(t *testing.T) {
 		signer := crypto.NewDefaultSigner(pk)
 		mockPostage := mockpost.New()
 
-		s := api.New(nil, nil, tC.res, nil, nil, nil, nil, mockPostage, nil, nil, signer, log, nil, api.Options{}).(*api.Server)
+		s := api.New(nil, nil, tC.res, nil, nil, nil, nil, mockPostage, nil, nil, nil, nil, nil, nil, signer, log, nil, api.Options{}).(*api.Server)
 
 		t.Run(tC.desc, func(t *testing.T) {
 			got, err := s.ResolveNameOrAddress(tC.name)
@@ -0,0 +1,101 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/auth"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+type authRequest struct {
+	Roles []string `json:"roles"`
+	// Expiry is the number of seconds the key is valid for.
+	Expiry int64 `json:"expiry"`
+}
+
+type authResponse struct {
+	Key string `json:"key"`
+}
+
+// authHandler issues a key granting the requested roles to the
+// holder of the admin password, sent with basic authentication.
+func (s *server) authHandler(w http.ResponseWriter, r *http.Request) {
+	_, password, ok := r.BasicAuth()
+	if !ok || !s.auth.Authorize(password) {
+		s.logger.Error("auth: unauthorized")
+		w.Header().Set("WWW-Authenticate", `Basic realm="Swarm"`)
+		jsonhttp.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		if jsonhttp.HandleBodyReadError(err, w) {
+			return
+		}
+		s.logger.Debugf("auth: read request body: %v", err)
+		s.logger.Error("auth: read request body")
+		jsonhttp.InternalServerError(w, "cannot read request")
+		return
+	}
+
+	var req authRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.logger.Debugf("auth: unmarshal request: %v", err)
+		s.logger.Error("auth: unmarshal request")
+		jsonhttp.BadRequest(w, "bad request")
+		return
+	}
+
+	key, err := s.auth.GenerateKey(req.Roles, time.Duration(req.Expiry)*time.Second)
+	if err != nil {
+		s.logger.Debugf("auth: generate key: %v", err)
+		s.logger.Error("auth: generate key")
+		switch {
+		case errors.Is(err, auth.ErrInvalidRole):
+			jsonhttp.BadRequest(w, "invalid role")
+		case errors.Is(err, auth.ErrInvalidExpiry):
+			jsonhttp.BadRequest(w, "invalid expiry")
+		default:
+			jsonhttp.InternalServerError(w, "cannot generate key")
+		}
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	jsonhttp.Created(w, authResponse{Key: key})
+}
+
+// restrictHandler allows only the requests authenticated with a bearer
+// key granting the role if the API access is restricted.
+func (s *server) restrictHandler(role string) func(h http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if s.auth == nil {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if err := s.auth.Enforce(key, role); err != nil {
+				s.logger.Tracef("auth: %s %s: %v", role, r.URL.String(), err)
+				if errors.Is(err, auth.ErrForbidden) {
+					jsonhttp.Forbidden(w, nil)
+					return
+				}
+				jsonhttp.Unauthorized(w, nil)
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/auth"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/logging"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/tags"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestAuth(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator, err := auth.New(string(hash), statestore.NewStateStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := logging.New(ioutil.Discard, 0)
+	client, _, _ := newTestServer(t, testServerOptions{
+		Tags:   tags.NewTags(statestore.NewStateStore(), logger),
+		Logger: logger,
+		Auth:   authenticator,
+	})
+
+	basicAuth := func(password string) jsonhttptest.Option {
+		return jsonhttptest.WithRequestHeader("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("admin:"+password)))
+	}
+	bearer := func(key string) jsonhttptest.Option {
+		return jsonhttptest.WithRequestHeader("Authorization", "Bearer "+key)
+	}
+	newKey := func(t *testing.T, roles ...string) string {
+		t.Helper()
+
+		var resp api.AuthResponse
+		jsonhttptest.Request(t, client, http.MethodPost, "/auth", http.StatusCreated,
+			basicAuth("secret"),
+			jsonhttptest.WithJSONRequestBody(api.AuthRequest{Roles: roles, Expiry: 60}),
+			jsonhttptest.WithUnmarshalJSONResponse(&resp),
+		)
+		if resp.Key == "" {
+			t.Fatal("got empty key")
+		}
+		return resp.Key
+	}
+
+	t.Run("wrong password", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, "/auth", http.StatusUnauthorized,
+			basicAuth("guess"),
+			jsonhttptest.WithJSONRequestBody(api.AuthRequest{Roles: []string{auth.RoleAdmin}}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "unauthorized",
+				Code:    http.StatusUnauthorized,
+			}),
+		)
+	})
+
+	t.Run("invalid role", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, "/auth", http.StatusBadRequest,
+			basicAuth("secret"),
+			jsonhttptest.WithJSONRequestBody(api.AuthRequest{Roles: []string{"root"}}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "invalid role",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("invalid expiry", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, "/auth", http.StatusBadRequest,
+			basicAuth("secret"),
+			jsonhttptest.WithJSONRequestBody(api.AuthRequest{Roles: []string{auth.RoleRead}, Expiry: -1}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "invalid expiry",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("no key", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, "/tags", http.StatusUnauthorized,
+			jsonhttptest.WithJSONRequestBody(api.TagRequest{}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: http.StatusText(http.StatusUnauthorized),
+				Code:    http.StatusUnauthorized,
+			}),
+		)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, "/tags", http.StatusUnauthorized,
+			bearer("deadbeef"),
+			jsonhttptest.WithJSONRequestBody(api.TagRequest{}),
+		)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, "/tags", http.StatusForbidden,
+			bearer(newKey(t, auth.RoleRead)),
+			jsonhttptest.WithJSONRequestBody(api.TagRequest{}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: http.StatusText(http.StatusForbidden),
+				Code:    http.StatusForbidden,
+			}),
+		)
+	})
+
+	t.Run("granted", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, "/tags", http.StatusCreated,
+			bearer(newKey(t, auth.RoleConsumer)),
+			jsonhttptest.WithJSONRequestBody(api.TagRequest{}),
+		)
+	})
+}
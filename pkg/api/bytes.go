@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/ethersphere/bee/pkg/act"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/postage"
 	"github.com/ethersphere/bee/pkg/sctx"
@@ -75,6 +76,8 @@ func (s *server) bytesUploadHandler(w http.ResponseWriter, r *http.Request) {
 		switch {
 		case errors.Is(err, postage.ErrBucketFull):
 			jsonhttp.PaymentRequired(w, "batch is overissued")
+		case errors.Is(err, errUploadTooLarge):
+			jsonhttp.RequestEntityTooLarge(w, errUploadTooLarge)
 		default:
 			jsonhttp.InternalServerError(w, nil)
 		}
@@ -100,6 +103,21 @@ func (s *server) bytesUploadHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if requestAct(r) {
+		address, err = s.actCreate(r, putter, address)
+		if err != nil {
+			logger.Debugf("bytes upload: create access control trie: %v", err)
+			logger.Error("bytes upload: create access control trie")
+			switch {
+			case errors.Is(err, postage.ErrBucketFull):
+				jsonhttp.PaymentRequired(w, "batch is overissued")
+			default:
+				jsonhttp.InternalServerError(w, nil)
+			}
+			return
+		}
+	}
+
 	w.Header().Set(SwarmTagHeader, fmt.Sprint(tag.Uid))
 	w.Header().Set("Access-Control-Expose-Headers", SwarmTagHeader)
 	jsonhttp.Created(w, bytesPostResponse{
@@ -120,6 +138,22 @@ func (s *server) bytesGetHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if requestAct(r) {
+		ref, err := s.actLookup(r, address)
+		if err != nil {
+			logger.Debugf("bytes: access control lookup %s: %v", address, err)
+			logger.Error("bytes: access control lookup")
+			if errors.Is(err, act.ErrNotGrantee) {
+				jsonhttp.Forbidden(w, "not a grantee")
+				return
+			}
+			jsonhttp.NotFound(w, nil)
+			return
+		}
+		address = ref
+	}
+	s.accessStats.RecordRoot(address)
+
 	additionalHeaders := http.Header{
 		"Content-Type": {"application/octet-stream"},
 	}
@@ -19,6 +19,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gorilla/mux"
 
+	"github.com/ethersphere/bee/pkg/act"
 	"github.com/ethersphere/bee/pkg/feeds"
 	"github.com/ethersphere/bee/pkg/file/joiner"
 	"github.com/ethersphere/bee/pkg/file/loadsave"
@@ -92,6 +93,12 @@ func (s *server) fileUploadHandler(w http.ResponseWriter, r *http.Request, store
 
 	// Content-Type has already been validated by this time
 	contentType := r.Header.Get(contentTypeHeader)
+	if !s.uploadPolicy.allowContentType(contentType) {
+		logger.Debugf("bzz upload file: content type %q not allowed", contentType)
+		logger.Error("bzz upload file: content type not allowed")
+		jsonhttp.UnsupportedMediaType(w, errUnsupportedContentType)
+		return
+	}
 
 	tag, created, err := s.getOrCreateTag(r.Header.Get(SwarmTagHeader))
 	if err != nil {
@@ -130,6 +137,8 @@ func (s *server) fileUploadHandler(w http.ResponseWriter, r *http.Request, store
 		switch {
 		case errors.Is(err, postage.ErrBucketFull):
 			jsonhttp.PaymentRequired(w, "batch is overissued")
+		case errors.Is(err, errUploadTooLarge):
+			jsonhttp.RequestEntityTooLarge(w, errUploadTooLarge)
 		default:
 			jsonhttp.InternalServerError(w, errFileStore)
 		}
@@ -228,6 +237,21 @@ func (s *server) fileUploadHandler(w http.ResponseWriter, r *http.Request, store
 		}
 	}
 
+	if requestAct(r) {
+		manifestReference, err = s.actCreate(r, storer, manifestReference)
+		if err != nil {
+			logger.Debugf("bzz upload file: create access control trie: %v", err)
+			logger.Error("bzz upload file: create access control trie")
+			switch {
+			case errors.Is(err, postage.ErrBucketFull):
+				jsonhttp.PaymentRequired(w, "batch is overissued")
+			default:
+				jsonhttp.InternalServerError(w, nil)
+			}
+			return
+		}
+	}
+
 	w.Header().Set("ETag", fmt.Sprintf("%q", manifestReference.String()))
 	w.Header().Set(SwarmTagHeader, fmt.Sprint(tag.Uid))
 	w.Header().Set("Access-Control-Expose-Headers", SwarmTagHeader)
@@ -263,6 +287,22 @@ func (s *server) bzzDownloadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if requestAct(r) {
+		ref, err := s.actLookup(r, address)
+		if err != nil {
+			logger.Debugf("bzz download: access control lookup %s: %v", address, err)
+			logger.Error("bzz download: access control lookup")
+			if errors.Is(err, act.ErrNotGrantee) {
+				jsonhttp.Forbidden(w, "not a grantee")
+				return
+			}
+			jsonhttp.NotFound(w, nil)
+			return
+		}
+		address = ref
+	}
+	s.accessStats.RecordRoot(address)
+
 FETCH:
 	// read manifest entry
 	m, err := manifest.NewDefaultManifestReference(
@@ -454,12 +494,34 @@ func (s *server) downloadHandler(w http.ResponseWriter, r *http.Request, referen
 	if etag {
 		w.Header().Set("ETag", fmt.Sprintf("%q", reference))
 	}
-	w.Header().Set("Content-Length", fmt.Sprintf("%d", l))
 	w.Header().Set("Decompressed-Content-Length", fmt.Sprintf("%d", l))
+	w.Header().Set("Accept-Ranges", "bytes")
 	w.Header().Set("Access-Control-Expose-Headers", "Content-Disposition")
 	if targets != "" {
 		w.Header().Set(TargetsRecoveryHeader, targets)
 	}
+
+	ranges, err := requestRanges(r, w.Header().Get("ETag"), l)
+	if err != nil {
+		logger.Debugf("api download: range %s: %v", reference, err)
+		logger.Error("api download: invalid range")
+		w.Header().Del("Content-Disposition")
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", l))
+		jsonhttp.RequestedRangeNotSatisfiable(w, nil)
+		return
+	}
+	if len(ranges) > 0 {
+		if err := serveRanges(w, r, reader, l, ranges); err != nil {
+			logger.Debugf("api download: serve ranges %s: %v", reference, err)
+			logger.Error("api download: serve ranges")
+		}
+		return
+	}
+
+	// serve the whole content, the range header is either absent or should
+	// be ignored as the if-range validator did not match
+	r.Header.Del("Range")
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", l))
 	http.ServeContent(w, r, "", time.Now(), langos.NewBufferedLangos(reader, lookaheadBufferSize(l)))
 }
 
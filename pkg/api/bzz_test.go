@@ -7,6 +7,7 @@ package api_test
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -16,8 +17,12 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/feeds/epochs"
+	"github.com/ethersphere/bee/pkg/feeds/factory"
 	"github.com/ethersphere/bee/pkg/file/loadsave"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
@@ -26,6 +31,7 @@ import (
 	pinning "github.com/ethersphere/bee/pkg/pinning/mock"
 	mockpost "github.com/ethersphere/bee/pkg/postage/mock"
 	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/steward"
 	"github.com/ethersphere/bee/pkg/storage"
 	smock "github.com/ethersphere/bee/pkg/storage/mock"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -459,6 +465,66 @@ func TestBzzFilesRangeRequests(t *testing.T) {
 	}
 }
 
+// TestBytesRangeRequestsConditional validates unsatisfiable ranges and
+// If-Range handling of range requests.
+func TestBytesRangeRequestsConditional(t *testing.T) {
+	data := []byte("Lorem ipsum dolor sit amet, consectetur adipiscing elit.")
+
+	logger := logging.New(ioutil.Discard, 0)
+	client, _, _ := newTestServer(t, testServerOptions{
+		Storer: smock.NewStorer(),
+		Tags:   tags.NewTags(statestore.NewStateStore(), logger),
+		Logger: logger,
+		Post:   mockpost.New(mockpost.WithAcceptAll()),
+	})
+
+	var resp api.BytesPostResponse
+	jsonhttptest.Request(t, client, http.MethodPost, "/bytes", http.StatusCreated,
+		jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+		jsonhttptest.WithRequestBody(bytes.NewReader(data)),
+		jsonhttptest.WithUnmarshalJSONResponse(&resp),
+	)
+	downloadPath := "/bytes/" + resp.Reference.String()
+	etag := fmt.Sprintf("%q", resp.Reference.String())
+
+	t.Run("accept ranges", func(t *testing.T) {
+		respHeaders := jsonhttptest.Request(t, client, http.MethodGet, downloadPath, http.StatusOK,
+			jsonhttptest.WithExpectedResponse(data),
+		)
+		if got := respHeaders.Get("Accept-Ranges"); got != "bytes" {
+			t.Errorf("got accept ranges %q, want %q", got, "bytes")
+		}
+	})
+
+	t.Run("not satisfiable", func(t *testing.T) {
+		respHeaders := jsonhttptest.Request(t, client, http.MethodGet, downloadPath, http.StatusRequestedRangeNotSatisfiable,
+			jsonhttptest.WithRequestHeader("Range", fmt.Sprintf("bytes=%d-", len(data))),
+		)
+		if got, want := respHeaders.Get("Content-Range"), fmt.Sprintf("bytes */%d", len(data)); got != want {
+			t.Errorf("got content range %q, want %q", got, want)
+		}
+	})
+
+	t.Run("if-range match", func(t *testing.T) {
+		respHeaders := jsonhttptest.Request(t, client, http.MethodGet, downloadPath, http.StatusPartialContent,
+			jsonhttptest.WithRequestHeader("Range", "bytes=6-10"),
+			jsonhttptest.WithRequestHeader("If-Range", etag),
+			jsonhttptest.WithExpectedResponse(data[6:11]),
+		)
+		if got, want := respHeaders.Get("Content-Range"), fmt.Sprintf("bytes 6-10/%d", len(data)); got != want {
+			t.Errorf("got content range %q, want %q", got, want)
+		}
+	})
+
+	t.Run("if-range mismatch", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodGet, downloadPath, http.StatusOK,
+			jsonhttptest.WithRequestHeader("Range", "bytes=6-10"),
+			jsonhttptest.WithRequestHeader("If-Range", `"not-the-etag"`),
+			jsonhttptest.WithExpectedResponse(data),
+		)
+	})
+}
+
 func createRangeHeader(data []byte, ranges [][2]int) (header string, parts [][]byte) {
 	header = "bytes="
 	for i, r := range ranges {
@@ -601,6 +667,80 @@ func TestFeedIndirection(t *testing.T) {
 	)
 }
 
+func TestEpochFeedIndirection(t *testing.T) {
+	var (
+		mockStatestore = statestore.NewStateStore()
+		logger         = logging.New(ioutil.Discard, 0)
+		storer         = smock.NewStorer()
+		ctx            = context.Background()
+		topic          = bytes.Repeat([]byte{0xcd}, 32)
+		client, _, _   = newTestServer(t, testServerOptions{
+			Storer: storer,
+			Tags:   tags.NewTags(mockStatestore, logger),
+			Logger: logger,
+			Feeds:  factory.New(storer),
+			Post:   mockpost.New(mockpost.WithAcceptAll()),
+		})
+	)
+
+	// upload uploads a website with the given index document
+	upload := func(t *testing.T, data []byte) swarm.Address {
+		t.Helper()
+		var resp api.BzzUploadResponse
+		jsonhttptest.Request(t, client, http.MethodPost, "/bzz", http.StatusCreated,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithRequestBody(tarFiles(t, []f{{
+				data:     data,
+				name:     "index.html",
+				filePath: "./index.html",
+			}})),
+			jsonhttptest.WithRequestHeader("Content-Type", api.ContentTypeTar),
+			jsonhttptest.WithRequestHeader(api.SwarmCollectionHeader, "True"),
+			jsonhttptest.WithRequestHeader(api.SwarmIndexDocumentHeader, "index.html"),
+			jsonhttptest.WithUnmarshalJSONResponse(&resp),
+		)
+		return resp.Reference
+	}
+
+	pk, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	updater, err := epochs.NewUpdater(storer, crypto.NewDefaultSigner(pk), topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var feedResp api.FeedReferenceResponse
+	feedResource := fmt.Sprintf("/feeds/%s/%s?type=epoch", hex.EncodeToString(updater.Feed().Owner.Bytes()), hex.EncodeToString(topic))
+	jsonhttptest.Request(t, client, http.MethodPost, feedResource, http.StatusCreated,
+		jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+		jsonhttptest.WithUnmarshalJSONResponse(&feedResp),
+	)
+	bzzResource := "/bzz/" + feedResp.Reference.String() + "/"
+
+	jsonhttptest.Request(t, client, http.MethodGet, bzzResource, http.StatusNotFound,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: "no update found",
+			Code:    http.StatusNotFound,
+		}),
+	)
+
+	now := time.Now().Unix()
+	for i, data := range [][]byte{
+		[]byte("<h1>Swarm Epoch Feeds Hello World!</h1>"),
+		[]byte("<h1>Swarm Epoch Feeds Hello Again!</h1>"),
+	} {
+		ref := upload(t, data)
+		if err := updater.Update(ctx, now-int64(100-50*i), ref.Bytes()); err != nil {
+			t.Fatal(err)
+		}
+		jsonhttptest.Request(t, client, http.MethodGet, bzzResource, http.StatusOK,
+			jsonhttptest.WithExpectedResponse(data),
+		)
+	}
+}
+
 func TestBzzReupload(t *testing.T) {
 	var (
 		logger         = logging.New(ioutil.Discard, 0)
@@ -627,10 +767,52 @@ func TestBzzReupload(t *testing.T) {
 }
 
 type mockSteward struct {
-	addr swarm.Address
+	addr    swarm.Address
+	missing []swarm.Address
+	err     error
+	job     *steward.ReuploadStatus
 }
 
 func (m *mockSteward) Reupload(_ context.Context, addr swarm.Address) error {
 	m.addr = addr
 	return nil
 }
+
+func (m *mockSteward) IsRetrievable(_ context.Context, addr swarm.Address) ([]swarm.Address, error) {
+	m.addr = addr
+	return m.missing, m.err
+}
+
+func (m *mockSteward) StartReupload(root swarm.Address, concurrency int) (steward.ReuploadStatus, error) {
+	if concurrency < 1 || concurrency > steward.MaxConcurrency {
+		return steward.ReuploadStatus{}, steward.ErrInvalidConcurrency
+	}
+	if m.job != nil && m.job.State == steward.JobRunning {
+		return steward.ReuploadStatus{}, steward.ErrJobRunning
+	}
+	m.addr = root
+	m.job = &steward.ReuploadStatus{Root: root, State: steward.JobRunning, Concurrency: concurrency}
+	return *m.job, nil
+}
+
+func (m *mockSteward) ReuploadStatus(root swarm.Address) (steward.ReuploadStatus, error) {
+	if m.job == nil || !m.job.Root.Equal(root) {
+		return steward.ReuploadStatus{}, steward.ErrJobNotFound
+	}
+	return *m.job, nil
+}
+
+func (m *mockSteward) CancelReupload(root swarm.Address) error {
+	if m.job == nil || !m.job.Root.Equal(root) {
+		return steward.ErrJobNotFound
+	}
+	if m.job.State != steward.JobRunning {
+		return steward.ErrJobNotRunning
+	}
+	m.job.State = steward.JobCancelled
+	return nil
+}
+
+func (m *mockSteward) Close() error {
+	return nil
+}
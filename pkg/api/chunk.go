@@ -189,6 +189,7 @@ func (s *server) chunkGetHandler(w http.ResponseWriter, r *http.Request) {
 		jsonhttp.InternalServerError(w, "chunk read error")
 		return
 	}
+	s.accessStats.RecordChunk(address)
 	w.Header().Set("Content-Type", "binary/octet-stream")
 	if targets != "" {
 		w.Header().Set(TargetsRecoveryHeader, targets)
@@ -0,0 +1,264 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/cac"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/sctx"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/gorilla/websocket"
+)
+
+// chunkStream holds the upload options of a chunk stream that are set once
+// for the whole websocket connection.
+type chunkStream struct {
+	putter storage.Putter
+	mode   storage.ModePut
+	tag    *tags.Tag
+	pin    bool
+}
+
+// chunkUploadStreamHandler upgrades the connection to a websocket over which
+// chunks are uploaded as binary messages. Every uploaded chunk is
+// acknowledged with a text message holding either its address or the error
+// that occurred while storing it.
+func (s *server) chunkUploadStreamHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		ctx = context.Background()
+		tag *tags.Tag
+		err error
+	)
+
+	if h := r.Header.Get(SwarmTagHeader); h != "" {
+		tag, err = s.getTag(h)
+		if err != nil {
+			s.logger.Debugf("chunk stream: get tag: %v", err)
+			s.logger.Error("chunk stream: get tag")
+			jsonhttp.BadRequest(w, "cannot get tag")
+			return
+		}
+		// add the tag to the context if it exists
+		ctx = sctx.SetTag(ctx, tag)
+	}
+
+	batch, err := requestPostageBatchId(r)
+	if err != nil {
+		s.logger.Debugf("chunk stream: postage batch id: %v", err)
+		s.logger.Error("chunk stream: postage batch id")
+		jsonhttp.BadRequest(w, "invalid postage batch id")
+		return
+	}
+
+	putter, err := newStamperPutter(s.storer, s.post, s.signer, batch)
+	if err != nil {
+		s.logger.Debugf("chunk stream: putter: %v", err)
+		s.logger.Error("chunk stream: putter")
+		switch {
+		case errors.Is(err, postage.ErrNotFound):
+			jsonhttp.BadRequest(w, "batch not found")
+		case errors.Is(err, postage.ErrNotUsable):
+			jsonhttp.BadRequest(w, "batch not usable yet")
+		default:
+			jsonhttp.BadRequest(w, nil)
+		}
+		return
+	}
+
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  swarm.ChunkWithSpanSize,
+		WriteBufferSize: swarm.ChunkSize,
+		CheckOrigin:     s.checkOrigin,
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.logger.Debugf("chunk stream: upgrade: %v", err)
+		s.logger.Error("chunk stream: cannot upgrade")
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	cs := &chunkStream{
+		putter: putter,
+		mode:   requestModePut(r),
+		tag:    tag,
+		pin:    strings.ToLower(r.Header.Get(SwarmPinHeader)) == "true",
+	}
+
+	s.wsWg.Add(1)
+	go s.handleUploadStream(ctx, conn, cs)
+}
+
+func (s *server) handleUploadStream(ctx context.Context, conn *websocket.Conn, cs *chunkStream) {
+	defer s.wsWg.Done()
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	var (
+		dataC  = make(chan []byte)
+		gone   = make(chan struct{})
+		ticker = time.NewTicker(s.WsPingPeriod)
+		err    error
+	)
+	defer func() {
+		cancel()
+		ticker.Stop()
+		_ = conn.Close()
+	}()
+
+	conn.SetReadLimit(swarm.ChunkWithSpanSize)
+
+	// websocket connections support one concurrent reader and one
+	// concurrent writer, all writes are done in the loop below
+	go func() {
+		defer close(gone)
+		for {
+			mt, msg, err := conn.ReadMessage()
+			if err != nil {
+				if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+					s.logger.Debugf("chunk stream: read message: %v", err)
+				}
+				return
+			}
+			if mt != websocket.BinaryMessage {
+				msg = nil
+			}
+			select {
+			case dataC <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case data := <-dataC:
+			var resp interface{}
+			if data == nil {
+				resp = jsonhttp.StatusResponse{
+					Message: "invalid message type",
+					Code:    http.StatusBadRequest,
+				}
+			} else {
+				resp = s.uploadStreamChunk(ctx, cs, data)
+			}
+			err = conn.SetWriteDeadline(time.Now().Add(writeDeadline))
+			if err != nil {
+				s.logger.Debugf("chunk stream: set write deadline: %v", err)
+				return
+			}
+			if err = conn.WriteJSON(resp); err != nil {
+				s.logger.Debugf("chunk stream: write response: %v", err)
+				return
+			}
+
+		case <-s.quit:
+			// shutdown
+			err = conn.SetWriteDeadline(time.Now().Add(writeDeadline))
+			if err != nil {
+				s.logger.Debugf("chunk stream: set write deadline: %v", err)
+				return
+			}
+			err = conn.WriteMessage(websocket.CloseMessage, []byte{})
+			if err != nil {
+				s.logger.Debugf("chunk stream: write close message: %v", err)
+			}
+			return
+		case <-gone:
+			// client gone
+			return
+		case <-ticker.C:
+			err = conn.SetWriteDeadline(time.Now().Add(writeDeadline))
+			if err != nil {
+				s.logger.Debugf("chunk stream: set write deadline: %v", err)
+				return
+			}
+			if err = conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				// error encountered while pinging client. client probably gone
+				return
+			}
+		}
+	}
+}
+
+// uploadStreamChunk stores the chunk with span and data from a single stream
+// message and returns the response for it.
+func (s *server) uploadStreamChunk(ctx context.Context, cs *chunkStream, data []byte) interface{} {
+	if cs.tag != nil {
+		// increment the StateSplit here since we dont have a splitter for the file upload
+		if err := cs.tag.Inc(tags.StateSplit); err != nil {
+			s.logger.Debugf("chunk stream: increment tag: %v", err)
+			s.logger.Error("chunk stream: increment tag")
+			return streamError(http.StatusInternalServerError, "increment tag")
+		}
+	}
+
+	if len(data) < swarm.SpanSize {
+		s.logger.Debug("chunk stream: not enough data")
+		s.logger.Error("chunk stream: data length")
+		return streamError(http.StatusBadRequest, "data length")
+	}
+
+	chunk, err := cac.NewWithDataSpan(data)
+	if err != nil {
+		s.logger.Debugf("chunk stream: create chunk: %v", err)
+		s.logger.Error("chunk stream: create chunk")
+		return streamError(http.StatusBadRequest, "invalid chunk")
+	}
+
+	seen, err := cs.putter.Put(ctx, cs.mode, chunk)
+	if err != nil {
+		s.logger.Debugf("chunk stream: chunk write error: %v, addr %s", err, chunk.Address())
+		s.logger.Error("chunk stream: chunk write error")
+		if errors.Is(err, postage.ErrBucketFull) {
+			return streamError(http.StatusPaymentRequired, "batch is overissued")
+		}
+		return streamError(http.StatusInternalServerError, "chunk write error")
+	}
+
+	if cs.tag != nil {
+		if len(seen) > 0 && seen[0] {
+			if err := cs.tag.Inc(tags.StateSeen); err != nil {
+				s.logger.Debugf("chunk stream: increment tag: %v", err)
+				s.logger.Error("chunk stream: increment tag")
+				return streamError(http.StatusInternalServerError, "increment tag")
+			}
+		}
+		// indicate that the chunk is stored
+		if err := cs.tag.Inc(tags.StateStored); err != nil {
+			s.logger.Debugf("chunk stream: increment tag: %v", err)
+			s.logger.Error("chunk stream: increment tag")
+			return streamError(http.StatusInternalServerError, "increment tag")
+		}
+	}
+
+	if cs.pin {
+		if err := s.pinning.CreatePin(ctx, chunk.Address(), false); err != nil {
+			s.logger.Debugf("chunk stream: creation of pin for %q failed: %v", chunk.Address(), err)
+			s.logger.Error("chunk stream: creation of pin failed")
+			return streamError(http.StatusInternalServerError, "creation of pin failed")
+		}
+	}
+
+	return chunkAddressResponse{Reference: chunk.Address()}
+}
+
+func streamError(code int, message string) jsonhttp.StatusResponse {
+	return jsonhttp.StatusResponse{
+		Message: message,
+		Code:    code,
+	}
+}
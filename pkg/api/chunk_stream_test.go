@@ -0,0 +1,155 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/logging"
+	pinning "github.com/ethersphere/bee/pkg/pinning/mock"
+	mockpost "github.com/ethersphere/bee/pkg/postage/mock"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	testingc "github.com/ethersphere/bee/pkg/storage/testing"
+	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/gorilla/websocket"
+)
+
+func TestChunkUploadStream(t *testing.T) {
+	var (
+		logger      = logging.New(ioutil.Discard, 0)
+		tagsService = tags.NewTags(statestore.NewStateStore(), logger)
+		storerMock  = mock.NewStorer()
+		_, _, addr  = newTestServer(t, testServerOptions{
+			Storer:  storerMock,
+			Pinning: pinning.NewServiceMock(),
+			Tags:    tagsService,
+			Logger:  logger,
+			Post:    mockpost.New(mockpost.WithAcceptAll()),
+		})
+	)
+
+	tag, err := tagsService.Create(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dial := func(t *testing.T, header http.Header) *websocket.Conn {
+		t.Helper()
+
+		u := url.URL{Scheme: "ws", Host: addr, Path: "/chunks/stream"}
+		conn, _, err := websocket.DefaultDialer.Dial(u.String(), header)
+		if err != nil {
+			t.Fatalf("dial: %v. url %v", err, u.String())
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+		return conn
+	}
+
+	expectResponse := func(t *testing.T, conn *websocket.Conn, want interface{}) {
+		t.Helper()
+
+		err := conn.SetReadDeadline(time.Now().Add(time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+		mt, got, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mt != websocket.TextMessage {
+			t.Fatalf("got message type %d, want %d", mt, websocket.TextMessage)
+		}
+		wantBytes, err := json.Marshal(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(bytes.TrimSpace(got), wantBytes) {
+			t.Fatalf("got response %s, want %s", got, wantBytes)
+		}
+	}
+
+	t.Run("upload", func(t *testing.T) {
+		conn := dial(t, http.Header{
+			api.SwarmPostageBatchIdHeader: {batchOkStr},
+			api.SwarmTagHeader:            {strconv.FormatUint(uint64(tag.Uid), 10)},
+		})
+
+		for i := 0; i < 5; i++ {
+			ch := testingc.GenerateTestRandomChunk()
+
+			err := conn.WriteMessage(websocket.BinaryMessage, ch.Data())
+			if err != nil {
+				t.Fatal(err)
+			}
+			expectResponse(t, conn, api.ChunkAddressResponse{Reference: ch.Address()})
+
+			has, err := storerMock.Has(context.Background(), ch.Address())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !has {
+				t.Fatalf("chunk %s not stored", ch.Address())
+			}
+		}
+
+		if got := tag.Get(tags.StateStored); got != 5 {
+			t.Fatalf("got %d stored chunks on tag, want %d", got, 5)
+		}
+	})
+
+	t.Run("invalid messages", func(t *testing.T) {
+		conn := dial(t, http.Header{
+			api.SwarmPostageBatchIdHeader: {batchOkStr},
+		})
+
+		err := conn.WriteMessage(websocket.TextMessage, []byte("hello"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectResponse(t, conn, jsonhttp.StatusResponse{
+			Message: "invalid message type",
+			Code:    http.StatusBadRequest,
+		})
+
+		err = conn.WriteMessage(websocket.BinaryMessage, []byte{1, 2, 3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectResponse(t, conn, jsonhttp.StatusResponse{
+			Message: "data length",
+			Code:    http.StatusBadRequest,
+		})
+
+		// the stream keeps accepting chunks after errors
+		ch := testingc.GenerateTestRandomChunk()
+		err = conn.WriteMessage(websocket.BinaryMessage, ch.Data())
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectResponse(t, conn, api.ChunkAddressResponse{Reference: ch.Address()})
+	})
+
+	t.Run("no batch", func(t *testing.T) {
+		u := url.URL{Scheme: "ws", Host: addr, Path: "/chunks/stream"}
+		_, resp, err := websocket.DefaultDialer.Dial(u.String(), nil)
+		if err == nil {
+			t.Fatal("expected dial error")
+		}
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusBadRequest)
+		}
+	})
+}
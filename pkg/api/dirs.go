@@ -78,6 +78,7 @@ func (s *server) dirUploadHandler(w http.ResponseWriter, r *http.Request, storer
 		dReader,
 		s.logger,
 		requestPipelineFn(storer, r),
+		s.uploadPolicy,
 		loadsave.New(storer, requestModePut(r), requestEncrypt(r)),
 		r.Header.Get(SwarmIndexDocumentHeader),
 		r.Header.Get(SwarmErrorDocumentHeader),
@@ -90,6 +91,12 @@ func (s *server) dirUploadHandler(w http.ResponseWriter, r *http.Request, storer
 		switch {
 		case errors.Is(err, postage.ErrBucketFull):
 			jsonhttp.PaymentRequired(w, "batch is overissued")
+		case errors.Is(err, errUploadTooLarge):
+			jsonhttp.RequestEntityTooLarge(w, errUploadTooLarge)
+		case errors.Is(err, errTooManyFiles):
+			jsonhttp.RequestEntityTooLarge(w, errTooManyFiles)
+		case errors.Is(err, errUnsupportedContentType):
+			jsonhttp.UnsupportedMediaType(w, errUnsupportedContentType)
 		default:
 			jsonhttp.InternalServerError(w, errDirectoryStore)
 		}
@@ -114,6 +121,21 @@ func (s *server) dirUploadHandler(w http.ResponseWriter, r *http.Request, storer
 		}
 	}
 
+	if requestAct(r) {
+		reference, err = s.actCreate(r, storer, reference)
+		if err != nil {
+			logger.Debugf("bzz upload dir: create access control trie: %v", err)
+			logger.Error("bzz upload dir: create access control trie")
+			switch {
+			case errors.Is(err, postage.ErrBucketFull):
+				jsonhttp.PaymentRequired(w, "batch is overissued")
+			default:
+				jsonhttp.InternalServerError(w, nil)
+			}
+			return
+		}
+	}
+
 	w.Header().Set(SwarmTagHeader, fmt.Sprint(tag.Uid))
 	jsonhttp.Created(w, bzzUploadResponse{
 		Reference: reference,
@@ -128,6 +150,7 @@ func storeDir(
 	reader dirReader,
 	log logging.Logger,
 	p pipelineFunc,
+	policy uploadPolicy,
 	ls file.LoadSaver,
 	indexFilename,
 	errorFilename string,
@@ -156,6 +179,13 @@ func storeDir(
 			return swarm.ZeroAddress, fmt.Errorf("read tar stream: %w", err)
 		}
 
+		if !policy.allowFiles(filesAdded + 1) {
+			return swarm.ZeroAddress, errTooManyFiles
+		}
+		if !policy.allowContentType(fileInfo.ContentType) {
+			return swarm.ZeroAddress, fmt.Errorf("file %s: %w", fileInfo.Path, errUnsupportedContentType)
+		}
+
 		if !tagCreated {
 			// only in the case when tag is sent via header (i.e. not created by this request)
 			// for each file
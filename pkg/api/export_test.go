@@ -9,17 +9,32 @@ import "github.com/ethersphere/bee/pkg/swarm"
 type Server = server
 
 type (
-	BytesPostResponse     = bytesPostResponse
-	ChunkAddressResponse  = chunkAddressResponse
-	SocPostResponse       = socPostResponse
-	FeedReferenceResponse = feedReferenceResponse
-	BzzUploadResponse     = bzzUploadResponse
-	TagResponse           = tagResponse
-	TagRequest            = tagRequest
-	ListTagsResponse      = listTagsResponse
-	PostageCreateResponse = postageCreateResponse
-	PostageStampResponse  = postageStampResponse
-	PostageStampsResponse = postageStampsResponse
+	BytesPostResponse       = bytesPostResponse
+	ChunkAddressResponse    = chunkAddressResponse
+	SocPostResponse         = socPostResponse
+	FeedReferenceResponse   = feedReferenceResponse
+	FeedHistoryEntry        = feedHistoryEntry
+	FeedHistoryResponse     = feedHistoryResponse
+	FeedUpdateRequest       = feedUpdateRequest
+	FeedUpdateResponse      = feedUpdateResponse
+	BzzUploadResponse       = bzzUploadResponse
+	TagResponse             = tagResponse
+	TagStreamResponse       = tagStreamResponse
+	TagRequest              = tagRequest
+	ListTagsResponse        = listTagsResponse
+	PostageCreateResponse   = postageCreateResponse
+	PostageStampResponse    = postageStampResponse
+	PostageStampsResponse   = postageStampsResponse
+	ActGranteesResponse     = actGranteesResponse
+	ActGranteesPatchRequest = actGranteesPatchRequest
+	ActReferenceResponse    = actReferenceResponse
+	IsRetrievableResponse   = isRetrievableResponse
+	PssMailboxResponse      = pssMailboxResponse
+	PssMailboxMessage       = pssMailboxMessage
+	PssMessagesResponse     = pssMessagesResponse
+	ReuploadJobResponse     = reuploadJobResponse
+	AuthRequest             = authRequest
+	AuthResponse            = authResponse
 )
 
 var (
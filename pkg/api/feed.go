@@ -7,8 +7,10 @@ package api
 import (
 	"encoding/binary"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"strconv"
 	"strings"
@@ -29,6 +31,9 @@ const (
 	feedMetadataEntryOwner = "swarm-feed-owner"
 	feedMetadataEntryTopic = "swarm-feed-topic"
 	feedMetadataEntryType  = "swarm-feed-type"
+
+	feedHistoryDefaultLimit = 100
+	feedHistoryMaxLimit     = 1000
 )
 
 var errInvalidFeedUpdate = errors.New("invalid feed update")
@@ -124,6 +129,119 @@ func (s *server) feedGetHandler(w http.ResponseWriter, r *http.Request) {
 	jsonhttp.OK(w, feedReferenceResponse{Reference: ref})
 }
 
+type feedHistoryEntry struct {
+	Index     string        `json:"index"`
+	Timestamp uint64        `json:"timestamp"`
+	Reference swarm.Address `json:"reference"`
+}
+
+type feedHistoryResponse struct {
+	Updates []feedHistoryEntry `json:"updates"`
+}
+
+func (s *server) feedHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	owner, err := hex.DecodeString(mux.Vars(r)["owner"])
+	if err != nil {
+		s.logger.Debugf("feed history: decode owner: %v", err)
+		s.logger.Error("feed history: bad owner")
+		jsonhttp.BadRequest(w, "bad owner")
+		return
+	}
+
+	topic, err := hex.DecodeString(mux.Vars(r)["topic"])
+	if err != nil {
+		s.logger.Debugf("feed history: decode topic: %v", err)
+		s.logger.Error("feed history: bad topic")
+		jsonhttp.BadRequest(w, "bad topic")
+		return
+	}
+
+	feedType := feeds.Sequence
+	if typeStr := r.URL.Query().Get("type"); typeStr != "" {
+		if err := feedType.FromString(typeStr); err != nil {
+			s.logger.Debugf("feed history: decode type: %v", err)
+			s.logger.Error("feed history: bad type")
+			jsonhttp.BadRequest(w, "bad type")
+			return
+		}
+	}
+
+	var from int64
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		from, err = strconv.ParseInt(fromStr, 10, 64)
+		if err != nil || from < 0 {
+			s.logger.Debugf("feed history: decode from: %v", err)
+			s.logger.Error("feed history: bad from")
+			jsonhttp.BadRequest(w, "bad from")
+			return
+		}
+	}
+
+	to := time.Now().Unix()
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		to, err = strconv.ParseInt(toStr, 10, 64)
+		if err != nil || to < from {
+			s.logger.Debugf("feed history: decode to: %v", err)
+			s.logger.Error("feed history: bad to")
+			jsonhttp.BadRequest(w, "bad to")
+			return
+		}
+	}
+
+	limit := feedHistoryDefaultLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > feedHistoryMaxLimit {
+			s.logger.Debugf("feed history: decode limit: %v", err)
+			s.logger.Error("feed history: bad limit")
+			jsonhttp.BadRequest(w, "bad limit")
+			return
+		}
+	}
+
+	f := feeds.New(topic, common.BytesToAddress(owner))
+	history, err := s.feedFactory.NewHistory(feedType, f)
+	if err != nil {
+		s.logger.Debugf("feed history: new history: %v", err)
+		s.logger.Error("feed history: new history")
+		jsonhttp.InternalServerError(w, "new history")
+		return
+	}
+
+	entries, err := history.Updates(r.Context(), from, to, limit)
+	if err != nil {
+		s.logger.Debugf("feed history: updates: %v", err)
+		s.logger.Error("feed history: updates")
+		jsonhttp.NotFound(w, "lookup failed")
+		return
+	}
+
+	resp := feedHistoryResponse{Updates: make([]feedHistoryEntry, 0, len(entries))}
+	for _, e := range entries {
+		ref, _, err := parseFeedUpdate(e.Chunk)
+		if err != nil {
+			s.logger.Debugf("feed history: parse update: %v", err)
+			s.logger.Error("feed history: parse update")
+			jsonhttp.InternalServerError(w, "parse update")
+			return
+		}
+		idx, err := e.Index.MarshalBinary()
+		if err != nil {
+			s.logger.Debugf("feed history: marshal index: %v", err)
+			s.logger.Error("feed history: marshal index")
+			jsonhttp.InternalServerError(w, "marshal index")
+			return
+		}
+		resp.Updates = append(resp.Updates, feedHistoryEntry{
+			Index:     hex.EncodeToString(idx),
+			Timestamp: e.Timestamp,
+			Reference: ref,
+		})
+	}
+
+	jsonhttp.OK(w, resp)
+}
+
 func (s *server) feedPostHandler(w http.ResponseWriter, r *http.Request) {
 	owner, err := hex.DecodeString(mux.Vars(r)["owner"])
 	if err != nil {
@@ -141,6 +259,16 @@ func (s *server) feedPostHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	feedType := feeds.Sequence
+	if typeStr := r.URL.Query().Get("type"); typeStr != "" {
+		if err := feedType.FromString(typeStr); err != nil {
+			s.logger.Debugf("feed put: decode type: %v", err)
+			s.logger.Error("feed put: bad type")
+			jsonhttp.BadRequest(w, "bad type")
+			return
+		}
+	}
+
 	batch, err := requestPostageBatchId(r)
 	if err != nil {
 		s.logger.Debugf("feed put: postage batch id: %v", err)
@@ -176,7 +304,7 @@ func (s *server) feedPostHandler(w http.ResponseWriter, r *http.Request) {
 	meta := map[string]string{
 		feedMetadataEntryOwner: hex.EncodeToString(owner),
 		feedMetadataEntryTopic: hex.EncodeToString(topic),
-		feedMetadataEntryType:  feeds.Sequence.String(), // only sequence allowed for now
+		feedMetadataEntryType:  feedType.String(),
 	}
 
 	emptyAddr := make([]byte, 32)
@@ -214,6 +342,141 @@ func (s *server) feedPostHandler(w http.ResponseWriter, r *http.Request) {
 	jsonhttp.Created(w, feedReferenceResponse{Reference: ref})
 }
 
+type feedUpdateRequest struct {
+	Reference swarm.Address `json:"reference"`
+}
+
+type feedUpdateResponse struct {
+	Owner string `json:"owner"`
+	Index string `json:"index"`
+}
+
+// feedUpdateHandler publishes an update of a feed owned by the node. The
+// update is signed by the node and placed at the next index of the feed.
+func (s *server) feedUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	topic, err := hex.DecodeString(mux.Vars(r)["topic"])
+	if err != nil {
+		s.logger.Debugf("feed update: decode topic: %v", err)
+		s.logger.Error("feed update: bad topic")
+		jsonhttp.BadRequest(w, "bad topic")
+		return
+	}
+
+	feedType := feeds.Sequence
+	if typeStr := r.URL.Query().Get("type"); typeStr != "" {
+		if err := feedType.FromString(typeStr); err != nil {
+			s.logger.Debugf("feed update: decode type: %v", err)
+			s.logger.Error("feed update: bad type")
+			jsonhttp.BadRequest(w, "bad type")
+			return
+		}
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		if jsonhttp.HandleBodyReadError(err, w) {
+			return
+		}
+		s.logger.Debugf("feed update: read request body: %v", err)
+		s.logger.Error("feed update: read request body")
+		jsonhttp.InternalServerError(w, "cannot read request")
+		return
+	}
+	var req feedUpdateRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.logger.Debugf("feed update: unmarshal request: %v", err)
+		s.logger.Error("feed update: unmarshal request")
+		jsonhttp.BadRequest(w, "bad reference")
+		return
+	}
+	if l := len(req.Reference.Bytes()); l != swarm.HashSize && l != swarm.HashSize*2 {
+		s.logger.Error("feed update: bad reference")
+		jsonhttp.BadRequest(w, "bad reference")
+		return
+	}
+
+	batch, err := requestPostageBatchId(r)
+	if err != nil {
+		s.logger.Debugf("feed update: postage batch id: %v", err)
+		s.logger.Error("feed update: postage batch id")
+		jsonhttp.BadRequest(w, "invalid postage batch id")
+		return
+	}
+
+	putter, err := newStamperPutter(s.storer, s.post, s.signer, batch)
+	if err != nil {
+		s.logger.Debugf("feed update: putter: %v", err)
+		s.logger.Error("feed update: putter")
+		switch {
+		case errors.Is(err, postage.ErrNotFound):
+			jsonhttp.BadRequest(w, "batch not found")
+		case errors.Is(err, postage.ErrNotUsable):
+			jsonhttp.BadRequest(w, "batch not usable yet")
+		default:
+			jsonhttp.BadRequest(w, nil)
+		}
+		return
+	}
+
+	feedPutter, err := feeds.NewPutter(putter, s.signer, topic)
+	if err != nil {
+		s.logger.Debugf("feed update: new putter: %v", err)
+		s.logger.Error("feed update: new putter")
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	lookup, err := s.feedFactory.NewLookup(feedType, feedPutter.Feed)
+	if err != nil {
+		s.logger.Debugf("feed update: new lookup: %v", err)
+		s.logger.Error("feed update: new lookup")
+		jsonhttp.InternalServerError(w, "new lookup")
+		return
+	}
+
+	at := time.Now().Unix()
+	ch, _, next, err := lookup.At(r.Context(), at, 0)
+	if err != nil {
+		s.logger.Debugf("feed update: lookup: %v", err)
+		s.logger.Error("feed update: lookup")
+		jsonhttp.InternalServerError(w, "lookup failed")
+		return
+	}
+	if ch != nil && feedType == feeds.Epoch {
+		// an epoch feed can not have two updates within the same second
+		if ts, err := feeds.UpdatedAt(ch); err == nil && int64(ts) >= at {
+			s.logger.Error("feed update: update too frequent")
+			jsonhttp.Conflict(w, "update too frequent")
+			return
+		}
+	}
+
+	if err := feedPutter.Put(r.Context(), next, at, req.Reference.Bytes()); err != nil {
+		s.logger.Debugf("feed update: put update: %v", err)
+		s.logger.Error("feed update: put update")
+		switch {
+		case errors.Is(err, postage.ErrBucketFull):
+			jsonhttp.PaymentRequired(w, "batch is overissued")
+		default:
+			jsonhttp.InternalServerError(w, nil)
+		}
+		return
+	}
+
+	nextBytes, err := next.MarshalBinary()
+	if err != nil {
+		s.logger.Debugf("feed update: marshal index: %v", err)
+		s.logger.Error("feed update: marshal index")
+		jsonhttp.InternalServerError(w, "marshal index")
+		return
+	}
+
+	jsonhttp.Created(w, feedUpdateResponse{
+		Owner: hex.EncodeToString(feedPutter.Owner.Bytes()),
+		Index: hex.EncodeToString(nextBytes),
+	})
+}
+
 func parseFeedUpdate(ch swarm.Chunk) (swarm.Address, int64, error) {
 	s, err := soc.FromChunk(ch)
 	if err != nil {
@@ -15,9 +15,14 @@ import (
 	"math/big"
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/feeds"
+	"github.com/ethersphere/bee/pkg/feeds/factory"
+	"github.com/ethersphere/bee/pkg/feeds/sequence"
 	"github.com/ethersphere/bee/pkg/file/loadsave"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
@@ -195,6 +200,35 @@ func TestFeed_Post(t *testing.T) {
 			t.Fatalf("type mismatch. got %s want %s", e, "Sequence")
 		}
 	})
+	t.Run("epoch", func(t *testing.T) {
+		var resp api.FeedReferenceResponse
+		jsonhttptest.Request(t, client, http.MethodPost, fmt.Sprintf("/feeds/%s/%s?type=epoch", ownerString, topic), http.StatusCreated,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithUnmarshalJSONResponse(&resp),
+		)
+
+		ls := loadsave.New(mockStorer, storage.ModePutUpload, false)
+		i, err := manifest.NewMantarayManifestReference(resp.Reference, ls)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e, err := i.Lookup(context.Background(), "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e := e.Metadata()[api.FeedMetadataEntryType]; e != "Epoch" {
+			t.Fatalf("type mismatch. got %s want %s", e, "Epoch")
+		}
+	})
+	t.Run("bad type", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, fmt.Sprintf("/feeds/%s/%s?type=xyz", ownerString, topic), http.StatusBadRequest,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "bad type",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
 	t.Run("postage", func(t *testing.T) {
 		t.Run("err - bad batch", func(t *testing.T) {
 			hexbatch := hex.EncodeToString(batchInvalid)
@@ -222,6 +256,219 @@ func TestFeed_Post(t *testing.T) {
 
 }
 
+func TestFeed_History(t *testing.T) {
+	var (
+		mockStorer = mock.NewStorer()
+		logger     = logging.New(ioutil.Discard, 0)
+		ctx        = context.Background()
+		topic      = bytes.Repeat([]byte{0xab}, 32)
+		refs       []swarm.Address
+		ats        []int64
+	)
+	client, _, _ := newTestServer(t, testServerOptions{
+		Storer: mockStorer,
+		Tags:   tags.NewTags(statestore.NewStateStore(), logger),
+		Feeds:  factory.New(mockStorer),
+	})
+
+	pk, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	updater, err := sequence.NewUpdater(mockStorer, crypto.NewDefaultSigner(pk), topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		ref := swarm.NewAddress(bytes.Repeat([]byte{byte(i + 1)}, 32))
+		at := int64(1000 + 10*i)
+		if err := updater.Update(ctx, at, ref.Bytes()); err != nil {
+			t.Fatal(err)
+		}
+		refs = append(refs, ref)
+		ats = append(ats, at)
+	}
+
+	owner := hex.EncodeToString(updater.Feed().Owner.Bytes())
+	historyResource := fmt.Sprintf("/feeds/%s/%s/history", owner, hex.EncodeToString(topic))
+	entry := func(i int) api.FeedHistoryEntry {
+		idx := make([]byte, 8)
+		binary.BigEndian.PutUint64(idx, uint64(i))
+		return api.FeedHistoryEntry{
+			Index:     hex.EncodeToString(idx),
+			Timestamp: uint64(ats[i]),
+			Reference: refs[i],
+		}
+	}
+
+	t.Run("all", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodGet, historyResource, http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.FeedHistoryResponse{
+				Updates: []api.FeedHistoryEntry{entry(4), entry(3), entry(2), entry(1), entry(0)},
+			}),
+		)
+	})
+
+	t.Run("range", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodGet, historyResource+"?from=1010&to=1035&limit=2", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.FeedHistoryResponse{
+				Updates: []api.FeedHistoryEntry{entry(3), entry(2)},
+			}),
+		)
+	})
+
+	t.Run("no updates", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodGet, historyResource+"?to=999", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.FeedHistoryResponse{
+				Updates: []api.FeedHistoryEntry{},
+			}),
+		)
+	})
+
+	t.Run("bad limit", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodGet, historyResource+"?limit=0", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "bad limit",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("bad type", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodGet, historyResource+"?type=xyz", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "bad type",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+}
+
+func TestFeed_Update(t *testing.T) {
+	var (
+		mockStorer     = mock.NewStorer()
+		logger         = logging.New(ioutil.Discard, 0)
+		topic          = hex.EncodeToString(bytes.Repeat([]byte{0xab}, 32))
+		updateResource = func(topic, typ string) string {
+			if typ != "" {
+				return fmt.Sprintf("/feeds/%s/update?type=%s", topic, typ)
+			}
+			return fmt.Sprintf("/feeds/%s/update", topic)
+		}
+		feedFactory  = factory.New(mockStorer)
+		client, _, _ = newTestServer(t, testServerOptions{
+			Storer: mockStorer,
+			Tags:   tags.NewTags(statestore.NewStateStore(), logger),
+			Feeds:  feedFactory,
+			Logger: logger,
+			Post:   mockpost.New(mockpost.WithAcceptAll()),
+		})
+	)
+
+	// latest resolves the latest update of the feed and
+	// returns its reference
+	latest := func(t *testing.T, typ feeds.Type, owner string) swarm.Address {
+		t.Helper()
+		o, err := hex.DecodeString(owner)
+		if err != nil {
+			t.Fatal(err)
+		}
+		topicBytes, _ := hex.DecodeString(topic)
+		lookup, err := feedFactory.NewLookup(typ, feeds.New(topicBytes, common.BytesToAddress(o)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ch, _, _, err := lookup.At(context.Background(), time.Now().Unix(), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ch == nil {
+			t.Fatal("no update found")
+		}
+		_, ref, err := feeds.FromChunk(ch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return swarm.NewAddress(ref)
+	}
+
+	t.Run("sequence", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			ref := swarm.NewAddress(bytes.Repeat([]byte{byte(i + 1)}, 32))
+			var resp api.FeedUpdateResponse
+			jsonhttptest.Request(t, client, http.MethodPost, updateResource(topic, ""), http.StatusCreated,
+				jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+				jsonhttptest.WithJSONRequestBody(api.FeedUpdateRequest{Reference: ref}),
+				jsonhttptest.WithUnmarshalJSONResponse(&resp),
+			)
+			idx := make([]byte, 8)
+			binary.BigEndian.PutUint64(idx, uint64(i))
+			if resp.Index != hex.EncodeToString(idx) {
+				t.Fatalf("got index %s, want %x", resp.Index, idx)
+			}
+			if got := latest(t, feeds.Sequence, resp.Owner); !got.Equal(ref) {
+				t.Fatalf("got reference %s, want %s", got, ref)
+			}
+		}
+	})
+
+	t.Run("epoch", func(t *testing.T) {
+		ref := swarm.NewAddress(bytes.Repeat([]byte{0xee}, 32))
+		var resp api.FeedUpdateResponse
+		jsonhttptest.Request(t, client, http.MethodPost, updateResource(topic, "epoch"), http.StatusCreated,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithJSONRequestBody(api.FeedUpdateRequest{Reference: ref}),
+			jsonhttptest.WithUnmarshalJSONResponse(&resp),
+		)
+		if got := latest(t, feeds.Epoch, resp.Owner); !got.Equal(ref) {
+			t.Fatalf("got reference %s, want %s", got, ref)
+		}
+	})
+
+	t.Run("bad topic", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, updateResource("xyz", ""), http.StatusBadRequest,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithJSONRequestBody(api.FeedUpdateRequest{Reference: expReference}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "bad topic",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("bad type", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, updateResource(topic, "xyz"), http.StatusBadRequest,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithJSONRequestBody(api.FeedUpdateRequest{Reference: expReference}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "bad type",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("bad reference", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, updateResource(topic, ""), http.StatusBadRequest,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithRequestBody(bytes.NewReader([]byte(`{"reference":"abcd"}`))),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "bad reference",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("no batch", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, updateResource(topic, ""), http.StatusBadRequest,
+			jsonhttptest.WithJSONRequestBody(api.FeedUpdateRequest{Reference: expReference}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "invalid postage batch id",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+}
+
 type factoryMock struct {
 	sequenceCalled bool
 	epochCalled    bool
@@ -244,6 +491,10 @@ func (f *factoryMock) NewLookup(t feeds.Type, feed *feeds.Feed) (feeds.Lookup, e
 	return f.lookup, nil
 }
 
+func (f *factoryMock) NewHistory(t feeds.Type, feed *feeds.Feed) (feeds.History, error) {
+	return nil, errors.New("not implemented")
+}
+
 type mockLookup struct {
 	at, after int64
 	chunk     swarm.Chunk
@@ -100,3 +100,96 @@ func TestGatewayMode(t *testing.T) {
 		jsonhttptest.Request(t, client, http.MethodPost, "/dirs", http.StatusForbidden, forbiddenResponseOption, headerOption)
 	})
 }
+
+func TestGatewayModeUploadPolicy(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+	client, _, _ := newTestServer(t, testServerOptions{
+		Storer:           mock.NewStorer(),
+		Tags:             tags.NewTags(statestore.NewStateStore(), logger),
+		Logger:           logger,
+		GatewayMode:      true,
+		Post:             mockpost.New(mockpost.WithAcceptAll()),
+		GatewayMaxUpload: 4096,
+		GatewayTypes:     []string{"text/*", "application/json"},
+		GatewayMaxFiles:  2,
+	})
+
+	batchOption := jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr)
+	tooLargeOption := func(message string) jsonhttptest.Option {
+		return jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: message,
+			Code:    http.StatusRequestEntityTooLarge,
+		})
+	}
+	unsupportedOption := jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+		Message: "unsupported content type",
+		Code:    http.StatusUnsupportedMediaType,
+	})
+
+	t.Run("size", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, "/bytes", http.StatusCreated, batchOption,
+			jsonhttptest.WithRequestBody(bytes.NewReader(make([]byte, 4096))),
+		)
+		// the declared content length is rejected upfront
+		jsonhttptest.Request(t, client, http.MethodPost, "/bytes", http.StatusRequestEntityTooLarge, batchOption,
+			jsonhttptest.WithRequestBody(bytes.NewReader(make([]byte, 4097))),
+			tooLargeOption("upload too large"),
+		)
+		// the streamed content is aborted once it exceeds the limit
+		jsonhttptest.Request(t, client, http.MethodPost, "/bytes", http.StatusRequestEntityTooLarge, batchOption,
+			jsonhttptest.WithRequestBody(ioutil.NopCloser(bytes.NewReader(make([]byte, 10000)))),
+			tooLargeOption("upload too large"),
+		)
+		jsonhttptest.Request(t, client, http.MethodPost, "/bzz", http.StatusRequestEntityTooLarge, batchOption,
+			jsonhttptest.WithRequestHeader("Content-Type", "text/plain"),
+			jsonhttptest.WithRequestBody(ioutil.NopCloser(bytes.NewReader(make([]byte, 10000)))),
+			tooLargeOption("upload too large"),
+		)
+	})
+
+	t.Run("content type", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, "/bzz?name=a.txt", http.StatusCreated, batchOption,
+			jsonhttptest.WithRequestHeader("Content-Type", "text/plain; charset=utf-8"),
+			jsonhttptest.WithRequestBody(bytes.NewReader([]byte("text"))),
+		)
+		jsonhttptest.Request(t, client, http.MethodPost, "/bzz?name=a.json", http.StatusCreated, batchOption,
+			jsonhttptest.WithRequestHeader("Content-Type", "application/json"),
+			jsonhttptest.WithRequestBody(bytes.NewReader([]byte("{}"))),
+		)
+		jsonhttptest.Request(t, client, http.MethodPost, "/bzz?name=a.png", http.StatusUnsupportedMediaType, batchOption,
+			jsonhttptest.WithRequestHeader("Content-Type", "image/png"),
+			jsonhttptest.WithRequestBody(bytes.NewReader([]byte("png"))),
+			unsupportedOption,
+		)
+		jsonhttptest.Request(t, client, http.MethodPost, "/bzz", http.StatusUnsupportedMediaType, batchOption,
+			jsonhttptest.WithRequestHeader("Content-Type", api.ContentTypeTar),
+			jsonhttptest.WithRequestHeader(api.SwarmCollectionHeader, "true"),
+			jsonhttptest.WithRequestBody(tarFiles(t, []f{
+				{data: []byte("text"), name: "a.txt"},
+				{data: []byte("png"), name: "a.png"},
+			})),
+			unsupportedOption,
+		)
+	})
+
+	t.Run("manifest files", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, "/bzz", http.StatusCreated, batchOption,
+			jsonhttptest.WithRequestHeader("Content-Type", api.ContentTypeTar),
+			jsonhttptest.WithRequestHeader(api.SwarmCollectionHeader, "true"),
+			jsonhttptest.WithRequestBody(tarFiles(t, []f{
+				{data: []byte("a"), name: "a.txt"},
+				{data: []byte("b"), name: "b.txt"},
+			})),
+		)
+		jsonhttptest.Request(t, client, http.MethodPost, "/bzz", http.StatusRequestEntityTooLarge, batchOption,
+			jsonhttptest.WithRequestHeader("Content-Type", api.ContentTypeTar),
+			jsonhttptest.WithRequestHeader(api.SwarmCollectionHeader, "true"),
+			jsonhttptest.WithRequestBody(tarFiles(t, []f{
+				{data: []byte("a"), name: "a.txt"},
+				{data: []byte("b"), name: "b.txt"},
+				{data: []byte("c"), name: "c.txt"},
+			})),
+			tooLargeOption("too many files"),
+		)
+	})
+}
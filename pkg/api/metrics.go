@@ -21,6 +21,7 @@ type metrics struct {
 	ResponseDuration   prometheus.Histogram
 	PingRequestCount   prometheus.Counter
 	ResponseCodeCounts *prometheus.CounterVec
+	RateLimitedCount   *prometheus.CounterVec
 }
 
 func newMetrics() metrics {
@@ -49,6 +50,15 @@ func newMetrics() metrics {
 			},
 			[]string{"code", "method"},
 		),
+		RateLimitedCount: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: m.Namespace,
+				Subsystem: subsystem,
+				Name:      "rate_limited_count",
+				Help:      "Number of requests rejected by the rate limits grouped by client kind and limit",
+			},
+			[]string{"client", "limit"},
+		),
 	}
 }
 
@@ -5,6 +5,7 @@
 package api_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"errors"
@@ -12,12 +13,14 @@ import (
 	"math/big"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/api"
 	"github.com/ethersphere/bee/pkg/bigint"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
 	"github.com/ethersphere/bee/pkg/postage"
+	mockbatchstore "github.com/ethersphere/bee/pkg/postage/batchstore/mock"
 	mockpost "github.com/ethersphere/bee/pkg/postage/mock"
 	"github.com/ethersphere/bee/pkg/postage/postagecontract"
 	contractMock "github.com/ethersphere/bee/pkg/postage/postagecontract/mock"
@@ -201,15 +204,18 @@ func TestPostageGetStamps(t *testing.T) {
 		jsonhttptest.WithExpectedJSONResponse(&api.PostageStampsResponse{
 			Stamps: []api.PostageStampResponse{
 				{
-					BatchID:       batchOk,
-					Utilization:   si.Utilization(),
-					Usable:        true,
-					Label:         si.Label(),
-					Depth:         si.Depth(),
-					Amount:        bigint.Wrap(si.Amount()),
-					BucketDepth:   si.BucketDepth(),
-					BlockNumber:   si.BlockNumber(),
-					ImmutableFlag: si.ImmutableFlag(),
+					BatchID:          batchOk,
+					Utilization:      si.Utilization(),
+					Usable:           true,
+					Label:            si.Label(),
+					Depth:            si.Depth(),
+					Amount:           bigint.Wrap(si.Amount()),
+					BucketDepth:      si.BucketDepth(),
+					BlockNumber:      si.BlockNumber(),
+					ImmutableFlag:    si.ImmutableFlag(),
+					BatchTTL:         -1,
+					BucketUpperBound: si.BucketUpperBound(),
+					BucketHistogram:  si.BucketHistogram(),
 				},
 			},
 		}),
@@ -219,20 +225,28 @@ func TestPostageGetStamps(t *testing.T) {
 func TestPostageGetStamp(t *testing.T) {
 	si := postage.NewStampIssuer("", "", batchOk, big.NewInt(3), 11, 10, 1000, true)
 	mp := mockpost.New(mockpost.WithIssuer(si))
-	client, _, _ := newTestServer(t, testServerOptions{Post: mp})
+	bs := mockbatchstore.New(
+		mockbatchstore.WithBatch(&postage.Batch{ID: batchOk, Value: big.NewInt(1000)}),
+		mockbatchstore.WithChainState(&postage.ChainState{Block: 100, TotalAmount: big.NewInt(100), CurrentPrice: big.NewInt(3)}),
+	)
+	client, _, _ := newTestServer(t, testServerOptions{Post: mp, BatchStore: bs, BlockTime: 5 * time.Second})
 
 	t.Run("ok", func(t *testing.T) {
 		jsonhttptest.Request(t, client, http.MethodGet, "/stamps/"+batchOkStr, http.StatusOK,
 			jsonhttptest.WithExpectedJSONResponse(&api.PostageStampResponse{
-				BatchID:       batchOk,
-				Utilization:   si.Utilization(),
-				Usable:        true,
-				Label:         si.Label(),
-				Depth:         si.Depth(),
-				Amount:        bigint.Wrap(si.Amount()),
-				BucketDepth:   si.BucketDepth(),
-				BlockNumber:   si.BlockNumber(),
-				ImmutableFlag: si.ImmutableFlag(),
+				BatchID:          batchOk,
+				Utilization:      si.Utilization(),
+				Usable:           true,
+				Label:            si.Label(),
+				Depth:            si.Depth(),
+				Amount:           bigint.Wrap(si.Amount()),
+				BucketDepth:      si.BucketDepth(),
+				BlockNumber:      si.BlockNumber(),
+				ImmutableFlag:    si.ImmutableFlag(),
+				BatchTTL:         1500, // 300 blocks with a block time of 5 seconds
+				ExpiryBlock:      400,
+				BucketUpperBound: si.BucketUpperBound(),
+				BucketHistogram:  si.BucketHistogram(),
 			}),
 		)
 	})
@@ -257,3 +271,207 @@ func TestPostageGetStamp(t *testing.T) {
 		)
 	})
 }
+
+func TestPostageTopUpStamp(t *testing.T) {
+	topupAmount := int64(1000)
+	topupBatch := func(id string, amount int64) string {
+		return fmt.Sprintf("/stamps/topup/%s/%d", id, amount)
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		contract := contractMock.New(
+			contractMock.WithTopUpBatchFunc(func(ctx context.Context, id []byte, ib *big.Int) error {
+				if !bytes.Equal(id, batchOk) {
+					return errors.New("incorrect batch ID in call")
+				}
+				if ib.Cmp(big.NewInt(topupAmount)) != 0 {
+					return fmt.Errorf("called with wrong topup amount. wanted %d, got %d", topupAmount, ib)
+				}
+				return nil
+			}),
+		)
+		client, _, _ := newTestServer(t, testServerOptions{
+			PostageContract: contract,
+		})
+
+		jsonhttptest.Request(t, client, http.MethodPatch, topupBatch(batchOkStr, topupAmount), http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(&api.PostageCreateResponse{
+				BatchID: batchOk,
+			}),
+		)
+	})
+
+	t.Run("with-custom-gas", func(t *testing.T) {
+		contract := contractMock.New(
+			contractMock.WithTopUpBatchFunc(func(ctx context.Context, id []byte, ib *big.Int) error {
+				if sctx.GetGasPrice(ctx).Cmp(big.NewInt(10000)) != 0 {
+					return fmt.Errorf("called with wrong gas price. wanted %d, got %d", 10000, sctx.GetGasPrice(ctx))
+				}
+				return nil
+			}),
+		)
+		client, _, _ := newTestServer(t, testServerOptions{
+			PostageContract: contract,
+		})
+
+		jsonhttptest.Request(t, client, http.MethodPatch, topupBatch(batchOkStr, topupAmount), http.StatusOK,
+			jsonhttptest.WithRequestHeader("Gas-Price", "10000"),
+			jsonhttptest.WithExpectedJSONResponse(&api.PostageCreateResponse{
+				BatchID: batchOk,
+			}),
+		)
+	})
+
+	t.Run("with-error", func(t *testing.T) {
+		contract := contractMock.New(
+			contractMock.WithTopUpBatchFunc(func(ctx context.Context, id []byte, ib *big.Int) error {
+				return errors.New("err")
+			}),
+		)
+		client, _, _ := newTestServer(t, testServerOptions{
+			PostageContract: contract,
+		})
+
+		jsonhttptest.Request(t, client, http.MethodPatch, topupBatch(batchOkStr, topupAmount), http.StatusInternalServerError,
+			jsonhttptest.WithExpectedJSONResponse(&jsonhttp.StatusResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "cannot topup batch",
+			}),
+		)
+	})
+
+	t.Run("out-of-funds", func(t *testing.T) {
+		contract := contractMock.New(
+			contractMock.WithTopUpBatchFunc(func(ctx context.Context, id []byte, ib *big.Int) error {
+				return postagecontract.ErrInsufficientFunds
+			}),
+		)
+		client, _, _ := newTestServer(t, testServerOptions{
+			PostageContract: contract,
+		})
+
+		jsonhttptest.Request(t, client, http.MethodPatch, topupBatch(batchOkStr, topupAmount), http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(&jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "out of funds",
+			}),
+		)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		contract := contractMock.New(
+			contractMock.WithTopUpBatchFunc(func(ctx context.Context, id []byte, ib *big.Int) error {
+				return postagecontract.ErrNotFound
+			}),
+		)
+		client, _, _ := newTestServer(t, testServerOptions{
+			PostageContract: contract,
+		})
+
+		jsonhttptest.Request(t, client, http.MethodPatch, topupBatch(batchOkStr, topupAmount), http.StatusNotFound,
+			jsonhttptest.WithExpectedJSONResponse(&jsonhttp.StatusResponse{
+				Code:    http.StatusNotFound,
+				Message: "batch not found",
+			}),
+		)
+	})
+
+	t.Run("invalid batch id", func(t *testing.T) {
+		client, _, _ := newTestServer(t, testServerOptions{})
+
+		jsonhttptest.Request(t, client, http.MethodPatch, "/stamps/topup/abcd/2", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(&jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid batchID",
+			}),
+		)
+	})
+
+	t.Run("invalid amount", func(t *testing.T) {
+		client, _, _ := newTestServer(t, testServerOptions{})
+
+		jsonhttptest.Request(t, client, http.MethodPatch, "/stamps/topup/"+batchOkStr+"/abcd", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(&jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid postage amount",
+			}),
+		)
+	})
+}
+
+func TestPostageDiluteStamp(t *testing.T) {
+	newBatchDepth := uint8(17)
+	diluteBatch := func(id string, depth uint8) string {
+		return fmt.Sprintf("/stamps/dilute/%s/%d", id, depth)
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		contract := contractMock.New(
+			contractMock.WithDiluteBatchFunc(func(ctx context.Context, id []byte, newDepth uint8) error {
+				if !bytes.Equal(id, batchOk) {
+					return errors.New("incorrect batch ID in call")
+				}
+				if newDepth != newBatchDepth {
+					return fmt.Errorf("called with wrong depth. wanted %d, got %d", newBatchDepth, newDepth)
+				}
+				return nil
+			}),
+		)
+		client, _, _ := newTestServer(t, testServerOptions{
+			PostageContract: contract,
+		})
+
+		jsonhttptest.Request(t, client, http.MethodPatch, diluteBatch(batchOkStr, newBatchDepth), http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(&api.PostageCreateResponse{
+				BatchID: batchOk,
+			}),
+		)
+	})
+
+	t.Run("with-error", func(t *testing.T) {
+		contract := contractMock.New(
+			contractMock.WithDiluteBatchFunc(func(ctx context.Context, id []byte, newDepth uint8) error {
+				return errors.New("err")
+			}),
+		)
+		client, _, _ := newTestServer(t, testServerOptions{
+			PostageContract: contract,
+		})
+
+		jsonhttptest.Request(t, client, http.MethodPatch, diluteBatch(batchOkStr, newBatchDepth), http.StatusInternalServerError,
+			jsonhttptest.WithExpectedJSONResponse(&jsonhttp.StatusResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "cannot dilute batch",
+			}),
+		)
+	})
+
+	t.Run("depth not increased", func(t *testing.T) {
+		contract := contractMock.New(
+			contractMock.WithDiluteBatchFunc(func(ctx context.Context, id []byte, newDepth uint8) error {
+				return postagecontract.ErrInvalidDepth
+			}),
+		)
+		client, _, _ := newTestServer(t, testServerOptions{
+			PostageContract: contract,
+		})
+
+		jsonhttptest.Request(t, client, http.MethodPatch, diluteBatch(batchOkStr, newBatchDepth), http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(&jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid depth",
+			}),
+		)
+	})
+
+	t.Run("invalid depth", func(t *testing.T) {
+		client, _, _ := newTestServer(t, testServerOptions{})
+
+		jsonhttptest.Request(t, client, http.MethodPatch, "/stamps/dilute/"+batchOkStr+"/ab", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(&jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid depth",
+			}),
+		)
+	})
+}
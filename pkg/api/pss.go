@@ -7,11 +7,16 @@ package api
 import (
 	"context"
 	"crypto/ecdsa"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethersphere/bee/pkg/crypto"
@@ -26,14 +31,36 @@ import (
 var (
 	writeDeadline   = 4 * time.Second // write deadline. should be smaller than the shutdown timeout on api close
 	targetMaxLength = 2               // max target length in bytes, in order to prevent grieving by excess computation
+
+	pssRequestDefaultTimeout = 30 * time.Second // time waited for the reply of a request if not specified otherwise
+	pssRequestMaxTimeout     = 5 * time.Minute  // max time waited for the reply of a request
+	pssRespondTimeout        = 30 * time.Second // time waited for a websocket responder to reply to a request
+
+	pssPollDefaultTimeout = 30 * time.Second // time waited for messages by a long-poll request if not specified otherwise
+	pssPollMaxTimeout     = 5 * time.Minute  // max time waited for messages by a long-poll request
+	pssBufferSize         = 64               // number of the recent messages buffered per topic for the subscribers
+	pssBufferRetention    = time.Minute      // time the messages are buffered for after the last subscriber is gone
+
+	errPssNoReply       = errors.New("no reply")
+	errPssResponderGone = errors.New("responder gone")
+	errPssReplyTimeout  = errors.New("reply timeout")
 )
 
-func (s *server) pssPostHandler(w http.ResponseWriter, r *http.Request) {
-	topicVar := mux.Vars(r)["topic"]
-	topic := pss.NewTopic(topicVar)
+// pssMessage holds the arguments of a pss message sent through the API.
+type pssMessage struct {
+	topic     pss.Topic
+	targets   pss.Targets
+	recipient *ecdsa.PublicKey
+	payload   []byte
+	stamper   postage.Stamper
+}
+
+// parsePssMessage reads the pss message from the request. The response is
+// written and false is returned if the request is not valid.
+func (s *server) parsePssMessage(w http.ResponseWriter, r *http.Request) (*pssMessage, bool) {
+	m := &pssMessage{topic: pss.NewTopic(mux.Vars(r)["topic"])}
 
 	targetsVar := mux.Vars(r)["targets"]
-	var targets pss.Targets
 	tgts := strings.Split(targetsVar, ",")
 
 	for _, v := range tgts {
@@ -42,31 +69,30 @@ func (s *server) pssPostHandler(w http.ResponseWriter, r *http.Request) {
 			s.logger.Debugf("pss send: bad target (%s): %v", target, err)
 			s.logger.Errorf("pss send: bad target (%s): %v", target, err)
 			jsonhttp.BadRequest(w, nil)
-			return
+			return nil, false
 		}
 		if len(target) > targetMaxLength {
 			s.logger.Debugf("pss send: bad target length: %d", len(target))
 			s.logger.Errorf("pss send: bad target length: %d", len(target))
 			jsonhttp.BadRequest(w, nil)
-			return
+			return nil, false
 		}
-		targets = append(targets, target)
+		m.targets = append(m.targets, target)
 	}
 
 	recipientQueryString := r.URL.Query().Get("recipient")
-	var recipient *ecdsa.PublicKey
 	if recipientQueryString == "" {
 		// use topic-based encryption
-		privkey := crypto.Secp256k1PrivateKeyFromBytes(topic[:])
-		recipient = &privkey.PublicKey
+		privkey := crypto.Secp256k1PrivateKeyFromBytes(m.topic[:])
+		m.recipient = &privkey.PublicKey
 	} else {
 		var err error
-		recipient, err = pss.ParseRecipient(recipientQueryString)
+		m.recipient, err = pss.ParseRecipient(recipientQueryString)
 		if err != nil {
 			s.logger.Debugf("pss recipient: %v", err)
 			s.logger.Error("pss recipient")
 			jsonhttp.BadRequest(w, nil)
-			return
+			return nil, false
 		}
 	}
 
@@ -75,14 +101,28 @@ func (s *server) pssPostHandler(w http.ResponseWriter, r *http.Request) {
 		s.logger.Debugf("pss read payload: %v", err)
 		s.logger.Error("pss read payload")
 		jsonhttp.InternalServerError(w, nil)
-		return
+		return nil, false
 	}
+	m.payload = payload
+
+	stamper, ok := s.pssStamper(w, r)
+	if !ok {
+		return nil, false
+	}
+	m.stamper = stamper
+
+	return m, true
+}
+
+// pssStamper returns the stamper of the postage batch of the request. The
+// response is written and false is returned if the batch is not usable.
+func (s *server) pssStamper(w http.ResponseWriter, r *http.Request) (postage.Stamper, bool) {
 	batch, err := requestPostageBatchId(r)
 	if err != nil {
 		s.logger.Debugf("pss: postage batch id: %v", err)
 		s.logger.Error("pss: postage batch id")
 		jsonhttp.BadRequest(w, "invalid postage batch id")
-		return
+		return nil, false
 	}
 	i, err := s.post.GetStampIssuer(batch)
 	if err != nil {
@@ -96,13 +136,34 @@ func (s *server) pssPostHandler(w http.ResponseWriter, r *http.Request) {
 		default:
 			jsonhttp.BadRequest(w, "postage stamp issuer")
 		}
+		return nil, false
+	}
+	return postage.NewStamper(i, s.signer), true
+}
+
+func (s *server) pssPostHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if v := r.URL.Query().Get("mailbox"); v != "" {
+		mailbox, err := strconv.ParseBool(v)
+		if err != nil {
+			s.logger.Debugf("pss send: bad mailbox %q: %v", v, err)
+			s.logger.Error("pss send: bad mailbox")
+			jsonhttp.BadRequest(w, "bad mailbox")
+			return
+		}
+		if mailbox {
+			ctx = pss.WithMailbox(ctx)
+		}
+	}
+
+	m, ok := s.parsePssMessage(w, r)
+	if !ok {
 		return
 	}
-	stamper := postage.NewStamper(i, s.signer)
 
-	err = s.pss.Send(r.Context(), topic, payload, stamper, recipient, targets)
+	err := s.pss.Send(ctx, m.topic, m.payload, m.stamper, m.recipient, m.targets)
 	if err != nil {
-		s.logger.Debugf("pss send payload: %v. topic: %s", err, topicVar)
+		s.logger.Debugf("pss send payload: %v. topic: %s", err, mux.Vars(r)["topic"])
 		s.logger.Error("pss send payload")
 		switch {
 		case errors.Is(err, postage.ErrBucketFull):
@@ -116,6 +177,404 @@ func (s *server) pssPostHandler(w http.ResponseWriter, r *http.Request) {
 	jsonhttp.Created(w, nil)
 }
 
+// pssRequestHandler sends a pss request and responds with the reply of the
+// recipient. The request fails if no reply arrives within the timeout.
+func (s *server) pssRequestHandler(w http.ResponseWriter, r *http.Request) {
+	timeout := pssRequestDefaultTimeout
+	if v := r.URL.Query().Get("timeout"); v != "" {
+		secs, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || secs == 0 || time.Duration(secs)*time.Second > pssRequestMaxTimeout {
+			s.logger.Debugf("pss request: bad timeout %q: %v", v, err)
+			s.logger.Error("pss request: bad timeout")
+			jsonhttp.BadRequest(w, "bad timeout")
+			return
+		}
+		timeout = time.Duration(secs) * time.Second
+	}
+
+	m, ok := s.parsePssMessage(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	reply, err := s.pss.Request(ctx, m.topic, m.payload, m.stamper, m.recipient, m.targets)
+	if err != nil {
+		s.logger.Debugf("pss request: %v. topic: %s", err, mux.Vars(r)["topic"])
+		s.logger.Error("pss request")
+		switch {
+		case errors.Is(err, postage.ErrBucketFull):
+			jsonhttp.PaymentRequired(w, "batch is overissued")
+		case errors.Is(err, context.DeadlineExceeded):
+			jsonhttp.GatewayTimeout(w, "no reply")
+		default:
+			jsonhttp.InternalServerError(w, nil)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.Itoa(len(reply)))
+	_, _ = w.Write(reply)
+}
+
+// pssRespondHandler upgrades the connection to a websocket which answers
+// the pss requests with the topic. Every request payload is written to the
+// websocket as a binary message and the next message read from it is sent
+// back to the sender as the reply, no reply is sent if it is empty. The
+// replies are stamped with the postage batch of the request. A responder
+// that does not reply in time is disconnected.
+func (s *server) pssRespondHandler(w http.ResponseWriter, r *http.Request) {
+	stamper, ok := s.pssStamper(w, r)
+	if !ok {
+		return
+	}
+
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  swarm.ChunkSize,
+		WriteBufferSize: swarm.ChunkSize,
+		CheckOrigin:     s.checkOrigin,
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.logger.Debugf("pss respond: upgrade: %v", err)
+		s.logger.Error("pss respond: cannot upgrade")
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	t := mux.Vars(r)["topic"]
+	s.wsWg.Add(1)
+	go s.pumpRespondWs(conn, pss.NewTopic(t), stamper)
+}
+
+func (s *server) pumpRespondWs(conn *websocket.Conn, topic pss.Topic, stamper postage.Stamper) {
+	defer s.wsWg.Done()
+
+	var (
+		requestC = make(chan []byte)
+		replyC   = make(chan []byte)
+		gone     = make(chan struct{}) // closed when the websocket can not be read
+		done     = make(chan struct{}) // closed when the pump returns
+		mu       sync.Mutex            // one request is answered at a time
+		err      error
+	)
+	defer func() {
+		close(done)
+		_ = conn.Close()
+	}()
+
+	cleanup := s.pss.RegisterRequestHandler(topic, stamper, func(ctx context.Context, m []byte) ([]byte, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		select {
+		case requestC <- m:
+		case <-gone:
+			return nil, errPssResponderGone
+		case <-done:
+			return nil, errPssResponderGone
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		timer := time.NewTimer(pssRespondTimeout)
+		defer timer.Stop()
+
+		select {
+		case reply := <-replyC:
+			if len(reply) == 0 {
+				return nil, errPssNoReply
+			}
+			return reply, nil
+		case <-timer.C:
+			// a late reply would be taken for the reply of the next request
+			_ = conn.Close()
+			return nil, errPssReplyTimeout
+		case <-gone:
+			return nil, errPssResponderGone
+		case <-ctx.Done():
+			_ = conn.Close()
+			return nil, ctx.Err()
+		}
+	})
+	defer cleanup()
+
+	// the handler is registered once the websocket is read
+	go func() {
+		defer close(gone)
+		for {
+			_, b, err := conn.ReadMessage()
+			if err != nil {
+				s.logger.Debugf("pss respond: read from websocket: %v", err)
+				return
+			}
+			select {
+			case replyC <- b:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var pingC <-chan time.Time
+	if s.WsPingPeriod > 0 {
+		ticker := time.NewTicker(s.WsPingPeriod)
+		defer ticker.Stop()
+		pingC = ticker.C
+	}
+
+	for {
+		select {
+		case m := <-requestC:
+			err = conn.SetWriteDeadline(time.Now().Add(writeDeadline))
+			if err != nil {
+				s.logger.Debugf("pss respond: set write deadline: %v", err)
+				return
+			}
+			err = conn.WriteMessage(websocket.BinaryMessage, m)
+			if err != nil {
+				s.logger.Debugf("pss respond: write to websocket: %v", err)
+				return
+			}
+		case <-s.quit:
+			// shutdown
+			err = conn.SetWriteDeadline(time.Now().Add(writeDeadline))
+			if err != nil {
+				s.logger.Debugf("pss respond: set write deadline: %v", err)
+				return
+			}
+			err = conn.WriteMessage(websocket.CloseMessage, []byte{})
+			if err != nil {
+				s.logger.Debugf("pss respond: write close message: %v", err)
+			}
+			return
+		case <-gone:
+			// client gone
+			return
+		case <-pingC:
+			err = conn.SetWriteDeadline(time.Now().Add(writeDeadline))
+			if err != nil {
+				s.logger.Debugf("pss respond: set write deadline: %v", err)
+				return
+			}
+			if err = conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				// error encountered while pinging client. client probably gone
+				return
+			}
+		}
+	}
+}
+
+type pssMailboxMessage struct {
+	Received int64  `json:"received"`
+	Payload  []byte `json:"payload"`
+}
+
+type pssMailboxResponse struct {
+	Messages []pssMailboxMessage `json:"messages"`
+}
+
+// pssMailboxHandler returns the messages with the topic that could not
+// be delivered when received, kept in the mailbox of the node and the
+// ones of its neighbourhood peers.
+func (s *server) pssMailboxHandler(w http.ResponseWriter, r *http.Request) {
+	topic := pss.NewTopic(mux.Vars(r)["topic"])
+
+	var since int64
+	if v := r.URL.Query().Get("since"); v != "" {
+		var err error
+		since, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			s.logger.Debugf("pss mailbox: parse since %q: %v", v, err)
+			s.logger.Error("pss mailbox: bad since")
+			jsonhttp.BadRequest(w, "bad since")
+			return
+		}
+	}
+
+	msgs, err := s.pss.MailboxMessages(r.Context(), topic, time.Unix(since, 0))
+	if err != nil {
+		s.logger.Debugf("pss mailbox: %v", err)
+		s.logger.Error("pss mailbox")
+		if errors.Is(err, pss.ErrMailboxDisabled) {
+			jsonhttp.NotFound(w, "mailbox disabled")
+			return
+		}
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	resp := pssMailboxResponse{Messages: make([]pssMailboxMessage, 0, len(msgs))}
+	for _, m := range msgs {
+		resp.Messages = append(resp.Messages, pssMailboxMessage{
+			Received: m.Received.Unix(),
+			Payload:  m.Payload,
+		})
+	}
+	jsonhttp.OK(w, resp)
+}
+
+// pssSubscribeHandler delivers the messages with the topic over a websocket,
+// as server-sent events if the client accepts an event stream, or otherwise
+// as a long-poll JSON response.
+func (s *server) pssSubscribeHandler(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case websocket.IsWebSocketUpgrade(r):
+		s.pssWsHandler(w, r)
+	case strings.Contains(r.Header.Get("Accept"), "text/event-stream"):
+		s.pssSseHandler(w, r)
+	default:
+		s.pssPollHandler(w, r)
+	}
+}
+
+// pssCursorParam returns the cursor after which the messages are delivered to
+// a subscriber, the one given with the since query parameter or the
+// Last-Event-ID header, or otherwise the one of the last buffered message.
+// The response is written and false is returned if the cursor is not valid.
+func (s *server) pssCursorParam(w http.ResponseWriter, r *http.Request) (uint64, bool) {
+	v := r.URL.Query().Get("since")
+	if v == "" {
+		v = r.Header.Get("Last-Event-ID")
+	}
+	if v == "" {
+		return s.pssLastCursor(), true
+	}
+	cursor, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		s.logger.Debugf("pss subscribe: parse since %q: %v", v, err)
+		s.logger.Error("pss subscribe: bad since")
+		jsonhttp.BadRequest(w, "bad since")
+		return 0, false
+	}
+	return cursor, true
+}
+
+// pssSseHandler streams the messages as server-sent events. The event ids
+// are the cursors of the messages, the messages after the one with the
+// Last-Event-ID are delivered to a reconnecting subscriber if they are
+// still buffered.
+func (s *server) pssSseHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.logger.Error("pss sse: streaming unsupported")
+		jsonhttp.InternalServerError(w, "streaming unsupported")
+		return
+	}
+	cursor, ok := s.pssCursorParam(w, r)
+	if !ok {
+		return
+	}
+
+	b, release := s.subscribePssBuffer(pss.NewTopic(mux.Vars(r)["topic"]))
+	defer release()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	var pingC <-chan time.Time
+	if s.WsPingPeriod > 0 {
+		ticker := time.NewTicker(s.WsPingPeriod)
+		defer ticker.Stop()
+		pingC = ticker.C
+	}
+
+	for {
+		msgs, notify := b.since(cursor)
+		for _, m := range msgs {
+			if _, err := fmt.Fprintf(w, "id: %d\ndata: %s\n\n", m.cursor, base64.StdEncoding.EncodeToString(m.payload)); err != nil {
+				s.logger.Debugf("pss sse: write event: %v", err)
+				return
+			}
+			cursor = m.cursor
+		}
+		if len(msgs) > 0 {
+			flusher.Flush()
+		}
+
+		select {
+		case <-notify:
+		case <-pingC:
+			// keep the connection alive with a comment line
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				s.logger.Debugf("pss sse: write ping: %v", err)
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			// client gone
+			return
+		case <-s.quit:
+			// shutdown
+			return
+		}
+	}
+}
+
+type pssMessagesResponse struct {
+	Messages [][]byte `json:"messages"`
+	Cursor   uint64   `json:"cursor"`
+}
+
+// pssPollHandler waits for messages with the topic after the cursor and
+// responds with the ones buffered until the first message arrives or the
+// timeout expires. The cursor of the response is to be passed as the since
+// query parameter of the next request, so that the messages received in
+// between are delivered if they are still buffered.
+func (s *server) pssPollHandler(w http.ResponseWriter, r *http.Request) {
+	timeout := pssPollDefaultTimeout
+	if v := r.URL.Query().Get("timeout"); v != "" {
+		secs, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || secs == 0 || time.Duration(secs)*time.Second > pssPollMaxTimeout {
+			s.logger.Debugf("pss poll: bad timeout %q: %v", v, err)
+			s.logger.Error("pss poll: bad timeout")
+			jsonhttp.BadRequest(w, "bad timeout")
+			return
+		}
+		timeout = time.Duration(secs) * time.Second
+	}
+	cursor, ok := s.pssCursorParam(w, r)
+	if !ok {
+		return
+	}
+
+	b, release := s.subscribePssBuffer(pss.NewTopic(mux.Vars(r)["topic"]))
+	defer release()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var msgs []pssBufferedMessage
+	for wait := true; wait; {
+		var notify <-chan struct{}
+		if msgs, notify = b.since(cursor); len(msgs) > 0 {
+			break
+		}
+		select {
+		case <-notify:
+		case <-timer.C:
+			wait = false
+		case <-r.Context().Done():
+			return
+		case <-s.quit:
+			wait = false
+		}
+	}
+
+	resp := pssMessagesResponse{Messages: make([][]byte, 0, len(msgs)), Cursor: cursor}
+	for _, m := range msgs {
+		resp.Messages = append(resp.Messages, m.payload)
+		resp.Cursor = m.cursor
+	}
+	jsonhttp.OK(w, resp)
+}
+
 func (s *server) pssWsHandler(w http.ResponseWriter, r *http.Request) {
 
 	upgrader := websocket.Upgrader{
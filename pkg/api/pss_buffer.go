@@ -0,0 +1,118 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/pss"
+)
+
+// pssBuffer keeps the recent messages with a topic for the long-poll and the
+// server-sent event subscribers, so that the messages received between two
+// requests of a subscriber are not lost. The messages are identified by
+// cursors increasing across all the topics.
+type pssBuffer struct {
+	mu       sync.Mutex
+	messages []pssBufferedMessage // oldest first
+	notify   chan struct{}        // closed when a message is added
+	cleanup  func()               // unregisters the pss handler
+
+	// guarded by the pssBuffersMu of the server
+	subscribers int
+	expiry      *time.Timer
+}
+
+type pssBufferedMessage struct {
+	cursor  uint64
+	payload []byte
+}
+
+// add appends the message with the next cursor, the oldest message is
+// dropped if the buffer is full.
+func (b *pssBuffer) add(next func() uint64, payload []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.messages = append(b.messages, pssBufferedMessage{cursor: next(), payload: payload})
+	if len(b.messages) > pssBufferSize {
+		b.messages = b.messages[1:]
+	}
+	close(b.notify)
+	b.notify = make(chan struct{})
+}
+
+// since returns the messages after the cursor and the channel closed when
+// the next message is added.
+func (b *pssBuffer) since(cursor uint64) ([]pssBufferedMessage, <-chan struct{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, m := range b.messages {
+		if m.cursor > cursor {
+			return append([]pssBufferedMessage(nil), b.messages[i:]...), b.notify
+		}
+	}
+	return nil, b.notify
+}
+
+// pssLastCursor returns the cursor of the last buffered message, the
+// messages received afterwards have greater cursors.
+func (s *server) pssLastCursor() uint64 {
+	return atomic.LoadUint64(&s.pssCursor)
+}
+
+// subscribePssBuffer returns the buffer of the messages with the topic. The
+// buffer is kept for pssBufferRetention after the returned release function
+// is called, unless it is subscribed to again in the meantime.
+func (s *server) subscribePssBuffer(topic pss.Topic) (*pssBuffer, func()) {
+	s.pssBuffersMu.Lock()
+	defer s.pssBuffersMu.Unlock()
+
+	if s.pssBuffers == nil {
+		s.pssBuffers = make(map[pss.Topic]*pssBuffer)
+	}
+	b, ok := s.pssBuffers[topic]
+	if !ok {
+		b = &pssBuffer{notify: make(chan struct{})}
+		next := func() uint64 {
+			return atomic.AddUint64(&s.pssCursor, 1)
+		}
+		b.cleanup = s.pss.Register(topic, func(_ context.Context, m []byte) {
+			b.add(next, m)
+		})
+		s.pssBuffers[topic] = b
+	}
+	if b.expiry != nil {
+		b.expiry.Stop()
+		b.expiry = nil
+	}
+	b.subscribers++
+
+	return b, func() {
+		s.pssBuffersMu.Lock()
+		defer s.pssBuffersMu.Unlock()
+
+		b.subscribers--
+		if b.subscribers > 0 {
+			return
+		}
+		var expiry *time.Timer
+		expiry = time.AfterFunc(pssBufferRetention, func() {
+			s.pssBuffersMu.Lock()
+			defer s.pssBuffersMu.Unlock()
+
+			if b.expiry != expiry {
+				return // subscribed to again
+			}
+			delete(s.pssBuffers, topic)
+			b.cleanup()
+		})
+		b.expiry = expiry
+	}
+}
@@ -5,15 +5,19 @@
 package api_test
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"crypto/ecdsa"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"math/big"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -28,6 +32,8 @@ import (
 	mockpost "github.com/ethersphere/bee/pkg/postage/mock"
 	"github.com/ethersphere/bee/pkg/pss"
 	"github.com/ethersphere/bee/pkg/pushsync"
+	pushsyncmock "github.com/ethersphere/bee/pkg/pushsync/mock"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
 	"github.com/ethersphere/bee/pkg/storage/mock"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/gorilla/websocket"
@@ -228,6 +234,17 @@ func TestPssSend(t *testing.T) {
 		)
 	})
 
+	t.Run("err - bad mailbox", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, "/pss/send/to/12?mailbox=maybe", http.StatusBadRequest,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithRequestBody(bytes.NewReader(payload)),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "bad mailbox",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
 	t.Run("ok batch", func(t *testing.T) {
 		hexbatch := hex.EncodeToString(batchOk)
 		jsonhttptest.Request(t, client, http.MethodPost, "/pss/send/to/12", http.StatusCreated,
@@ -387,6 +404,418 @@ func waitMessage(t *testing.T, data, expData []byte, mtx *sync.Mutex) {
 	}
 }
 
+// TestPssRequest sends a request through the API of one node to the
+// websocket responder connected to the API of another node and expects
+// its reply in the response.
+func TestPssRequest(t *testing.T) {
+	senderKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipientKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		logger    = logging.New(ioutil.Discard, 0)
+		sender    = pss.New(senderKey, swarm.MustParseHexAddress("abcd"+strings.Repeat("00", 30)), nil, logger)
+		recipient = pss.New(recipientKey, swarm.ZeroAddress, nil, logger)
+		resource  = func(query string) string {
+			return "/pss/request/testtopic/01?recipient=" +
+				hex.EncodeToString((*btcec.PublicKey)(&recipientKey.PublicKey).SerializeCompressed()) + query
+		}
+		client, _, _ = newTestServer(t, testServerOptions{
+			Pss:    sender,
+			Storer: mock.NewStorer(),
+			Logger: logger,
+			Post:   mockpost.New(mockpost.WithAcceptAll()),
+		})
+		_, _, listener = newTestServer(t, testServerOptions{
+			Pss:    recipient,
+			Storer: mock.NewStorer(),
+			Logger: logger,
+			Post:   mockpost.New(mockpost.WithAcceptAll()),
+		})
+	)
+
+	sender.SetPushSyncer(pushsyncmock.New(func(_ context.Context, ch swarm.Chunk) (*pushsync.Receipt, error) {
+		go recipient.TryUnwrap(ch)
+		return nil, nil
+	}))
+	recipient.SetPushSyncer(pushsyncmock.New(func(_ context.Context, ch swarm.Chunk) (*pushsync.Receipt, error) {
+		go sender.TryUnwrap(ch)
+		return nil, nil
+	}))
+
+	u := url.URL{Scheme: "ws", Host: listener, Path: "/pss/respond/testtopic"}
+	conn, _, err := websocket.DefaultDialer.Dial(u.String(), http.Header{
+		api.SwarmPostageBatchIdHeader: []string{batchOkStr},
+	})
+	if err != nil {
+		t.Fatalf("dial: %v. url %v", err, u.String())
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	// the responder is registered once the pong of a ping arrives
+	ready := make(chan struct{})
+	conn.SetPongHandler(func(string) error {
+		close(ready)
+		return nil
+	})
+	go func() {
+		for {
+			_, m, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			reply := bytes.ToUpper(m)
+			if string(m) == "silence" {
+				reply = nil
+			}
+			if err := conn.WriteMessage(websocket.BinaryMessage, reply); err != nil {
+				return
+			}
+		}
+	}()
+	if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-ready:
+	case <-time.After(mTimeout):
+		t.Fatal("responder not registered")
+	}
+
+	t.Run("reply", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, resource(""), http.StatusOK,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithRequestBody(bytes.NewReader(payload)),
+			jsonhttptest.WithExpectedResponse(bytes.ToUpper(payload)),
+		)
+	})
+
+	t.Run("no reply", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, resource("&timeout=1"), http.StatusGatewayTimeout,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithRequestBody(bytes.NewReader([]byte("silence"))),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "no reply",
+				Code:    http.StatusGatewayTimeout,
+			}),
+		)
+	})
+
+	t.Run("bad timeout", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodPost, resource("&timeout=0"), http.StatusBadRequest,
+			jsonhttptest.WithRequestHeader(api.SwarmPostageBatchIdHeader, batchOkStr),
+			jsonhttptest.WithRequestBody(bytes.NewReader(payload)),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "bad timeout",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("responder without batch", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodGet, "/pss/respond/testtopic", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "invalid postage batch id",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+}
+
+func TestPssMailbox(t *testing.T) {
+	privkey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mailbox, err := pss.NewMailbox(statestore.NewStateStore(), pss.DefaultMailboxCapacity, pss.DefaultMailboxTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		logger       = logging.New(ioutil.Discard, 0)
+		overlay      = swarm.MustParseHexAddress("0100000000000000000000000000000000000000000000000000000000000000")
+		p            = pss.New(privkey, overlay, mailbox, logger)
+		client, _, _ = newTestServer(t, testServerOptions{
+			Pss:    p,
+			Storer: mock.NewStorer(),
+			Logger: logger,
+		})
+	)
+
+	tc, err := pss.Wrap(context.Background(), topic, payload, &privkey.PublicKey, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// there is no subscription to the topic, the message is kept in the mailbox
+	p.TryUnwrapOrKeep(tc)
+
+	t.Run("messages", func(t *testing.T) {
+		var resp api.PssMailboxResponse
+		jsonhttptest.Request(t, client, http.MethodGet, "/pss/mailbox/testtopic", http.StatusOK,
+			jsonhttptest.WithUnmarshalJSONResponse(&resp),
+		)
+		if len(resp.Messages) != 1 || !bytes.Equal(resp.Messages[0].Payload, payload) {
+			t.Fatalf("got messages %v, want payload %q", resp.Messages, payload)
+		}
+	})
+
+	t.Run("since", func(t *testing.T) {
+		since := time.Now().Add(time.Minute).Unix()
+		jsonhttptest.Request(t, client, http.MethodGet, fmt.Sprintf("/pss/mailbox/testtopic?since=%d", since), http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.PssMailboxResponse{
+				Messages: []api.PssMailboxMessage{},
+			}),
+		)
+	})
+
+	t.Run("other topic", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodGet, "/pss/mailbox/othertopic", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.PssMailboxResponse{
+				Messages: []api.PssMailboxMessage{},
+			}),
+		)
+	})
+
+	t.Run("bad since", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodGet, "/pss/mailbox/testtopic?since=yesterday", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "bad since",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		client, _, _ := newTestServer(t, testServerOptions{
+			Pss:    pss.New(privkey, swarm.ZeroAddress, nil, logger),
+			Storer: mock.NewStorer(),
+			Logger: logger,
+		})
+		jsonhttptest.Request(t, client, http.MethodGet, "/pss/mailbox/testtopic", http.StatusNotFound,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "mailbox disabled",
+				Code:    http.StatusNotFound,
+			}),
+		)
+	})
+}
+
+func TestPssSubscribeEvents(t *testing.T) {
+	privkey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		logger       = logging.New(ioutil.Discard, 0)
+		p            = pss.New(privkey, swarm.ZeroAddress, nil, logger)
+		client, _, _ = newTestServer(t, testServerOptions{
+			Pss:    p,
+			Storer: mock.NewStorer(),
+			Logger: logger,
+		})
+	)
+
+	subscribe := func(t *testing.T, lastEventID string) (*bufio.Reader, func()) {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), longTimeout)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/pss/subscribe/testtopic", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept", "text/event-stream")
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("got content type %q, want %q", ct, "text/event-stream")
+		}
+		return bufio.NewReader(resp.Body), func() {
+			resp.Body.Close()
+			cancel()
+		}
+	}
+	// readEvent reads the next event and returns its id
+	readEvent := func(t *testing.T, r *bufio.Reader) uint64 {
+		t.Helper()
+		var event []string
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			if line != "" {
+				event = append(event, line)
+				continue
+			}
+			if len(event) != 2 || !strings.HasPrefix(event[0], "id: ") {
+				t.Fatalf("got event %q", event)
+			}
+			id, err := strconv.ParseUint(strings.TrimPrefix(event[0], "id: "), 10, 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "data: " + base64.StdEncoding.EncodeToString(payload); event[1] != want {
+				t.Fatalf("got event data %q, want %q", event[1], want)
+			}
+			return id
+		}
+	}
+	send := func(t *testing.T) {
+		t.Helper()
+		tc, err := pss.Wrap(context.Background(), topic, payload, &privkey.PublicKey, targets)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.TryUnwrap(tc)
+	}
+
+	// the handler is registered once the response headers are sent
+	r, cancel := subscribe(t, "")
+	send(t)
+	first := readEvent(t, r)
+	send(t)
+	if id := readEvent(t, r); id != first+1 {
+		t.Fatalf("got event id %d, want %d", id, first+1)
+	}
+	cancel()
+
+	// the events received while disconnected are delivered on reconnect
+	send(t)
+	r, cancel = subscribe(t, strconv.FormatUint(first+1, 10))
+	defer cancel()
+	if id := readEvent(t, r); id != first+2 {
+		t.Fatalf("got event id %d, want %d", id, first+2)
+	}
+}
+
+func TestPssSubscribePoll(t *testing.T) {
+	privkey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		logger       = logging.New(ioutil.Discard, 0)
+		p            = pss.New(privkey, swarm.ZeroAddress, nil, logger)
+		client, _, _ = newTestServer(t, testServerOptions{
+			Pss:    p,
+			Storer: mock.NewStorer(),
+			Logger: logger,
+		})
+	)
+
+	t.Run("message", func(t *testing.T) {
+		tc, err := pss.Wrap(context.Background(), topic, payload, &privkey.PublicKey, targets)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			// deliver the message until the poll request is answered
+			for {
+				p.TryUnwrap(tc)
+				select {
+				case <-done:
+					return
+				case <-time.After(50 * time.Millisecond):
+				}
+			}
+		}()
+
+		var resp api.PssMessagesResponse
+		jsonhttptest.Request(t, client, http.MethodGet, "/pss/subscribe/testtopic", http.StatusOK,
+			jsonhttptest.WithUnmarshalJSONResponse(&resp),
+		)
+		if len(resp.Messages) == 0 {
+			t.Fatal("got no messages")
+		}
+		for _, m := range resp.Messages {
+			if !bytes.Equal(m, payload) {
+				t.Fatalf("got message %q, want %q", m, payload)
+			}
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		var resp api.PssMessagesResponse
+		jsonhttptest.Request(t, client, http.MethodGet, "/pss/subscribe/othertopic?timeout=1", http.StatusOK,
+			jsonhttptest.WithUnmarshalJSONResponse(&resp),
+		)
+		if len(resp.Messages) != 0 {
+			t.Fatalf("got %d messages, want none", len(resp.Messages))
+		}
+	})
+
+	t.Run("since", func(t *testing.T) {
+		var resp api.PssMessagesResponse
+		jsonhttptest.Request(t, client, http.MethodGet, "/pss/subscribe/sincetopic?timeout=1", http.StatusOK,
+			jsonhttptest.WithUnmarshalJSONResponse(&resp),
+		)
+
+		// the messages received between the polls are buffered
+		sinceTopic := pss.NewTopic("sincetopic")
+		for i := 0; i < 2; i++ {
+			tc, err := pss.Wrap(context.Background(), sinceTopic, payload, &privkey.PublicKey, targets)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.TryUnwrap(tc)
+		}
+
+		var got [][]byte
+		for cursor := resp.Cursor; len(got) < 2; {
+			var resp api.PssMessagesResponse
+			jsonhttptest.Request(t, client, http.MethodGet, fmt.Sprintf("/pss/subscribe/sincetopic?timeout=1&since=%d", cursor), http.StatusOK,
+				jsonhttptest.WithUnmarshalJSONResponse(&resp),
+			)
+			if len(resp.Messages) == 0 {
+				t.Fatalf("got %d messages, want %d", len(got), 2)
+			}
+			got = append(got, resp.Messages...)
+			cursor = resp.Cursor
+		}
+		if len(got) != 2 {
+			t.Fatalf("got %d messages, want %d", len(got), 2)
+		}
+		for _, m := range got {
+			if !bytes.Equal(m, payload) {
+				t.Fatalf("got message %q, want %q", m, payload)
+			}
+		}
+	})
+
+	t.Run("bad since", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodGet, "/pss/subscribe/testtopic?since=latest", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "bad since",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("bad timeout", func(t *testing.T) {
+		jsonhttptest.Request(t, client, http.MethodGet, "/pss/subscribe/testtopic?timeout=forever", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "bad timeout",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+}
+
 type opts struct {
 	pingPeriod time.Duration
 }
@@ -400,7 +829,7 @@ func newPssTest(t *testing.T, o opts) (pss.Interface, *ecdsa.PublicKey, *websock
 	}
 	var (
 		logger = logging.New(ioutil.Discard, 0)
-		pss    = pss.New(privkey, logger)
+		pss    = pss.New(privkey, swarm.ZeroAddress, nil, logger)
 	)
 	if o.pingPeriod == 0 {
 		o.pingPeriod = 10 * time.Second
@@ -438,11 +867,36 @@ func (m *mpss) Register(_ pss.Topic, _ pss.Handler) func() {
 	panic("not implemented") // TODO: Implement
 }
 
+// Request sends a request and waits for the reply.
+func (m *mpss) Request(_ context.Context, _ pss.Topic, _ []byte, _ postage.Stamper, _ *ecdsa.PublicKey, _ pss.Targets) ([]byte, error) {
+	panic("not implemented") // TODO: Implement
+}
+
+// MailboxMessages returns the undelivered messages with the given Topic.
+func (m *mpss) MailboxMessages(_ context.Context, _ pss.Topic, _ time.Time) ([]pss.MailboxMessage, error) {
+	panic("not implemented") // TODO: Implement
+}
+
+// SetMailboxFetcher sets the retrieval of the messages kept in the mailboxes of the neighbourhood peers.
+func (m *mpss) SetMailboxFetcher(_ pss.MailboxFetcher) {
+	panic("not implemented") // TODO: Implement
+}
+
+// RegisterRequestHandler registers a RequestHandler for a given Topic.
+func (m *mpss) RegisterRequestHandler(_ pss.Topic, _ postage.Stamper, _ pss.RequestHandler) func() {
+	panic("not implemented") // TODO: Implement
+}
+
 // TryUnwrap tries to unwrap a wrapped trojan message.
 func (m *mpss) TryUnwrap(_ swarm.Chunk) {
 	panic("not implemented") // TODO: Implement
 }
 
+// TryUnwrapOrKeep tries to unwrap a wrapped trojan message and keeps it in the mailbox if it can not be delivered.
+func (m *mpss) TryUnwrapOrKeep(_ swarm.Chunk) {
+	panic("not implemented") // TODO: Implement
+}
+
 func (m *mpss) SetPushSyncer(pushSyncer pushsync.PushSyncer) {
 	panic("not implemented") // TODO: Implement
 }
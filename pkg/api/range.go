@@ -0,0 +1,219 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"github.com/ethersphere/langos"
+)
+
+var (
+	errInvalidRange = errors.New("invalid range")
+	errNoOverlap    = errors.New("invalid range: failed to overlap")
+)
+
+// httpRange specifies the byte range to be sent to the client.
+type httpRange struct {
+	start, length int64
+}
+
+func (r httpRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.start, r.start+r.length-1, size)
+}
+
+func (r httpRange) mimeHeader(contentType string, size int64) textproto.MIMEHeader {
+	h := textproto.MIMEHeader{
+		"Content-Range": {r.contentRange(size)},
+	}
+	if contentType != "" {
+		h.Set("Content-Type", contentType)
+	}
+	return h
+}
+
+// requestRanges returns the byte ranges requested by the Range header of the
+// request for content of the given size. No ranges are returned if the whole
+// content should be served, which is the case when the Range header is absent
+// or the If-Range validator does not match the etag of the content.
+func requestRanges(r *http.Request, etag string, size int64) ([]httpRange, error) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return nil, nil
+	}
+	rangeHeader := r.Header.Get("Range")
+	if rangeHeader == "" {
+		return nil, nil
+	}
+	if ir := r.Header.Get("If-Range"); ir != "" {
+		// only strong entity tags are accepted as validators as there is no
+		// modification time of the content that a date could be checked against
+		if etag == "" || strings.HasPrefix(ir, "W/") || ir != etag {
+			return nil, nil
+		}
+	}
+	ranges, err := parseRange(rangeHeader, size)
+	if err != nil {
+		return nil, err
+	}
+	var sum int64
+	for _, ra := range ranges {
+		sum += ra.length
+	}
+	if sum > size {
+		// the total size of all ranges is larger than the content itself,
+		// which is a possible attack or a dumb client, ignore the ranges
+		return nil, nil
+	}
+	return ranges, nil
+}
+
+// parseRange parses a Range header string as per RFC 7233. It is adapted
+// from the unexported function with the same name in the net/http package.
+// errNoOverlap is returned if none of the ranges overlap with the content.
+func parseRange(s string, size int64) ([]httpRange, error) {
+	const b = "bytes="
+	if !strings.HasPrefix(s, b) {
+		return nil, errInvalidRange
+	}
+	var ranges []httpRange
+	noOverlap := false
+	for _, ra := range strings.Split(s[len(b):], ",") {
+		ra = textproto.TrimString(ra)
+		if ra == "" {
+			continue
+		}
+		i := strings.Index(ra, "-")
+		if i < 0 {
+			return nil, errInvalidRange
+		}
+		start, end := textproto.TrimString(ra[:i]), textproto.TrimString(ra[i+1:])
+		var r httpRange
+		if start == "" {
+			// If no start is specified, end specifies the
+			// range start relative to the end of the file,
+			// and we are dealing with <suffix-length>
+			// which has to be a non-negative integer as per
+			// RFC 7233 Section 2.1 "Byte-Ranges".
+			if end == "" || end[0] == '-' {
+				return nil, errInvalidRange
+			}
+			i, err := strconv.ParseInt(end, 10, 64)
+			if i < 0 || err != nil {
+				return nil, errInvalidRange
+			}
+			if i > size {
+				i = size
+			}
+			r.start = size - i
+			r.length = size - r.start
+		} else {
+			i, err := strconv.ParseInt(start, 10, 64)
+			if err != nil || i < 0 {
+				return nil, errInvalidRange
+			}
+			if i >= size {
+				// If the range begins after the size of the content,
+				// then it does not overlap.
+				noOverlap = true
+				continue
+			}
+			r.start = i
+			if end == "" {
+				// If no end is specified, range extends to end of the file.
+				r.length = size - r.start
+			} else {
+				i, err := strconv.ParseInt(end, 10, 64)
+				if err != nil || r.start > i {
+					return nil, errInvalidRange
+				}
+				if i >= size {
+					i = size - 1
+				}
+				r.length = i - r.start + 1
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	if noOverlap && len(ranges) == 0 {
+		// The specified ranges did not overlap with the content.
+		return nil, errNoOverlap
+	}
+	return ranges, nil
+}
+
+// serveRanges writes a partial content response with the provided ranges of
+// the content. A single range is served as the response body, while multiple
+// ranges are served as a multipart/byteranges body. Only the chunks that the
+// ranges span are retrieved.
+func serveRanges(w http.ResponseWriter, r *http.Request, content io.ReaderAt, size int64, ranges []httpRange) error {
+	if len(ranges) == 1 {
+		ra := ranges[0]
+		w.Header().Set("Content-Range", ra.contentRange(size))
+		w.Header().Set("Content-Length", strconv.FormatInt(ra.length, 10))
+		w.WriteHeader(http.StatusPartialContent)
+		if r.Method == http.MethodHead {
+			return nil
+		}
+		_, err := io.Copy(w, rangeReader(content, ra))
+		return err
+	}
+
+	contentType := w.Header().Get(contentTypeHeader)
+	mw := multipart.NewWriter(w)
+	w.Header().Set(contentTypeHeader, "multipart/byteranges; boundary="+mw.Boundary())
+	w.Header().Set("Content-Length", strconv.FormatInt(multipartRangesSize(ranges, contentType, size, mw.Boundary()), 10))
+	w.WriteHeader(http.StatusPartialContent)
+	if r.Method == http.MethodHead {
+		return nil
+	}
+	for _, ra := range ranges {
+		part, err := mw.CreatePart(ra.mimeHeader(contentType, size))
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(part, rangeReader(content, ra)); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+// rangeReader returns a reader of the content section described by the range.
+// Lookahead prefetching starts at the beginning of the range and does not go
+// beyond its end.
+func rangeReader(content io.ReaderAt, ra httpRange) io.Reader {
+	return langos.NewBufferedLangos(io.NewSectionReader(content, ra.start, ra.length), lookaheadBufferSize(ra.length))
+}
+
+// multipartRangesSize returns the exact length of the multipart/byteranges
+// body with the provided ranges.
+func multipartRangesSize(ranges []httpRange, contentType string, size int64, boundary string) int64 {
+	var cw countingWriter
+	mw := multipart.NewWriter(&cw)
+	// the boundary is generated by the multipart writer and is always valid
+	_ = mw.SetBoundary(boundary)
+	var encSize int64
+	for _, ra := range ranges {
+		_, _ = mw.CreatePart(ra.mimeHeader(contentType, size))
+		encSize += ra.length
+	}
+	_ = mw.Close()
+	return encSize + int64(cw)
+}
+
+// countingWriter counts how many bytes have been written to it.
+type countingWriter int64
+
+func (w *countingWriter) Write(p []byte) (n int, err error) {
+	*w += countingWriter(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,198 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/ratelimit"
+)
+
+// RateLimits are the amounts a single client may use within a rate limit
+// window. Zero amounts are not limited.
+type RateLimits struct {
+	Requests      int64
+	UploadBytes   int64
+	DownloadBytes int64
+}
+
+// clientQuotas hold the quotas of one kind of clients,
+// nil quotas are not limited.
+type clientQuotas struct {
+	requests *ratelimit.Quota
+	upload   *ratelimit.Quota
+	download *ratelimit.Quota
+}
+
+func newClientQuotas(window time.Duration, l RateLimits) clientQuotas {
+	newQuota := func(limit int64) *ratelimit.Quota {
+		if window <= 0 || limit <= 0 {
+			return nil
+		}
+		return ratelimit.NewQuota(window, limit)
+	}
+	return clientQuotas{
+		requests: newQuota(l.Requests),
+		upload:   newQuota(l.UploadBytes),
+		download: newQuota(l.DownloadBytes),
+	}
+}
+
+// ParseTrustedProxies parses the IP addresses and the CIDR ranges of the
+// trusted reverse proxies.
+func ParseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, p := range proxies {
+		if !strings.Contains(p, "/") {
+			ip := net.ParseIP(p)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ip address %q", p)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(p)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// client returns the kind, the key and the quotas of the client making
+// the request. The clients authenticated with a valid bearer key are
+// limited by the key, the rest by their IP address.
+func (s *server) client(r *http.Request) (kind, key string, q clientQuotas) {
+	if s.auth != nil {
+		key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if key != "" && s.auth.Valid(key) {
+			return "token", key, s.tokenQuotas
+		}
+	}
+	return "ip", s.clientIP(r), s.ipQuotas
+}
+
+// clientIP returns the IP address of the client making the request. If the
+// request is made by a trusted proxy, the address is the last one of the
+// X-Forwarded-For headers which is not of a trusted proxy, as the ones
+// before it may be set by the client at will.
+func (s *server) clientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	if len(s.RateLimitTrustedProxies) == 0 {
+		return ip
+	}
+
+	var forwarded []string
+	for _, h := range r.Header.Values("X-Forwarded-For") {
+		for _, v := range strings.Split(h, ",") {
+			forwarded = append(forwarded, strings.TrimSpace(v))
+		}
+	}
+	for i := len(forwarded) - 1; i >= 0 && s.trustedProxy(ip); i-- {
+		ip = forwarded[i]
+	}
+	return ip
+}
+
+// trustedProxy reports whether the IP address is of a trusted proxy.
+func (s *server) trustedProxy(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, n := range s.RateLimitTrustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// rateLimitHandler responds with 429 Too Many Requests to the clients that
+// exceeded any of their quotas in the current window. The transferred
+// bytes are accounted for while the request is served, so a request is
+// not interrupted once it is admitted.
+func (s *server) rateLimitHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		kind, key, q := s.client(r)
+
+		for _, l := range []struct {
+			name  string
+			quota *ratelimit.Quota
+		}{
+			{name: "requests", quota: q.requests},
+			{name: "upload", quota: q.upload},
+			{name: "download", quota: q.download},
+		} {
+			if l.quota == nil {
+				continue
+			}
+			if wait := l.quota.Wait(key); wait > 0 {
+				s.logger.Tracef("rate limit: %s %s exceeded %s quota", kind, key, l.name)
+				s.metrics.RateLimitedCount.WithLabelValues(kind, l.name).Inc()
+				w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(wait.Seconds())), 10))
+				jsonhttp.TooManyRequests(w, nil)
+				return
+			}
+		}
+
+		if q.requests != nil {
+			q.requests.Use(key, 1)
+		}
+		if q.upload != nil && r.Body != nil {
+			r.Body = &quotaReader{ReadCloser: r.Body, quota: q.upload, key: key}
+		}
+		if q.download != nil {
+			w = &quotaWriter{UpgradedResponseWriter: w.(UpgradedResponseWriter), quota: q.download, key: key}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// quotaReader accounts for the bytes read from the request body.
+type quotaReader struct {
+	io.ReadCloser
+	quota *ratelimit.Quota
+	key   string
+}
+
+func (c *quotaReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	if n > 0 {
+		c.quota.Use(c.key, int64(n))
+	}
+	return n, err
+}
+
+// quotaWriter accounts for the bytes written to the response body.
+type quotaWriter struct {
+	UpgradedResponseWriter
+	quota *ratelimit.Quota
+	key   string
+}
+
+func (c *quotaWriter) Write(p []byte) (int, error) {
+	n, err := c.UpgradedResponseWriter.Write(p)
+	if n > 0 {
+		c.quota.Use(c.key, int64(n))
+	}
+	return n, err
+}
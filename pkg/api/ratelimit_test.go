@@ -0,0 +1,175 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/auth"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/logging"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/tags"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestRateLimit(t *testing.T) {
+	tooManyRequests := jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+		Message: http.StatusText(http.StatusTooManyRequests),
+		Code:    http.StatusTooManyRequests,
+	})
+
+	checkRetryAfter := func(t *testing.T, h http.Header) {
+		t.Helper()
+
+		if got := h.Get("Retry-After"); got != "60" {
+			t.Fatalf("got Retry-After %q, want %q", got, "60")
+		}
+	}
+
+	t.Run("requests", func(t *testing.T) {
+		client, _, _ := newTestServer(t, testServerOptions{
+			RateLimitWindow: time.Minute,
+			RateLimitIP:     api.RateLimits{Requests: 2},
+		})
+
+		jsonhttptest.Request(t, client, http.MethodGet, "/robots.txt", http.StatusOK)
+		jsonhttptest.Request(t, client, http.MethodGet, "/robots.txt", http.StatusOK)
+		h := jsonhttptest.Request(t, client, http.MethodGet, "/robots.txt", http.StatusTooManyRequests,
+			tooManyRequests,
+		)
+		checkRetryAfter(t, h)
+	})
+
+	t.Run("download", func(t *testing.T) {
+		client, _, _ := newTestServer(t, testServerOptions{
+			RateLimitWindow: time.Minute,
+			RateLimitIP:     api.RateLimits{DownloadBytes: 20},
+		})
+
+		// the request exceeding the quota is served in full
+		jsonhttptest.Request(t, client, http.MethodGet, "/robots.txt", http.StatusOK,
+			jsonhttptest.WithExpectedResponse([]byte("User-agent: *\nDisallow: /\n")),
+		)
+		h := jsonhttptest.Request(t, client, http.MethodGet, "/robots.txt", http.StatusTooManyRequests,
+			tooManyRequests,
+		)
+		checkRetryAfter(t, h)
+	})
+
+	t.Run("upload", func(t *testing.T) {
+		logger := logging.New(ioutil.Discard, 0)
+		client, _, _ := newTestServer(t, testServerOptions{
+			Tags:            tags.NewTags(statestore.NewStateStore(), logger),
+			Logger:          logger,
+			RateLimitWindow: time.Minute,
+			RateLimitIP:     api.RateLimits{UploadBytes: 1},
+		})
+
+		jsonhttptest.Request(t, client, http.MethodPost, "/tags", http.StatusCreated,
+			jsonhttptest.WithJSONRequestBody(api.TagRequest{}),
+		)
+		h := jsonhttptest.Request(t, client, http.MethodPost, "/tags", http.StatusTooManyRequests,
+			jsonhttptest.WithJSONRequestBody(api.TagRequest{}),
+			tooManyRequests,
+		)
+		checkRetryAfter(t, h)
+	})
+
+	t.Run("token", func(t *testing.T) {
+		hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		authenticator, err := auth.New(string(hash), statestore.NewStateStore())
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := authenticator.GenerateKey([]string{auth.RoleRead}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bearer := jsonhttptest.WithRequestHeader("Authorization", "Bearer "+key)
+
+		client, _, _ := newTestServer(t, testServerOptions{
+			Auth:            authenticator,
+			RateLimitWindow: time.Minute,
+			RateLimitIP:     api.RateLimits{Requests: 1},
+			RateLimitToken:  api.RateLimits{Requests: 2},
+		})
+
+		jsonhttptest.Request(t, client, http.MethodGet, "/robots.txt", http.StatusOK)
+		jsonhttptest.Request(t, client, http.MethodGet, "/robots.txt", http.StatusTooManyRequests)
+
+		// the authenticated client is limited by its own quota
+		jsonhttptest.Request(t, client, http.MethodGet, "/robots.txt", http.StatusOK, bearer)
+		jsonhttptest.Request(t, client, http.MethodGet, "/robots.txt", http.StatusOK, bearer)
+		jsonhttptest.Request(t, client, http.MethodGet, "/robots.txt", http.StatusTooManyRequests, bearer)
+
+		// an invalid key does not escape the IP quota
+		jsonhttptest.Request(t, client, http.MethodGet, "/robots.txt", http.StatusTooManyRequests,
+			jsonhttptest.WithRequestHeader("Authorization", "Bearer deadbeef"),
+		)
+	})
+
+	t.Run("trusted proxies", func(t *testing.T) {
+		// the test clients connect from the loopback address
+		proxies, err := api.ParseTrustedProxies([]string{"127.0.0.1", "10.0.0.0/8"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		client, _, _ := newTestServer(t, testServerOptions{
+			RateLimitWindow: time.Minute,
+			RateLimitIP:     api.RateLimits{Requests: 1},
+			TrustedProxies:  proxies,
+		})
+		forwardedFor := func(v string) jsonhttptest.Option {
+			return jsonhttptest.WithRequestHeader("X-Forwarded-For", v)
+		}
+
+		jsonhttptest.Request(t, client, http.MethodGet, "/robots.txt", http.StatusOK, forwardedFor("1.2.3.4"))
+		jsonhttptest.Request(t, client, http.MethodGet, "/robots.txt", http.StatusTooManyRequests, forwardedFor("1.2.3.4"))
+
+		// the clients behind the proxies are limited by their own quotas
+		jsonhttptest.Request(t, client, http.MethodGet, "/robots.txt", http.StatusOK, forwardedFor("5.6.7.8, 10.0.0.1"))
+
+		// the addresses set by the client before the proxies are ignored
+		jsonhttptest.Request(t, client, http.MethodGet, "/robots.txt", http.StatusTooManyRequests, forwardedFor("9.9.9.9, 1.2.3.4"))
+
+		// the proxy is limited by its own quota
+		jsonhttptest.Request(t, client, http.MethodGet, "/robots.txt", http.StatusOK)
+		jsonhttptest.Request(t, client, http.MethodGet, "/robots.txt", http.StatusTooManyRequests)
+	})
+
+	t.Run("untrusted proxies", func(t *testing.T) {
+		client, _, _ := newTestServer(t, testServerOptions{
+			RateLimitWindow: time.Minute,
+			RateLimitIP:     api.RateLimits{Requests: 1},
+		})
+
+		jsonhttptest.Request(t, client, http.MethodGet, "/robots.txt", http.StatusOK,
+			jsonhttptest.WithRequestHeader("X-Forwarded-For", "1.2.3.4"),
+		)
+		jsonhttptest.Request(t, client, http.MethodGet, "/robots.txt", http.StatusTooManyRequests,
+			jsonhttptest.WithRequestHeader("X-Forwarded-For", "5.6.7.8"),
+		)
+	})
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	if _, err := api.ParseTrustedProxies([]string{"192.168.0.1", "::1", "10.0.0.0/8"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"localhost", "10.0.0.0/33"} {
+		if _, err := api.ParseTrustedProxies([]string{p}); err == nil {
+			t.Fatalf("parsed invalid proxy %q", p)
+		}
+	}
+}
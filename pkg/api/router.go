@@ -14,6 +14,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"resenje.org/web"
 
+	"github.com/ethersphere/bee/pkg/auth"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/logging/httpaccess"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -43,14 +44,26 @@ func (s *server) setupRouting() {
 		fmt.Fprintln(w, "User-agent: *\nDisallow: /")
 	})
 
+	if s.auth != nil {
+		handle("/auth", jsonhttp.MethodHandler{
+			"POST": web.ChainHandlers(
+				jsonhttp.NewMaxBodyBytesHandler(512),
+				web.FinalHandlerFunc(s.authHandler),
+			),
+		})
+	}
+
 	handle("/bytes", jsonhttp.MethodHandler{
 		"POST": web.ChainHandlers(
+			s.restrictHandler(auth.RoleUpload),
+			s.uploadLimitHandler,
 			s.newTracingHandler("bytes-upload"),
 			web.FinalHandlerFunc(s.bytesUploadHandler),
 		),
 	})
 	handle("/bytes/{address}", jsonhttp.MethodHandler{
 		"GET": web.ChainHandlers(
+			s.restrictHandler(auth.RoleRead),
 			s.newTracingHandler("bytes-download"),
 			web.FinalHandlerFunc(s.bytesGetHandler),
 		),
@@ -58,47 +71,85 @@ func (s *server) setupRouting() {
 
 	handle("/chunks", jsonhttp.MethodHandler{
 		"POST": web.ChainHandlers(
+			s.restrictHandler(auth.RoleUpload),
 			jsonhttp.NewMaxBodyBytesHandler(swarm.ChunkWithSpanSize),
 			web.FinalHandlerFunc(s.chunkUploadHandler),
 		),
 	})
 
+	handle("/chunks/stream", web.ChainHandlers(
+		s.restrictHandler(auth.RoleUpload),
+		s.newTracingHandler("chunks-stream-upload"),
+		web.FinalHandlerFunc(s.chunkUploadStreamHandler),
+	))
+
 	handle("/chunks/{addr}", jsonhttp.MethodHandler{
-		"GET": http.HandlerFunc(s.chunkGetHandler),
+		"GET": web.ChainHandlers(
+			s.restrictHandler(auth.RoleRead),
+			web.FinalHandlerFunc(s.chunkGetHandler),
+		),
 	})
 
 	handle("/soc/{owner}/{id}", jsonhttp.MethodHandler{
 		"POST": web.ChainHandlers(
+			s.restrictHandler(auth.RoleUpload),
 			jsonhttp.NewMaxBodyBytesHandler(swarm.ChunkWithSpanSize),
 			web.FinalHandlerFunc(s.socUploadHandler),
 		),
 	})
 
+	// registered before the feed manifest route as "update" is not a valid topic
+	handle("/feeds/{topic}/update", jsonhttp.MethodHandler{
+		"POST": web.ChainHandlers(
+			s.restrictHandler(auth.RoleUpload),
+			jsonhttp.NewMaxBodyBytesHandler(1024),
+			web.FinalHandlerFunc(s.feedUpdateHandler),
+		),
+	})
+
 	handle("/feeds/{owner}/{topic}", jsonhttp.MethodHandler{
-		"GET": http.HandlerFunc(s.feedGetHandler),
+		"GET": web.ChainHandlers(
+			s.restrictHandler(auth.RoleRead),
+			web.FinalHandlerFunc(s.feedGetHandler),
+		),
 		"POST": web.ChainHandlers(
+			s.restrictHandler(auth.RoleUpload),
 			jsonhttp.NewMaxBodyBytesHandler(swarm.ChunkWithSpanSize),
 			web.FinalHandlerFunc(s.feedPostHandler),
 		),
 	})
 
+	handle("/feeds/{owner}/{topic}/history", jsonhttp.MethodHandler{
+		"GET": web.ChainHandlers(
+			s.restrictHandler(auth.RoleRead),
+			web.FinalHandlerFunc(s.feedHistoryHandler),
+		),
+	})
+
 	handle("/bzz", jsonhttp.MethodHandler{
 		"POST": web.ChainHandlers(
+			s.restrictHandler(auth.RoleUpload),
+			s.uploadLimitHandler,
 			s.newTracingHandler("bzz-upload"),
 			web.FinalHandlerFunc(s.bzzUploadHandler),
 		),
 	})
-	handle("/bzz/{address}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		u := r.URL
-		u.Path += "/"
-		http.Redirect(w, r, u.String(), http.StatusPermanentRedirect)
-	}))
+	handle("/bzz/{address}", web.ChainHandlers(
+		s.restrictHandler(auth.RoleRead),
+		web.FinalHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			u := r.URL
+			u.Path += "/"
+			http.Redirect(w, r, u.String(), http.StatusPermanentRedirect)
+		}),
+	))
 	handle("/bzz/{address}/{path:.*}", jsonhttp.MethodHandler{
 		"GET": web.ChainHandlers(
+			s.restrictHandler(auth.RoleRead),
 			s.newTracingHandler("bzz-download"),
 			web.FinalHandlerFunc(s.bzzDownloadHandler),
 		),
 		"PATCH": web.ChainHandlers(
+			s.restrictHandler(auth.RoleUpload),
 			s.newTracingHandler("bzz-patch"),
 			web.FinalHandlerFunc(s.bzzPatchHandler),
 		),
@@ -106,6 +157,7 @@ func (s *server) setupRouting() {
 
 	handle("/pss/send/{topic}/{targets}", web.ChainHandlers(
 		s.gatewayModeForbidEndpointHandler,
+		s.restrictHandler(auth.RolePss),
 		web.FinalHandler(jsonhttp.MethodHandler{
 			"POST": web.ChainHandlers(
 				jsonhttp.NewMaxBodyBytesHandler(swarm.ChunkSize),
@@ -114,13 +166,40 @@ func (s *server) setupRouting() {
 		})),
 	)
 
+	handle("/pss/request/{topic}/{targets}", web.ChainHandlers(
+		s.gatewayModeForbidEndpointHandler,
+		s.restrictHandler(auth.RolePss),
+		web.FinalHandler(jsonhttp.MethodHandler{
+			"POST": web.ChainHandlers(
+				jsonhttp.NewMaxBodyBytesHandler(swarm.ChunkSize),
+				web.FinalHandlerFunc(s.pssRequestHandler),
+			),
+		})),
+	)
+
+	handle("/pss/respond/{topic}", web.ChainHandlers(
+		s.gatewayModeForbidEndpointHandler,
+		s.restrictHandler(auth.RolePss),
+		web.FinalHandlerFunc(s.pssRespondHandler),
+	))
+
+	handle("/pss/mailbox/{topic}", web.ChainHandlers(
+		s.gatewayModeForbidEndpointHandler,
+		s.restrictHandler(auth.RolePss),
+		web.FinalHandler(jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.pssMailboxHandler),
+		})),
+	)
+
 	handle("/pss/subscribe/{topic}", web.ChainHandlers(
 		s.gatewayModeForbidEndpointHandler,
-		web.FinalHandlerFunc(s.pssWsHandler),
+		s.restrictHandler(auth.RolePss),
+		web.FinalHandlerFunc(s.pssSubscribeHandler),
 	))
 
 	handle("/tags", web.ChainHandlers(
 		s.gatewayModeForbidEndpointHandler,
+		s.restrictHandler(auth.RoleUpload),
 		web.FinalHandler(jsonhttp.MethodHandler{
 			"GET": http.HandlerFunc(s.listTagsHandler),
 			"POST": web.ChainHandlers(
@@ -131,6 +210,7 @@ func (s *server) setupRouting() {
 	)
 	handle("/tags/{id}", web.ChainHandlers(
 		s.gatewayModeForbidEndpointHandler,
+		s.restrictHandler(auth.RoleUpload),
 		web.FinalHandler(jsonhttp.MethodHandler{
 			"GET":    http.HandlerFunc(s.getTagHandler),
 			"DELETE": http.HandlerFunc(s.deleteTagHandler),
@@ -140,15 +220,24 @@ func (s *server) setupRouting() {
 			),
 		})),
 	)
+	handle("/tags/{id}/stream", web.ChainHandlers(
+		s.gatewayModeForbidEndpointHandler,
+		s.restrictHandler(auth.RoleUpload),
+		web.FinalHandler(jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.tagStreamHandler),
+		})),
+	)
 
 	handle("/pins", web.ChainHandlers(
 		s.gatewayModeForbidEndpointHandler,
+		s.restrictHandler(auth.RolePin),
 		web.FinalHandler(jsonhttp.MethodHandler{
 			"GET": http.HandlerFunc(s.listPinnedRootHashes),
 		})),
 	)
 	handle("/pins/{reference}", web.ChainHandlers(
 		s.gatewayModeForbidEndpointHandler,
+		s.restrictHandler(auth.RolePin),
 		web.FinalHandler(jsonhttp.MethodHandler{
 			"GET":    http.HandlerFunc(s.getPinnedRootHash),
 			"POST":   http.HandlerFunc(s.pinRootHash),
@@ -156,8 +245,42 @@ func (s *server) setupRouting() {
 		})),
 	)
 
+	handle("/act/{address}/grantees", web.ChainHandlers(
+		s.gatewayModeForbidEndpointHandler,
+		s.restrictHandler(auth.RoleUpload),
+		web.FinalHandler(jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.actGranteesGetHandler),
+			"PATCH": web.ChainHandlers(
+				jsonhttp.NewMaxBodyBytesHandler(swarm.ChunkSize),
+				web.FinalHandlerFunc(s.actGranteesPatchHandler),
+			),
+		})),
+	)
+
+	handle("/stewardship/{address}", web.ChainHandlers(
+		s.gatewayModeForbidEndpointHandler,
+		s.restrictHandler(auth.RoleRead),
+		web.FinalHandler(jsonhttp.MethodHandler{
+			"GET": web.ChainHandlers(
+				s.newTracingHandler("stewardship-get"),
+				web.FinalHandlerFunc(s.stewardshipGetHandler),
+			),
+		})),
+	)
+
+	handle("/stewardship/reupload/{address}", web.ChainHandlers(
+		s.gatewayModeForbidEndpointHandler,
+		s.restrictHandler(auth.RolePin),
+		web.FinalHandler(jsonhttp.MethodHandler{
+			"POST":   http.HandlerFunc(s.stewardshipReuploadStartHandler),
+			"GET":    http.HandlerFunc(s.stewardshipReuploadStatusHandler),
+			"DELETE": http.HandlerFunc(s.stewardshipReuploadCancelHandler),
+		})),
+	)
+
 	handle("/stamps", web.ChainHandlers(
 		s.gatewayModeForbidEndpointHandler,
+		s.restrictHandler(auth.RoleStamps),
 		web.FinalHandler(jsonhttp.MethodHandler{
 			"GET": http.HandlerFunc(s.postageGetStampsHandler),
 		})),
@@ -165,6 +288,7 @@ func (s *server) setupRouting() {
 
 	handle("/stamps/{id}", web.ChainHandlers(
 		s.gatewayModeForbidEndpointHandler,
+		s.restrictHandler(auth.RoleStamps),
 		web.FinalHandler(jsonhttp.MethodHandler{
 			"GET": http.HandlerFunc(s.postageGetStampHandler),
 		})),
@@ -172,11 +296,28 @@ func (s *server) setupRouting() {
 
 	handle("/stamps/{amount}/{depth}", web.ChainHandlers(
 		s.gatewayModeForbidEndpointHandler,
+		s.restrictHandler(auth.RoleStamps),
 		web.FinalHandler(jsonhttp.MethodHandler{
 			"POST": http.HandlerFunc(s.postageCreateHandler),
 		})),
 	)
 
+	handle("/stamps/topup/{id}/{amount}", web.ChainHandlers(
+		s.gatewayModeForbidEndpointHandler,
+		s.restrictHandler(auth.RoleStamps),
+		web.FinalHandler(jsonhttp.MethodHandler{
+			"PATCH": http.HandlerFunc(s.postageTopUpHandler),
+		})),
+	)
+
+	handle("/stamps/dilute/{id}/{depth}", web.ChainHandlers(
+		s.gatewayModeForbidEndpointHandler,
+		s.restrictHandler(auth.RoleStamps),
+		web.FinalHandler(jsonhttp.MethodHandler{
+			"PATCH": http.HandlerFunc(s.postageDiluteHandler),
+		})),
+	)
+
 	s.Handler = web.ChainHandlers(
 		httpaccess.NewHTTPAccessLogHandler(s.logger, logrus.InfoLevel, s.tracer, "api access"),
 		handlers.CompressHandler,
@@ -188,13 +329,14 @@ func (s *server) setupRouting() {
 				if o := r.Header.Get("Origin"); o != "" && s.checkOrigin(r) {
 					w.Header().Set("Access-Control-Allow-Credentials", "true")
 					w.Header().Set("Access-Control-Allow-Origin", o)
-					w.Header().Set("Access-Control-Allow-Headers", "Origin, Accept, Authorization, Content-Type, X-Requested-With, Access-Control-Request-Headers, Access-Control-Request-Method, Swarm-Tag, Swarm-Pin, Swarm-Encrypt, Swarm-Index-Document, Swarm-Error-Document, Swarm-Collection, Swarm-Postage-Batch-Id, Gas-Price")
+					w.Header().Set("Access-Control-Allow-Headers", "Origin, Accept, Authorization, Content-Type, X-Requested-With, Access-Control-Request-Headers, Access-Control-Request-Method, Swarm-Tag, Swarm-Pin, Swarm-Encrypt, Swarm-Index-Document, Swarm-Error-Document, Swarm-Collection, Swarm-Postage-Batch-Id, Swarm-Act, Gas-Price")
 					w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS, POST, PUT, DELETE")
 					w.Header().Set("Access-Control-Max-Age", "3600")
 				}
 				h.ServeHTTP(w, r)
 			})
 		},
+		s.rateLimitHandler,
 		s.gatewayModeForbidHeadersHandler,
 		web.FinalHandler(router),
 	)
@@ -219,6 +361,11 @@ func (s *server) gatewayModeForbidHeadersHandler(h http.Handler) http.Handler {
 				jsonhttp.Forbidden(w, "pinning is disabled")
 				return
 			}
+			if strings.ToLower(r.Header.Get(SwarmActHeader)) == "true" {
+				s.logger.Tracef("gateway mode: forbidden access control %s", r.URL.String())
+				jsonhttp.Forbidden(w, "access control is disabled")
+				return
+			}
 			if strings.ToLower(r.Header.Get(SwarmEncryptHeader)) == "true" {
 				s.logger.Tracef("gateway mode: forbidden encryption %s", r.URL.String())
 				jsonhttp.Forbidden(w, "encryption is disabled")
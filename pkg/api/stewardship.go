@@ -0,0 +1,171 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/steward"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/traversal"
+	"github.com/gorilla/mux"
+)
+
+type isRetrievableResponse struct {
+	IsRetrievable bool            `json:"isRetrievable"`
+	Missing       []swarm.Address `json:"missing"`
+	Incomplete    bool            `json:"incomplete"`
+}
+
+// stewardshipGetHandler checks whether the content on the given address is
+// retrievable from the network.
+func (s *server) stewardshipGetHandler(w http.ResponseWriter, r *http.Request) {
+	nameOrHex := mux.Vars(r)["address"]
+	address, err := s.resolveNameOrAddress(nameOrHex)
+	if err != nil {
+		s.logger.Debugf("stewardship get: parse address %s: %v", nameOrHex, err)
+		s.logger.Error("stewardship get: parse address")
+		jsonhttp.NotFound(w, nil)
+		return
+	}
+
+	missing, err := s.steward.IsRetrievable(r.Context(), address)
+	incomplete := errors.Is(err, traversal.ErrIncomplete)
+	if err != nil && !incomplete {
+		s.logger.Debugf("stewardship get: is retrievable %s: %v", address, err)
+		s.logger.Error("stewardship get: is retrievable")
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	jsonhttp.OK(w, isRetrievableResponse{
+		IsRetrievable: len(missing) == 0 && !incomplete,
+		Missing:       missing,
+		Incomplete:    incomplete,
+	})
+}
+
+type reuploadJobResponse struct {
+	Root        swarm.Address `json:"root"`
+	State       string        `json:"state"`
+	Concurrency int           `json:"concurrency"`
+	Tag         uint32        `json:"tag"`
+	Pushed      uint64        `json:"pushed"`
+	Failed      uint64        `json:"failed"`
+	Error       string        `json:"error,omitempty"`
+}
+
+func newReuploadJobResponse(status steward.ReuploadStatus) reuploadJobResponse {
+	return reuploadJobResponse{
+		Root:        status.Root,
+		State:       string(status.State),
+		Concurrency: status.Concurrency,
+		Tag:         status.Tag,
+		Pushed:      status.Pushed,
+		Failed:      status.Failed,
+		Error:       status.Error,
+	}
+}
+
+// stewardshipReuploadStartHandler starts a background job reuploading the
+// content on the given address, resuming the progress of a previous job.
+func (s *server) stewardshipReuploadStartHandler(w http.ResponseWriter, r *http.Request) {
+	nameOrHex := mux.Vars(r)["address"]
+	address, err := s.resolveNameOrAddress(nameOrHex)
+	if err != nil {
+		s.logger.Debugf("stewardship reupload: parse address %s: %v", nameOrHex, err)
+		s.logger.Error("stewardship reupload: parse address")
+		jsonhttp.NotFound(w, nil)
+		return
+	}
+
+	concurrency := steward.DefaultConcurrency
+	if v := r.URL.Query().Get("concurrency"); v != "" {
+		concurrency, err = strconv.Atoi(v)
+		if err != nil {
+			s.logger.Debugf("stewardship reupload: parse concurrency %s: %v", v, err)
+			s.logger.Error("stewardship reupload: parse concurrency")
+			jsonhttp.BadRequest(w, "invalid concurrency")
+			return
+		}
+	}
+
+	status, err := s.steward.StartReupload(address, concurrency)
+	if err != nil {
+		s.logger.Debugf("stewardship reupload: start %s: %v", address, err)
+		s.logger.Error("stewardship reupload: start")
+		switch {
+		case errors.Is(err, steward.ErrInvalidConcurrency):
+			jsonhttp.BadRequest(w, "invalid concurrency")
+		case errors.Is(err, steward.ErrJobRunning):
+			jsonhttp.Conflict(w, "reupload already running")
+		default:
+			jsonhttp.InternalServerError(w, nil)
+		}
+		return
+	}
+
+	jsonhttp.Accepted(w, newReuploadJobResponse(status))
+}
+
+// stewardshipReuploadStatusHandler returns the progress of the reupload job
+// of the content on the given address.
+func (s *server) stewardshipReuploadStatusHandler(w http.ResponseWriter, r *http.Request) {
+	nameOrHex := mux.Vars(r)["address"]
+	address, err := s.resolveNameOrAddress(nameOrHex)
+	if err != nil {
+		s.logger.Debugf("stewardship reupload status: parse address %s: %v", nameOrHex, err)
+		s.logger.Error("stewardship reupload status: parse address")
+		jsonhttp.NotFound(w, nil)
+		return
+	}
+
+	status, err := s.steward.ReuploadStatus(address)
+	if err != nil {
+		if errors.Is(err, steward.ErrJobNotFound) {
+			jsonhttp.NotFound(w, nil)
+			return
+		}
+		s.logger.Debugf("stewardship reupload status: %s: %v", address, err)
+		s.logger.Error("stewardship reupload status")
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	jsonhttp.OK(w, newReuploadJobResponse(status))
+}
+
+// stewardshipReuploadCancelHandler stops the running reupload job of the
+// content on the given address.
+func (s *server) stewardshipReuploadCancelHandler(w http.ResponseWriter, r *http.Request) {
+	nameOrHex := mux.Vars(r)["address"]
+	address, err := s.resolveNameOrAddress(nameOrHex)
+	if err != nil {
+		s.logger.Debugf("stewardship reupload cancel: parse address %s: %v", nameOrHex, err)
+		s.logger.Error("stewardship reupload cancel: parse address")
+		jsonhttp.NotFound(w, nil)
+		return
+	}
+
+	err = s.steward.CancelReupload(address)
+	if err != nil {
+		switch {
+		case errors.Is(err, steward.ErrJobNotFound):
+			jsonhttp.NotFound(w, nil)
+		case errors.Is(err, steward.ErrJobNotRunning):
+			jsonhttp.Conflict(w, "reupload not running")
+		default:
+			s.logger.Debugf("stewardship reupload cancel: %s: %v", address, err)
+			s.logger.Error("stewardship reupload cancel")
+			jsonhttp.InternalServerError(w, nil)
+		}
+		return
+	}
+
+	jsonhttp.OK(w, nil)
+}
@@ -0,0 +1,138 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/logging"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/steward"
+	smock "github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/ethersphere/bee/pkg/traversal"
+)
+
+func TestStewardshipIsRetrievable(t *testing.T) {
+	var (
+		logger  = logging.New(ioutil.Discard, 0)
+		m       = &mockSteward{}
+		addr    = swarm.NewAddress([]byte{31: 128})
+		missing = swarm.NewAddress([]byte{31: 129})
+	)
+	client, _, _ := newTestServer(t, testServerOptions{
+		Storer:  smock.NewStorer(),
+		Tags:    tags.NewTags(statestore.NewStateStore(), logger),
+		Logger:  logger,
+		Steward: m,
+	})
+
+	t.Run("retrievable", func(t *testing.T) {
+		m.missing = nil
+		jsonhttptest.Request(t, client, http.MethodGet, "/stewardship/"+addr.String(), http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.IsRetrievableResponse{
+				IsRetrievable: true,
+			}),
+		)
+		if !m.addr.Equal(addr) {
+			t.Fatalf("got address %s want %s", m.addr.String(), addr.String())
+		}
+	})
+
+	t.Run("missing chunks", func(t *testing.T) {
+		m.missing = []swarm.Address{missing}
+		jsonhttptest.Request(t, client, http.MethodGet, "/stewardship/"+addr.String(), http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.IsRetrievableResponse{
+				IsRetrievable: false,
+				Missing:       []swarm.Address{missing},
+			}),
+		)
+	})
+
+	t.Run("incomplete", func(t *testing.T) {
+		m.missing = []swarm.Address{missing}
+		m.err = traversal.ErrIncomplete
+		defer func() { m.err = nil }()
+		jsonhttptest.Request(t, client, http.MethodGet, "/stewardship/"+addr.String(), http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(api.IsRetrievableResponse{
+				IsRetrievable: false,
+				Missing:       []swarm.Address{missing},
+				Incomplete:    true,
+			}),
+		)
+	})
+}
+
+func TestStewardshipReupload(t *testing.T) {
+	var (
+		logger = logging.New(ioutil.Discard, 0)
+		m      = &mockSteward{}
+		addr   = swarm.NewAddress([]byte{31: 128})
+		url    = "/stewardship/reupload/" + addr.String()
+	)
+	client, _, _ := newTestServer(t, testServerOptions{
+		Storer:  smock.NewStorer(),
+		Tags:    tags.NewTags(statestore.NewStateStore(), logger),
+		Logger:  logger,
+		Steward: m,
+	})
+
+	jsonhttptest.Request(t, client, http.MethodGet, url, http.StatusNotFound)
+	jsonhttptest.Request(t, client, http.MethodDelete, url, http.StatusNotFound)
+	jsonhttptest.Request(t, client, http.MethodPost, url+"?concurrency=0", http.StatusBadRequest,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: "invalid concurrency",
+			Code:    http.StatusBadRequest,
+		}),
+	)
+
+	running := api.ReuploadJobResponse{
+		Root:        addr,
+		State:       string(steward.JobRunning),
+		Concurrency: 8,
+	}
+	jsonhttptest.Request(t, client, http.MethodPost, url+"?concurrency=8", http.StatusAccepted,
+		jsonhttptest.WithExpectedJSONResponse(running),
+	)
+	jsonhttptest.Request(t, client, http.MethodPost, url, http.StatusConflict,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: "reupload already running",
+			Code:    http.StatusConflict,
+		}),
+	)
+	jsonhttptest.Request(t, client, http.MethodGet, url, http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(running),
+	)
+
+	jsonhttptest.Request(t, client, http.MethodDelete, url, http.StatusOK)
+	jsonhttptest.Request(t, client, http.MethodDelete, url, http.StatusConflict,
+		jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+			Message: "reupload not running",
+			Code:    http.StatusConflict,
+		}),
+	)
+	jsonhttptest.Request(t, client, http.MethodGet, url, http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(api.ReuploadJobResponse{
+			Root:        addr,
+			State:       string(steward.JobCancelled),
+			Concurrency: 8,
+		}),
+	)
+
+	// the job is started with the default concurrency
+	jsonhttptest.Request(t, client, http.MethodPost, url, http.StatusAccepted,
+		jsonhttptest.WithExpectedJSONResponse(api.ReuploadJobResponse{
+			Root:        addr,
+			State:       string(steward.JobRunning),
+			Concurrency: steward.DefaultConcurrency,
+		}),
+	)
+}
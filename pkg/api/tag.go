@@ -38,9 +38,9 @@ func newTagResponse(tag *tags.Tag) tagResponse {
 	return tagResponse{
 		Uid:       tag.Uid,
 		StartedAt: tag.StartedAt,
-		Total:     tag.Total,
-		Processed: tag.Stored,
-		Synced:    tag.Seen + tag.Synced,
+		Total:     tag.TotalCounter(),
+		Processed: tag.Get(tags.StateStored),
+		Synced:    tag.Get(tags.StateSeen) + tag.Get(tags.StateSynced),
 	}
 }
 
@@ -0,0 +1,231 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+// tagStreamPeriod is the minimal time between two updates of a tag stream.
+const tagStreamPeriod = 100 * time.Millisecond
+
+type tagStreamResponse struct {
+	tagResponse
+	// Done is set on the last update, sent once all the chunks are synced.
+	Done bool `json:"done"`
+}
+
+func newTagStreamResponse(tag *tags.Tag) tagStreamResponse {
+	resp := newTagResponse(tag)
+	return tagStreamResponse{
+		tagResponse: resp,
+		Done:        resp.Total > 0 && resp.Synced >= resp.Total,
+	}
+}
+
+// tagStreamHandler pushes the counters of the tag whenever they change until
+// all the chunks are synced. The updates are sent over a websocket if the
+// connection is upgraded, otherwise as server-sent events.
+func (s *server) tagStreamHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := mux.Vars(r)["id"]
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		s.logger.Debugf("tag stream: parse id  %s: %v", idStr, err)
+		s.logger.Error("tag stream: parse id")
+		jsonhttp.BadRequest(w, "invalid id")
+		return
+	}
+
+	tag, err := s.tags.Get(uint32(id))
+	if err != nil {
+		if errors.Is(err, tags.ErrNotFound) {
+			s.logger.Debugf("tag stream: tag not present: %v, id %s", err, idStr)
+			s.logger.Error("tag stream: tag not present")
+			jsonhttp.NotFound(w, "tag not present")
+			return
+		}
+		s.logger.Debugf("tag stream: tag %v: %v", idStr, err)
+		s.logger.Errorf("tag stream: %v", idStr)
+		jsonhttp.InternalServerError(w, "cannot get tag")
+		return
+	}
+
+	if websocket.IsWebSocketUpgrade(r) {
+		s.tagWsHandler(w, r, tag)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.logger.Error("tag stream: streaming unsupported")
+		jsonhttp.InternalServerError(w, "streaming unsupported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	var eventID uint64
+	err = s.streamTag(r.Context(), tag, func(resp tagStreamResponse) error {
+		data, err := json.Marshal(resp)
+		if err != nil {
+			return err
+		}
+		eventID++
+		if _, err := fmt.Fprintf(w, "id: %d\ndata: %s\n\n", eventID, data); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}, func() error {
+		// keep the connection alive with a comment line
+		if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+	if err != nil {
+		s.logger.Debugf("tag stream: tag %v: %v", idStr, err)
+	}
+}
+
+func (s *server) tagWsHandler(w http.ResponseWriter, r *http.Request, tag *tags.Tag) {
+	upgrader := websocket.Upgrader{
+		CheckOrigin: s.checkOrigin,
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.logger.Debugf("tag stream: upgrade: %v", err)
+		s.logger.Error("tag stream: cannot upgrade")
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	s.wsWg.Add(1)
+	go s.pumpTagWs(conn, tag)
+}
+
+func (s *server) pumpTagWs(conn *websocket.Conn, tag *tags.Tag) {
+	defer s.wsWg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		_ = conn.Close()
+	}()
+
+	// the messages of the client are discarded, reading is needed
+	// to process the control messages and notice the client is gone
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	write := func(messageType int, data []byte) error {
+		if err := conn.SetWriteDeadline(time.Now().Add(writeDeadline)); err != nil {
+			return err
+		}
+		return conn.WriteMessage(messageType, data)
+	}
+
+	err := s.streamTag(ctx, tag, func(resp tagStreamResponse) error {
+		data, err := json.Marshal(resp)
+		if err != nil {
+			return err
+		}
+		return write(websocket.TextMessage, data)
+	}, func() error {
+		return write(websocket.PingMessage, nil)
+	})
+	if err != nil {
+		s.logger.Debugf("tag stream: tag %d: %v", tag.Uid, err)
+		return
+	}
+	if ctx.Err() != nil {
+		// client gone
+		return
+	}
+
+	err = write(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	if err != nil {
+		s.logger.Debugf("tag stream: write close message: %v", err)
+	}
+}
+
+// streamTag calls send with the counters of the tag whenever they change, at
+// most once per tagStreamPeriod, until all the chunks are synced or the
+// context is done. The ping function is called every WsPingPeriod while the
+// counters do not change.
+func (s *server) streamTag(ctx context.Context, tag *tags.Tag, send func(tagStreamResponse) error, ping func() error) error {
+	notifyC, cancel := tag.Subscribe()
+	defer cancel()
+
+	var pingC <-chan time.Time
+	if s.WsPingPeriod > 0 {
+		ticker := time.NewTicker(s.WsPingPeriod)
+		defer ticker.Stop()
+		pingC = ticker.C
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+
+	for {
+		resp := newTagStreamResponse(tag)
+		if err := send(resp); err != nil {
+			return err
+		}
+		if resp.Done {
+			return nil
+		}
+
+		// changes made within the period are coalesced into one update
+		timer.Reset(tagStreamPeriod)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil
+		case <-s.quit:
+			return nil
+		}
+
+	wait:
+		for {
+			select {
+			case <-notifyC:
+				break wait
+			case <-pingC:
+				if err := ping(); err != nil {
+					return err
+				}
+			case <-ctx.Done():
+				return nil
+			case <-s.quit:
+				return nil
+			}
+		}
+	}
+}
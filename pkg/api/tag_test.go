@@ -5,13 +5,18 @@
 package api_test
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"sort"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/logging"
 	mockpost "github.com/ethersphere/bee/pkg/postage/mock"
@@ -25,6 +30,7 @@ import (
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/swarm/test"
 	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/gorilla/websocket"
 	"gitlab.com/nolash/go-mockbytes"
 )
 
@@ -395,3 +401,115 @@ func tagValueTest(t *testing.T, id uint32, split, stored, seen, sent, synced, to
 		t.Errorf("tag total count mismatch. got %d want %d", tag.Total, total)
 	}
 }
+
+func TestTagStream(t *testing.T) {
+	var (
+		logger = logging.New(ioutil.Discard, 0)
+		tagsDB = tags.NewTags(statestore.NewStateStore(), logger)
+	)
+	tag, err := tagsDB.Create(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resource := tagsWithIdResource(tag.Uid) + "/stream"
+
+	// sync two chunks and see the third one as already synced
+	syncChunks := func() {
+		for _, state := range []tags.State{tags.StateStored, tags.StateStored, tags.StateSeen, tags.StateSynced, tags.StateSynced} {
+			if err := tag.Inc(state); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+
+	t.Run("events", func(t *testing.T) {
+		client, _, _ := newTestServer(t, testServerOptions{
+			Storer: mock.NewStorer(),
+			Tags:   tagsDB,
+			Logger: logger,
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, resource, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+
+		var (
+			r      = bufio.NewReader(resp.Body)
+			events []api.TagStreamResponse
+		)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			var e api.TagStreamResponse
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e); err != nil {
+				t.Fatal(err)
+			}
+			events = append(events, e)
+			if len(events) == 1 {
+				go syncChunks()
+			}
+			if e.Done {
+				break
+			}
+		}
+
+		if first := events[0]; first.Uid != tag.Uid || first.Total != 3 || first.Synced != 0 {
+			t.Fatalf("got first event %+v, want no synced chunks", first)
+		}
+		if last := events[len(events)-1]; last.Total != 3 || last.Processed != 2 || last.Synced != 3 {
+			t.Fatalf("got last event %+v, want all chunks synced", last)
+		}
+	})
+
+	t.Run("websocket", func(t *testing.T) {
+		_, conn, _ := newTestServer(t, testServerOptions{
+			Storer: mock.NewStorer(),
+			Tags:   tagsDB,
+			Logger: logger,
+			WsPath: resource,
+		})
+
+		// the tag is already synced, the only update is the last one
+		var e api.TagStreamResponse
+		if err := conn.ReadJSON(&e); err != nil {
+			t.Fatal(err)
+		}
+		if !e.Done || e.Synced != 3 {
+			t.Fatalf("got update %+v, want all chunks synced", e)
+		}
+		if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+			t.Fatalf("got error %v, want normal closure", err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		client, _, _ := newTestServer(t, testServerOptions{
+			Storer: mock.NewStorer(),
+			Tags:   tagsDB,
+			Logger: logger,
+		})
+		jsonhttptest.Request(t, client, http.MethodGet, tagsWithIdResource(tag.Uid+1)+"/stream", http.StatusNotFound,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "tag not present",
+				Code:    http.StatusNotFound,
+			}),
+		)
+	})
+}
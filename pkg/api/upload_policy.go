@@ -0,0 +1,114 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+var (
+	errUploadTooLarge         = errors.New("upload too large")
+	errTooManyFiles           = errors.New("too many files")
+	errUnsupportedContentType = errors.New("unsupported content type")
+)
+
+// uploadPolicy restricts the content uploaded in gateway mode.
+// The zero value allows any content.
+type uploadPolicy struct {
+	maxSize      int64
+	contentTypes []string
+	maxFiles     int
+}
+
+func newUploadPolicy(o Options) uploadPolicy {
+	if !o.GatewayMode {
+		return uploadPolicy{}
+	}
+	var contentTypes []string
+	for _, t := range o.GatewayContentTypes {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			contentTypes = append(contentTypes, t)
+		}
+	}
+	return uploadPolicy{
+		maxSize:      o.GatewayMaxUploadSize,
+		contentTypes: contentTypes,
+		maxFiles:     o.GatewayMaxManifestFiles,
+	}
+}
+
+// allowContentType reports whether the media type of the content type is
+// allowed. The "type/*" patterns allow all the subtypes of the type.
+func (p uploadPolicy) allowContentType(contentType string) bool {
+	if len(p.contentTypes) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range p.contentTypes {
+		if t == mediaType {
+			return true
+		}
+		if strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(t, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowFiles reports whether a manifest may reference the number of files.
+func (p uploadPolicy) allowFiles(n int) bool {
+	return p.maxFiles <= 0 || n <= p.maxFiles
+}
+
+// uploadLimitHandler limits the size of the uploaded content. The requests
+// declaring a larger content length are rejected upfront, the rest fail
+// with errUploadTooLarge as soon as the limit is exceeded while the
+// content is streamed to the pipeline.
+func (s *server) uploadLimitHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if limit := s.uploadPolicy.maxSize; limit > 0 {
+			if r.ContentLength > limit {
+				s.logger.Tracef("upload policy: content length %d exceeds %d", r.ContentLength, limit)
+				jsonhttp.RequestEntityTooLarge(w, errUploadTooLarge)
+				return
+			}
+			r.Body = &limitedReader{ReadCloser: r.Body, n: limit}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// limitedReader returns errUploadTooLarge once more than n bytes are read.
+type limitedReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		// check if there is any content left over the limit
+		var b [1]byte
+		n, err := l.ReadCloser.Read(b[:])
+		if n > 0 {
+			return 0, errUploadTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.ReadCloser.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
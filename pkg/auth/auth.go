@@ -0,0 +1,220 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package auth provides the authentication of the API requests with
+// expiring bearer tokens granting access to a set of roles. The tokens
+// are issued to the holder of the admin password.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Roles granting access to the groups of endpoints.
+const (
+	// RoleRead grants downloading the content.
+	RoleRead = "read"
+	// RoleUpload grants uploading the content and tracking the uploads.
+	RoleUpload = "upload"
+	// RolePin grants managing the pinned and the reuploaded content.
+	RolePin = "pin"
+	// RoleStamps grants buying and managing the postage batches.
+	RoleStamps = "stamps"
+	// RolePss grants sending and receiving pss messages.
+	RolePss = "pss"
+	// RoleConsumer grants the roles needed by a dapp,
+	// read, upload and pss.
+	RoleConsumer = "consumer"
+	// RoleAdmin grants all the roles and the debug API.
+	RoleAdmin = "admin"
+)
+
+const (
+	// DefaultExpiry is the expiry of the tokens if not specified otherwise.
+	DefaultExpiry = time.Hour
+	// MaxExpiry is the maximal expiry of the tokens.
+	MaxExpiry = 365 * 24 * time.Hour
+
+	tokenSize = 32
+
+	tokenKeyPrefix = "auth_token_"
+)
+
+var (
+	// ErrUnauthorized is returned when the token is unknown or expired.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is returned when the token does not grant the role.
+	ErrForbidden = errors.New("forbidden")
+	// ErrInvalidRole is returned when issuing a token for an unknown role.
+	ErrInvalidRole = errors.New("invalid role")
+	// ErrInvalidExpiry is returned when issuing a token with a negative
+	// expiry or one exceeding the MaxExpiry.
+	ErrInvalidExpiry = errors.New("invalid expiry")
+)
+
+// grants holds the roles granted by every role.
+var grants = map[string][]string{
+	RoleRead:     {RoleRead},
+	RoleUpload:   {RoleUpload},
+	RolePin:      {RolePin},
+	RoleStamps:   {RoleStamps},
+	RolePss:      {RolePss},
+	RoleConsumer: {RoleRead, RoleUpload, RolePss},
+	RoleAdmin:    {RoleRead, RoleUpload, RolePin, RoleStamps, RolePss, RoleAdmin},
+}
+
+// Authenticator issues the tokens and checks the roles they grant.
+// The tokens are kept in the state store by their sha256 hashes, so
+// that they survive a restart and can not be read from the store.
+type Authenticator struct {
+	passwordHash []byte
+	store        storage.StateStorer
+	now          func() time.Time
+}
+
+// token is the state store entry of an issued token.
+type token struct {
+	Roles   []string  `json:"roles"`
+	Expires time.Time `json:"expires"`
+}
+
+// New constructs an Authenticator with the bcrypt hash of the admin password
+// keeping the issued tokens in the state store.
+func New(passwordHash string, store storage.StateStorer) (*Authenticator, error) {
+	if _, err := bcrypt.Cost([]byte(passwordHash)); err != nil {
+		return nil, fmt.Errorf("admin password hash: %w", err)
+	}
+	return &Authenticator{
+		passwordHash: []byte(passwordHash),
+		store:        store,
+		now:          time.Now,
+	}, nil
+}
+
+// tokenKey returns the state store key of the token.
+func tokenKey(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return tokenKeyPrefix + hex.EncodeToString(h[:])
+}
+
+// Authorize reports whether the password is the admin password.
+func (a *Authenticator) Authorize(password string) bool {
+	return bcrypt.CompareHashAndPassword(a.passwordHash, []byte(password)) == nil
+}
+
+// GenerateKey issues a token granting the roles for the expiry duration.
+// The DefaultExpiry is used if the expiry is zero.
+func (a *Authenticator) GenerateKey(roles []string, expiry time.Duration) (string, error) {
+	if expiry == 0 {
+		expiry = DefaultExpiry
+	}
+	if expiry < 0 || expiry > MaxExpiry {
+		return "", ErrInvalidExpiry
+	}
+	if len(roles) == 0 {
+		return "", ErrInvalidRole
+	}
+
+	var t token
+	granted := make(map[string]struct{})
+	for _, role := range roles {
+		g, ok := grants[role]
+		if !ok {
+			return "", fmt.Errorf("%w: %q", ErrInvalidRole, role)
+		}
+		for _, r := range g {
+			if _, ok := granted[r]; !ok {
+				granted[r] = struct{}{}
+				t.Roles = append(t.Roles, r)
+			}
+		}
+	}
+
+	b := make([]byte, tokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	key := hex.EncodeToString(b)
+
+	now := a.now()
+	if err := a.pruneExpired(now); err != nil {
+		return "", fmt.Errorf("prune expired tokens: %w", err)
+	}
+	t.Expires = now.Add(expiry)
+	if err := a.store.Put(tokenKey(key), t); err != nil {
+		return "", fmt.Errorf("store token: %w", err)
+	}
+
+	return key, nil
+}
+
+// pruneExpired deletes the expired tokens from the state store.
+func (a *Authenticator) pruneExpired(now time.Time) error {
+	var expired []string
+	err := a.store.Iterate(tokenKeyPrefix, func(k, v []byte) (bool, error) {
+		var t token
+		if err := json.Unmarshal(v, &t); err != nil {
+			return true, err
+		}
+		if !now.Before(t.Expires) {
+			expired = append(expired, string(k))
+		}
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, k := range expired {
+		if err := a.store.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// get returns the token if it is known and not expired.
+func (a *Authenticator) get(key string) (token, error) {
+	var t token
+	if err := a.store.Get(tokenKey(key), &t); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return token{}, ErrUnauthorized
+		}
+		return token{}, err
+	}
+	if !a.now().Before(t.Expires) {
+		_ = a.store.Delete(tokenKey(key))
+		return token{}, ErrUnauthorized
+	}
+	return t, nil
+}
+
+// Enforce returns nil if the token grants the role, ErrUnauthorized if
+// the token is unknown or expired and ErrForbidden otherwise.
+func (a *Authenticator) Enforce(key, role string) error {
+	t, err := a.get(key)
+	if err != nil {
+		return err
+	}
+	for _, r := range t.Roles {
+		if r == role {
+			return nil
+		}
+	}
+	return ErrForbidden
+}
+
+// Valid reports whether the token is known and not expired.
+func (a *Authenticator) Valid(key string) bool {
+	_, err := a.get(key)
+	return err == nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/auth"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newAuthenticator(t *testing.T, password string) *auth.Authenticator {
+	t.Helper()
+	return newAuthenticatorWithStore(t, password, statestore.NewStateStore())
+}
+
+func newAuthenticatorWithStore(t *testing.T, password string, store storage.StateStorer) *auth.Authenticator {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := auth.New(string(hash), store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestAuthorize(t *testing.T) {
+	a := newAuthenticator(t, "secret")
+
+	if !a.Authorize("secret") {
+		t.Fatal("admin password not authorized")
+	}
+	if a.Authorize("guess") {
+		t.Fatal("wrong password authorized")
+	}
+}
+
+func TestNewInvalidHash(t *testing.T) {
+	if _, err := auth.New("secret", statestore.NewStateStore()); err == nil {
+		t.Fatal("expected error for a password that is not a bcrypt hash")
+	}
+}
+
+func TestEnforce(t *testing.T) {
+	a := newAuthenticator(t, "secret")
+
+	key, err := a.GenerateKey([]string{auth.RoleConsumer, auth.RolePin}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		role string
+		want error
+	}{
+		{role: auth.RoleRead},
+		{role: auth.RoleUpload},
+		{role: auth.RolePss},
+		{role: auth.RolePin},
+		{role: auth.RoleStamps, want: auth.ErrForbidden},
+		{role: auth.RoleAdmin, want: auth.ErrForbidden},
+	} {
+		if err := a.Enforce(key, tc.role); !errors.Is(err, tc.want) {
+			t.Errorf("role %s: got error %v, want %v", tc.role, err, tc.want)
+		}
+	}
+
+	if err := a.Enforce("unknown", auth.RoleRead); !errors.Is(err, auth.ErrUnauthorized) {
+		t.Fatalf("got error %v, want %v", err, auth.ErrUnauthorized)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	a := newAuthenticator(t, "secret")
+	now := time.Now()
+	auth.SetTime(a, func() time.Time { return now })
+
+	key, err := a.GenerateKey([]string{auth.RoleRead}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Enforce(key, auth.RoleRead); err != nil {
+		t.Fatal(err)
+	}
+	if !a.Valid(key) {
+		t.Fatal("key not valid")
+	}
+
+	now = now.Add(time.Minute)
+	if a.Valid(key) {
+		t.Fatal("expired key valid")
+	}
+	if err := a.Enforce(key, auth.RoleRead); !errors.Is(err, auth.ErrUnauthorized) {
+		t.Fatalf("got error %v, want %v", err, auth.ErrUnauthorized)
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	store := statestore.NewStateStore()
+	a := newAuthenticatorWithStore(t, "secret", store)
+
+	key, err := a.GenerateKey([]string{auth.RoleRead}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the token is valid after a restart
+	a = newAuthenticatorWithStore(t, "secret", store)
+	if err := a.Enforce(key, auth.RoleRead); err != nil {
+		t.Fatal(err)
+	}
+
+	// the token is not stored in the clear
+	err = store.Iterate("", func(k, v []byte) (bool, error) {
+		if strings.Contains(string(k), key) || strings.Contains(string(v), key) {
+			t.Fatalf("token found in the state store entry %q", k)
+		}
+		return false, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGenerateKeyInvalid(t *testing.T) {
+	a := newAuthenticator(t, "secret")
+
+	if _, err := a.GenerateKey([]string{"root"}, 0); !errors.Is(err, auth.ErrInvalidRole) {
+		t.Fatalf("got error %v, want %v", err, auth.ErrInvalidRole)
+	}
+	if _, err := a.GenerateKey(nil, 0); !errors.Is(err, auth.ErrInvalidRole) {
+		t.Fatalf("got error %v, want %v", err, auth.ErrInvalidRole)
+	}
+	if _, err := a.GenerateKey([]string{auth.RoleRead}, -time.Second); !errors.Is(err, auth.ErrInvalidExpiry) {
+		t.Fatalf("got error %v, want %v", err, auth.ErrInvalidExpiry)
+	}
+	if _, err := a.GenerateKey([]string{auth.RoleRead}, auth.MaxExpiry+time.Second); !errors.Is(err, auth.ErrInvalidExpiry) {
+		t.Fatalf("got error %v, want %v", err, auth.ErrInvalidExpiry)
+	}
+}
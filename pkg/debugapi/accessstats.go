@@ -0,0 +1,89 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/accessstats"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	defaultHotContentLimit = 20
+	maxHotContentLimit     = 1000
+)
+
+type hotContentEntry struct {
+	Address swarm.Address `json:"address"`
+	Count   uint64        `json:"count"`
+}
+
+type hotContentResponse struct {
+	Window string            `json:"window"`
+	Roots  []hotContentEntry `json:"roots"`
+	Chunks []hotContentEntry `json:"chunks"`
+}
+
+// hotContentHandler lists the most requested root references and chunks
+// within the time window.
+func (s *Service) hotContentHandler(w http.ResponseWriter, r *http.Request) {
+	window := s.accessStats.Windows()[0]
+	if v := r.URL.Query().Get("window"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			s.logger.Debugf("debug api: hot content: parse window %q: %v", v, err)
+			s.logger.Error("debug api: hot content: parse window")
+			jsonhttp.BadRequest(w, "invalid window")
+			return
+		}
+		window = d
+	}
+
+	limit := defaultHotContentLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxHotContentLimit {
+			s.logger.Debugf("debug api: hot content: parse limit %q: %v", v, err)
+			s.logger.Error("debug api: hot content: parse limit")
+			jsonhttp.BadRequest(w, "invalid limit")
+			return
+		}
+		limit = n
+	}
+
+	roots, chunks, err := s.accessStats.Top(window, limit)
+	if err != nil {
+		s.logger.Debugf("debug api: hot content: %v", err)
+		s.logger.Error("debug api: hot content")
+		if errors.Is(err, accessstats.ErrUnknownWindow) {
+			jsonhttp.BadRequest(w, "unknown window")
+			return
+		}
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	jsonhttp.OK(w, hotContentResponse{
+		Window: window.String(),
+		Roots:  hotContentEntries(roots),
+		Chunks: hotContentEntries(chunks),
+	})
+}
+
+func hotContentEntries(entries []accessstats.Entry) []hotContentEntry {
+	r := make([]hotContentEntry, len(entries))
+	for i, e := range entries {
+		r[i] = hotContentEntry{
+			Address: e.Address,
+			Count:   e.Count,
+		}
+	}
+	return r
+}
@@ -0,0 +1,57 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/accessstats"
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestHotContent(t *testing.T) {
+	stats := accessstats.New(accessstats.Options{})
+	testServer := newTestServer(t, testServerOptions{
+		AccessStats: stats,
+	})
+
+	root := swarm.MustParseHexAddress("aa")
+	chunk := swarm.MustParseHexAddress("bb")
+	stats.RecordRoot(root)
+	stats.RecordRoot(root)
+	stats.RecordChunk(chunk)
+
+	t.Run("ok", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/stats/hot?window=24h", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(debugapi.HotContentResponse{
+				Window: "24h0m0s",
+				Roots:  []debugapi.HotContentEntry{{Address: root, Count: 2}},
+				Chunks: []debugapi.HotContentEntry{{Address: chunk, Count: 1}},
+			}),
+		)
+	})
+
+	t.Run("unknown window", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/stats/hot?window=1m", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "unknown window",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/stats/hot?limit=0", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "invalid limit",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+}
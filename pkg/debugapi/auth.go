@@ -0,0 +1,44 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/auth"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+)
+
+// restrictHandler allows only the requests authenticated with a bearer key
+// if the access is restricted. The postage stamps endpoints require the
+// stamps role, the health and readiness probes are open and the rest of the
+// endpoints require the admin role.
+func (s *Service) restrictHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.auth == nil || r.URL.Path == "/health" || r.URL.Path == "/readiness" {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		role := auth.RoleAdmin
+		if r.URL.Path == "/stamps" || strings.HasPrefix(r.URL.Path, "/stamps/") {
+			role = auth.RoleStamps
+		}
+
+		key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if err := s.auth.Enforce(key, role); err != nil {
+			s.logger.Tracef("debug api: auth: %s %s: %v", role, r.URL.String(), err)
+			if errors.Is(err, auth.ErrForbidden) {
+				jsonhttp.Forbidden(w, nil)
+				return
+			}
+			jsonhttp.Unauthorized(w, nil)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/auth"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/postage/batchstore/mock"
+	mockpost "github.com/ethersphere/bee/pkg/postage/mock"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestRestricted(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator, err := auth.New(string(hash), statestore.NewStateStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stampsKey, err := authenticator.GenerateKey([]string{auth.RoleStamps}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminKey, err := authenticator.GenerateKey([]string{auth.RoleAdmin}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := newTestServer(t, testServerOptions{
+		Post:       mockpost.New(),
+		BatchStore: mock.New(),
+		Auth:       authenticator,
+	})
+
+	for _, tc := range []struct {
+		path   string
+		key    string
+		status int
+	}{
+		{path: "/health", status: http.StatusOK},
+		{path: "/readiness", status: http.StatusOK},
+		{path: "/stamps", status: http.StatusUnauthorized},
+		{path: "/stamps", key: "deadbeef", status: http.StatusUnauthorized},
+		{path: "/stamps", key: stampsKey, status: http.StatusOK},
+		{path: "/reservestate", key: stampsKey, status: http.StatusForbidden},
+		{path: "/stamps", key: adminKey, status: http.StatusOK},
+		{path: "/reservestate", key: adminKey, status: http.StatusOK},
+	} {
+		var opts []jsonhttptest.Option
+		if tc.key != "" {
+			opts = append(opts, jsonhttptest.WithRequestHeader("Authorization", "Bearer "+tc.key))
+		}
+		jsonhttptest.Request(t, ts.Client, http.MethodGet, tc.path, tc.status, opts...)
+	}
+}
@@ -34,6 +34,9 @@ type (
 	PostageCreateResponse             = postageCreateResponse
 	PostageStampResponse              = postageStampResponse
 	PostageStampsResponse             = postageStampsResponse
+	VerifyResponse                    = verifyResponse
+	HotContentResponse                = hotContentResponse
+	HotContentEntry                   = hotContentEntry
 )
 
 var (
@@ -14,6 +14,7 @@ import (
 
 	"github.com/ethersphere/bee/pkg/bigint"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/postage"
 	"github.com/ethersphere/bee/pkg/postage/postagecontract"
 	"github.com/ethersphere/bee/pkg/sctx"
 	"github.com/gorilla/mux"
@@ -92,15 +93,20 @@ func (s *Service) postageCreateHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 type postageStampResponse struct {
-	BatchID       batchID        `json:"batchID"`
-	Utilization   uint32         `json:"utilization"`
-	Usable        bool           `json:"usable"`
-	Label         string         `json:"label"`
-	Depth         uint8          `json:"depth"`
-	Amount        *bigint.BigInt `json:"amount"`
-	BucketDepth   uint8          `json:"bucketDepth"`
-	BlockNumber   uint64         `json:"blockNumber"`
-	ImmutableFlag bool           `json:"immutableFlag"`
+	BatchID          batchID        `json:"batchID"`
+	Utilization      uint32         `json:"utilization"`
+	Usable           bool           `json:"usable"`
+	Label            string         `json:"label"`
+	Depth            uint8          `json:"depth"`
+	Amount           *bigint.BigInt `json:"amount"`
+	BucketDepth      uint8          `json:"bucketDepth"`
+	BlockNumber      uint64         `json:"blockNumber"`
+	ImmutableFlag    bool           `json:"immutableFlag"`
+	BatchTTL         int64          `json:"batchTTL"` // estimated seconds until expiry, -1 if unknown
+	ExpiryBlock      uint64         `json:"expiryBlock"`
+	BucketUpperBound uint32         `json:"bucketUpperBound"`
+	BucketHistogram  []uint32       `json:"bucketHistogram"`
+	NearFull         bool           `json:"nearFull"`
 }
 
 type postageStampsResponse struct {
@@ -110,17 +116,7 @@ type postageStampsResponse struct {
 func (s *Service) postageGetStampsHandler(w http.ResponseWriter, _ *http.Request) {
 	resp := postageStampsResponse{}
 	for _, v := range s.post.StampIssuers() {
-		resp.Stamps = append(resp.Stamps, postageStampResponse{
-			BatchID:       v.ID(),
-			Utilization:   v.Utilization(),
-			Usable:        s.post.IssuerUsable(v),
-			Label:         v.Label(),
-			Depth:         v.Depth(),
-			Amount:        bigint.Wrap(v.Amount()),
-			BucketDepth:   v.BucketDepth(),
-			BlockNumber:   v.BlockNumber(),
-			ImmutableFlag: v.ImmutableFlag(),
-		})
+		resp.Stamps = append(resp.Stamps, s.stampIssuerResponse(v))
 	}
 	jsonhttp.OK(w, resp)
 }
@@ -147,18 +143,43 @@ func (s *Service) postageGetStampHandler(w http.ResponseWriter, r *http.Request)
 		jsonhttp.BadRequest(w, "cannot get issuer")
 		return
 	}
+	resp := s.stampIssuerResponse(issuer)
+	jsonhttp.OK(w, &resp)
+}
+
+// stampIssuerResponse returns the state of the stamp issuer together with
+// the expiry of its batch estimated from the current chain state.
+func (s *Service) stampIssuerResponse(issuer *postage.StampIssuer) postageStampResponse {
 	resp := postageStampResponse{
-		BatchID:       id,
-		Utilization:   issuer.Utilization(),
-		Usable:        s.post.IssuerUsable(issuer),
-		Label:         issuer.Label(),
-		Depth:         issuer.Depth(),
-		Amount:        bigint.Wrap(issuer.Amount()),
-		BucketDepth:   issuer.BucketDepth(),
-		BlockNumber:   issuer.BlockNumber(),
-		ImmutableFlag: issuer.ImmutableFlag(),
+		BatchID:          issuer.ID(),
+		Utilization:      issuer.Utilization(),
+		Usable:           s.post.IssuerUsable(issuer),
+		Label:            issuer.Label(),
+		Depth:            issuer.Depth(),
+		Amount:           bigint.Wrap(issuer.Amount()),
+		BucketDepth:      issuer.BucketDepth(),
+		BlockNumber:      issuer.BlockNumber(),
+		ImmutableFlag:    issuer.ImmutableFlag(),
+		BatchTTL:         -1,
+		BucketUpperBound: issuer.BucketUpperBound(),
+		BucketHistogram:  issuer.BucketHistogram(),
+		NearFull:         issuer.NearFull(),
 	}
-	jsonhttp.OK(w, &resp)
+
+	batch, err := s.batchStore.Get(issuer.ID())
+	if err != nil {
+		// the batch may not be synced from the chain yet
+		s.logger.Debugf("get stamp issuer: get batch %x: %v", issuer.ID(), err)
+		return resp
+	}
+	cs := s.batchStore.GetChainState()
+	expiry, ok := postage.ExpiryBlock(cs, batch.Value)
+	if !ok {
+		return resp
+	}
+	resp.ExpiryBlock = expiry
+	resp.BatchTTL = postage.TTL(expiry-cs.Block, s.blockTime)
+	return resp
 }
 
 type reserveStateResponse struct {
@@ -196,3 +217,120 @@ func (s *Service) chainStateHandler(w http.ResponseWriter, _ *http.Request) {
 		CurrentPrice: bigint.Wrap(state.CurrentPrice),
 	})
 }
+
+func (s *Service) postageTopUpHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := mux.Vars(r)["id"]
+	if len(idStr) != 64 {
+		s.logger.Error("topup batch: invalid batchID")
+		jsonhttp.BadRequest(w, "invalid batchID")
+		return
+	}
+	id, err := hex.DecodeString(idStr)
+	if err != nil {
+		s.logger.Debugf("topup batch: invalid batchID: %v", err)
+		s.logger.Error("topup batch: invalid batchID")
+		jsonhttp.BadRequest(w, "invalid batchID")
+		return
+	}
+
+	amount, ok := big.NewInt(0).SetString(mux.Vars(r)["amount"], 10)
+	if !ok || amount.Sign() <= 0 {
+		s.logger.Error("topup batch: invalid amount")
+		jsonhttp.BadRequest(w, "invalid postage amount")
+		return
+	}
+
+	ctx := r.Context()
+	if price, ok := r.Header[gasPriceHeader]; ok {
+		p, ok := big.NewInt(0).SetString(price[0], 10)
+		if !ok {
+			s.logger.Error("topup batch: bad gas price")
+			jsonhttp.BadRequest(w, errBadGasPrice)
+			return
+		}
+		ctx = sctx.SetGasPrice(ctx, p)
+	}
+
+	err = s.postageContract.TopUpBatch(ctx, id, amount)
+	if err != nil {
+		if errors.Is(err, postagecontract.ErrInsufficientFunds) {
+			s.logger.Debugf("topup batch: out of funds: %v", err)
+			s.logger.Error("topup batch: out of funds")
+			jsonhttp.BadRequest(w, "out of funds")
+			return
+		}
+		if errors.Is(err, postagecontract.ErrNotFound) {
+			s.logger.Debugf("topup batch: batch not found: %v", err)
+			s.logger.Error("topup batch: batch not found")
+			jsonhttp.NotFound(w, "batch not found")
+			return
+		}
+		s.logger.Debugf("topup batch: failed to top up: %v", err)
+		s.logger.Error("topup batch: failed to top up")
+		jsonhttp.InternalServerError(w, "cannot topup batch")
+		return
+	}
+
+	jsonhttp.OK(w, &postageCreateResponse{
+		BatchID: id,
+	})
+}
+
+func (s *Service) postageDiluteHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := mux.Vars(r)["id"]
+	if len(idStr) != 64 {
+		s.logger.Error("dilute batch: invalid batchID")
+		jsonhttp.BadRequest(w, "invalid batchID")
+		return
+	}
+	id, err := hex.DecodeString(idStr)
+	if err != nil {
+		s.logger.Debugf("dilute batch: invalid batchID: %v", err)
+		s.logger.Error("dilute batch: invalid batchID")
+		jsonhttp.BadRequest(w, "invalid batchID")
+		return
+	}
+
+	depth, err := strconv.ParseUint(mux.Vars(r)["depth"], 10, 8)
+	if err != nil {
+		s.logger.Debugf("dilute batch: invalid depth: %v", err)
+		s.logger.Error("dilute batch: invalid depth")
+		jsonhttp.BadRequest(w, "invalid depth")
+		return
+	}
+
+	ctx := r.Context()
+	if price, ok := r.Header[gasPriceHeader]; ok {
+		p, ok := big.NewInt(0).SetString(price[0], 10)
+		if !ok {
+			s.logger.Error("dilute batch: bad gas price")
+			jsonhttp.BadRequest(w, errBadGasPrice)
+			return
+		}
+		ctx = sctx.SetGasPrice(ctx, p)
+	}
+
+	err = s.postageContract.DiluteBatch(ctx, id, uint8(depth))
+	if err != nil {
+		if errors.Is(err, postagecontract.ErrInvalidDepth) {
+			s.logger.Debugf("dilute batch: invalid depth: %v", err)
+			s.logger.Error("dilute batch: invalid depth")
+			jsonhttp.BadRequest(w, "invalid depth")
+			return
+		}
+		if errors.Is(err, postagecontract.ErrNotFound) {
+			s.logger.Debugf("dilute batch: batch not found: %v", err)
+			s.logger.Error("dilute batch: batch not found")
+			jsonhttp.NotFound(w, "batch not found")
+			return
+		}
+		s.logger.Debugf("dilute batch: failed to dilute: %v", err)
+		s.logger.Error("dilute batch: failed to dilute")
+		jsonhttp.InternalServerError(w, "cannot dilute batch")
+		return
+	}
+
+	jsonhttp.OK(w, &postageCreateResponse{
+		BatchID: id,
+	})
+}
@@ -5,6 +5,7 @@
 package debugapi_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"errors"
@@ -12,6 +13,7 @@ import (
 	"math/big"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/bigint"
 	"github.com/ethersphere/bee/pkg/debugapi"
@@ -196,21 +198,24 @@ func TestPostageCreateStamp(t *testing.T) {
 func TestPostageGetStamps(t *testing.T) {
 	si := postage.NewStampIssuer("", "", batchOk, big.NewInt(3), 11, 10, 1000, true)
 	mp := mockpost.New(mockpost.WithIssuer(si))
-	ts := newTestServer(t, testServerOptions{Post: mp})
+	ts := newTestServer(t, testServerOptions{Post: mp, BatchStore: mock.New()})
 
 	jsonhttptest.Request(t, ts.Client, http.MethodGet, "/stamps", http.StatusOK,
 		jsonhttptest.WithExpectedJSONResponse(&debugapi.PostageStampsResponse{
 			Stamps: []debugapi.PostageStampResponse{
 				{
-					BatchID:       batchOk,
-					Utilization:   si.Utilization(),
-					Usable:        true,
-					Label:         si.Label(),
-					Depth:         si.Depth(),
-					Amount:        bigint.Wrap(si.Amount()),
-					BucketDepth:   si.BucketDepth(),
-					BlockNumber:   si.BlockNumber(),
-					ImmutableFlag: si.ImmutableFlag(),
+					BatchID:          batchOk,
+					Utilization:      si.Utilization(),
+					Usable:           true,
+					Label:            si.Label(),
+					Depth:            si.Depth(),
+					Amount:           bigint.Wrap(si.Amount()),
+					BucketDepth:      si.BucketDepth(),
+					BlockNumber:      si.BlockNumber(),
+					ImmutableFlag:    si.ImmutableFlag(),
+					BatchTTL:         -1,
+					BucketUpperBound: si.BucketUpperBound(),
+					BucketHistogram:  si.BucketHistogram(),
 				},
 			},
 		}),
@@ -220,20 +225,28 @@ func TestPostageGetStamps(t *testing.T) {
 func TestPostageGetStamp(t *testing.T) {
 	si := postage.NewStampIssuer("", "", batchOk, big.NewInt(3), 11, 10, 1000, true)
 	mp := mockpost.New(mockpost.WithIssuer(si))
-	ts := newTestServer(t, testServerOptions{Post: mp})
+	bs := mock.New(
+		mock.WithBatch(&postage.Batch{ID: batchOk, Value: big.NewInt(1000)}),
+		mock.WithChainState(&postage.ChainState{Block: 100, TotalAmount: big.NewInt(100), CurrentPrice: big.NewInt(3)}),
+	)
+	ts := newTestServer(t, testServerOptions{Post: mp, BatchStore: bs, BlockTime: 5 * time.Second})
 
 	t.Run("ok", func(t *testing.T) {
 		jsonhttptest.Request(t, ts.Client, http.MethodGet, "/stamps/"+batchOkStr, http.StatusOK,
 			jsonhttptest.WithExpectedJSONResponse(&debugapi.PostageStampResponse{
-				BatchID:       batchOk,
-				Utilization:   si.Utilization(),
-				Usable:        true,
-				Label:         si.Label(),
-				Depth:         si.Depth(),
-				Amount:        bigint.Wrap(si.Amount()),
-				BucketDepth:   si.BucketDepth(),
-				BlockNumber:   si.BlockNumber(),
-				ImmutableFlag: si.ImmutableFlag(),
+				BatchID:          batchOk,
+				Utilization:      si.Utilization(),
+				Usable:           true,
+				Label:            si.Label(),
+				Depth:            si.Depth(),
+				Amount:           bigint.Wrap(si.Amount()),
+				BucketDepth:      si.BucketDepth(),
+				BlockNumber:      si.BlockNumber(),
+				ImmutableFlag:    si.ImmutableFlag(),
+				BatchTTL:         1500, // 300 blocks with a block time of 5 seconds
+				ExpiryBlock:      400,
+				BucketUpperBound: si.BucketUpperBound(),
+				BucketHistogram:  si.BucketHistogram(),
 			}),
 		)
 	})
@@ -314,3 +327,93 @@ func TestChainState(t *testing.T) {
 		)
 	})
 }
+
+func TestPostageTopUpStamp(t *testing.T) {
+	topupAmount := int64(1000)
+
+	t.Run("ok", func(t *testing.T) {
+		contract := contractMock.New(
+			contractMock.WithTopUpBatchFunc(func(ctx context.Context, id []byte, ib *big.Int) error {
+				if !bytes.Equal(id, batchOk) {
+					return errors.New("incorrect batch ID in call")
+				}
+				if ib.Cmp(big.NewInt(topupAmount)) != 0 {
+					return fmt.Errorf("called with wrong topup amount. wanted %d, got %d", topupAmount, ib)
+				}
+				return nil
+			}),
+		)
+		ts := newTestServer(t, testServerOptions{
+			PostageContract: contract,
+		})
+
+		jsonhttptest.Request(t, ts.Client, http.MethodPatch, fmt.Sprintf("/stamps/topup/%s/%d", batchOkStr, topupAmount), http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(&debugapi.PostageCreateResponse{
+				BatchID: batchOk,
+			}),
+		)
+	})
+
+	t.Run("out-of-funds", func(t *testing.T) {
+		contract := contractMock.New(
+			contractMock.WithTopUpBatchFunc(func(ctx context.Context, id []byte, ib *big.Int) error {
+				return postagecontract.ErrInsufficientFunds
+			}),
+		)
+		ts := newTestServer(t, testServerOptions{
+			PostageContract: contract,
+		})
+
+		jsonhttptest.Request(t, ts.Client, http.MethodPatch, fmt.Sprintf("/stamps/topup/%s/%d", batchOkStr, topupAmount), http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(&jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "out of funds",
+			}),
+		)
+	})
+}
+
+func TestPostageDiluteStamp(t *testing.T) {
+	newBatchDepth := uint8(17)
+
+	t.Run("ok", func(t *testing.T) {
+		contract := contractMock.New(
+			contractMock.WithDiluteBatchFunc(func(ctx context.Context, id []byte, newDepth uint8) error {
+				if !bytes.Equal(id, batchOk) {
+					return errors.New("incorrect batch ID in call")
+				}
+				if newDepth != newBatchDepth {
+					return fmt.Errorf("called with wrong depth. wanted %d, got %d", newBatchDepth, newDepth)
+				}
+				return nil
+			}),
+		)
+		ts := newTestServer(t, testServerOptions{
+			PostageContract: contract,
+		})
+
+		jsonhttptest.Request(t, ts.Client, http.MethodPatch, fmt.Sprintf("/stamps/dilute/%s/%d", batchOkStr, newBatchDepth), http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(&debugapi.PostageCreateResponse{
+				BatchID: batchOk,
+			}),
+		)
+	})
+
+	t.Run("depth not increased", func(t *testing.T) {
+		contract := contractMock.New(
+			contractMock.WithDiluteBatchFunc(func(ctx context.Context, id []byte, newDepth uint8) error {
+				return postagecontract.ErrInvalidDepth
+			}),
+		)
+		ts := newTestServer(t, testServerOptions{
+			PostageContract: contract,
+		})
+
+		jsonhttptest.Request(t, ts.Client, http.MethodPatch, fmt.Sprintf("/stamps/dilute/%s/%d", batchOkStr, newBatchDepth), http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(&jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid depth",
+			}),
+		)
+	})
+}
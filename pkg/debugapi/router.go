@@ -59,6 +59,12 @@ func (s *Service) newBasicRouter() *mux.Router {
 		"GET": http.HandlerFunc(s.addressesHandler),
 	})
 
+	if s.stateStore != nil {
+		router.Handle("/db/state/backup", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.stateBackupHandler),
+		})
+	}
+
 	if s.transaction != nil {
 		router.Handle("/transactions", jsonhttp.MethodHandler{
 			"GET": http.HandlerFunc(s.transactionListHandler),
@@ -113,6 +119,15 @@ func (s *Service) newRouter() *mux.Router {
 		"GET":    http.HandlerFunc(s.hasChunkHandler),
 		"DELETE": http.HandlerFunc(s.removeChunk),
 	})
+	router.Handle("/db/verify/{address}", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.verifyHandler),
+	})
+
+	if s.accessStats != nil {
+		router.Handle("/stats/hot", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.hotContentHandler),
+		})
+	}
 	router.Handle("/topology", jsonhttp.MethodHandler{
 		"GET": http.HandlerFunc(s.topologyHandler),
 	})
@@ -204,6 +219,18 @@ func (s *Service) newRouter() *mux.Router {
 		})),
 	)
 
+	router.Handle("/stamps/topup/{id}/{amount}", web.ChainHandlers(
+		web.FinalHandler(jsonhttp.MethodHandler{
+			"PATCH": http.HandlerFunc(s.postageTopUpHandler),
+		})),
+	)
+
+	router.Handle("/stamps/dilute/{id}/{depth}", web.ChainHandlers(
+		web.FinalHandler(jsonhttp.MethodHandler{
+			"PATCH": http.HandlerFunc(s.postageDiluteHandler),
+		})),
+	)
+
 	return router
 }
 
@@ -214,6 +241,7 @@ func (s *Service) setRouter(router http.Handler) {
 		httpaccess.NewHTTPAccessLogHandler(s.logger, logrus.InfoLevel, s.tracer, "debug api access"),
 		handlers.CompressHandler,
 		s.corsHandler,
+		s.restrictHandler,
 		web.NoCacheHeadersHandler,
 		web.FinalHandler(router),
 	))
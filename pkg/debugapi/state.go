@@ -0,0 +1,34 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/statestore/leveldb"
+)
+
+// stateBackupHandler writes the backup of the state store taken from a
+// consistent snapshot while the node runs.
+func (s *Service) stateBackupHandler(w http.ResponseWriter, r *http.Request) {
+	db := s.stateStore.DB()
+	if db == nil {
+		s.logger.Debug("debug api: state backup: state store not persisted")
+		jsonhttp.NotImplemented(w, "state store not persisted")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.Header().Set("Content-Disposition", `attachment; filename="statestore.tar"`)
+	count, err := leveldb.Backup(db, w)
+	if err != nil {
+		// the response status is already written
+		s.logger.Debugf("debug api: state backup: %v", err)
+		s.logger.Error("debug api: state backup")
+		return
+	}
+	s.logger.Debugf("debug api: state backup: %d entries", count)
+}
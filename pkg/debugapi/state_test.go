@@ -0,0 +1,78 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/statestore/leveldb"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+)
+
+func TestStateBackup(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+
+	t.Run("ok", func(t *testing.T) {
+		store, err := leveldb.NewStateStore(t.TempDir(), logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			if err := store.Close(); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if err := store.Put("key", "value"); err != nil {
+			t.Fatal(err)
+		}
+
+		testServer := newTestServer(t, testServerOptions{
+			StateStore: store,
+		})
+
+		var backup []byte
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/db/state/backup", http.StatusOK,
+			jsonhttptest.WithPutResponseBody(&backup),
+		)
+
+		dir := filepath.Join(t.TempDir(), "statestore")
+		if _, err := leveldb.Restore(dir, bytes.NewReader(backup), logger); err != nil {
+			t.Fatal(err)
+		}
+		restored, err := leveldb.NewStateStore(dir, logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer restored.Close()
+
+		var got string
+		if err := restored.Get("key", &got); err != nil {
+			t.Fatal(err)
+		}
+		if got != "value" {
+			t.Fatalf("got value %q, want %q", got, "value")
+		}
+	})
+
+	t.Run("not persisted", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			StateStore: statestore.NewStateStore(),
+		})
+
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/db/state/backup", http.StatusNotImplemented,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "state store not persisted",
+				Code:    http.StatusNotImplemented,
+			}),
+		)
+	})
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/encryption"
+	"github.com/ethersphere/bee/pkg/integrity"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+type verifyResponse struct {
+	OK         bool            `json:"ok"`
+	Total      int             `json:"total"`
+	Corrupt    []swarm.Address `json:"corrupt"`
+	Missing    []swarm.Address `json:"missing"`
+	Unstamped  []swarm.Address `json:"unstamped"`
+	Incomplete bool            `json:"incomplete"`
+}
+
+// verifyHandler checks that the content under the given address is fully
+// present and intact in the local store.
+func (s *Service) verifyHandler(w http.ResponseWriter, r *http.Request) {
+	addr, err := swarm.ParseHexAddress(mux.Vars(r)["address"])
+	if err != nil {
+		s.logger.Debugf("debug api: verify: parse address: %v", err)
+		jsonhttp.BadRequest(w, "bad address")
+		return
+	}
+	if l := len(addr.Bytes()); l != swarm.HashSize && l != encryption.ReferenceSize {
+		s.logger.Debugf("debug api: verify: invalid address length %d", l)
+		jsonhttp.BadRequest(w, "bad address")
+		return
+	}
+
+	report, err := integrity.New(s.storer, postage.ValidStamp(s.batchStore)).Verify(r.Context(), addr)
+	if err != nil {
+		if errors.Is(err, storage.ErrReferenceLength) {
+			s.logger.Debugf("debug api: verify %s: %v", addr, err)
+			jsonhttp.BadRequest(w, "bad address")
+			return
+		}
+		s.logger.Debugf("debug api: verify %s: %v", addr, err)
+		s.logger.Errorf("debug api: verify %s", addr)
+		jsonhttp.InternalServerError(w, nil)
+		return
+	}
+
+	jsonhttp.OK(w, verifyResponse{
+		OK:         report.OK(),
+		Total:      report.Total,
+		Corrupt:    report.Corrupt,
+		Missing:    report.Missing,
+		Unstamped:  report.Unstamped,
+		Incomplete: report.Incomplete,
+	})
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	mockbatchstore "github.com/ethersphere/bee/pkg/postage/batchstore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	testingc "github.com/ethersphere/bee/pkg/storage/testing"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestVerifyHandler(t *testing.T) {
+	mockStorer := mock.NewStorer()
+	testServer := newTestServer(t, testServerOptions{
+		Storer:     mockStorer,
+		BatchStore: mockbatchstore.New(),
+	})
+
+	// the batch of the stamp of the chunk is not known
+	ch := testingc.GenerateTestRandomChunk()
+	_, err := mockStorer.Put(context.Background(), storage.ModePutUpload, ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("unstamped", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/db/verify/"+ch.Address().String(), http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(debugapi.VerifyResponse{
+				OK:        false,
+				Total:     1,
+				Corrupt:   []swarm.Address{},
+				Missing:   []swarm.Address{},
+				Unstamped: []swarm.Address{ch.Address()},
+			}),
+		)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		addr := swarm.MustParseHexAddress("f4a1b9c1d6a6c41bda3e3c0e2e1c7e1c2c0e6d8fbd8d7b0a4c9b6f04a2bc3d5e")
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/db/verify/"+addr.String(), http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(debugapi.VerifyResponse{
+				OK:         false,
+				Total:      1,
+				Corrupt:    []swarm.Address{},
+				Missing:    []swarm.Address{addr},
+				Unstamped:  []swarm.Address{},
+				Incomplete: true,
+			}),
+		)
+	})
+
+	t.Run("bad address length", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/db/verify/aabbcc", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "bad address",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("bad address", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/db/verify/abcd1100zz", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "bad address",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+}
@@ -5,6 +5,7 @@
 package epochs
 
 import (
+	"bytes"
 	"context"
 	"errors"
 
@@ -16,6 +17,10 @@ import (
 var _ feeds.Lookup = (*finder)(nil)
 var _ feeds.Lookup = (*asyncFinder)(nil)
 
+// errEpochNotFound is returned if the update chunk found is not placed
+// in any of the epochs spanning its timestamp
+var errEpochNotFound = errors.New("epoch not found")
+
 // finder encapsulates a chunk store getter and a feed and provides
 //  non-concurrent lookup methods
 type finder struct {
@@ -35,7 +40,35 @@ func (f *finder) At(ctx context.Context, at, after int64) (swarm.Chunk, feeds.In
 		return nil, nil, nil, err
 	}
 	ch, err = f.at(ctx, uint64(at), e, ch)
-	return ch, nil, nil, err
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return indices(f.getter.Topic, ch, at)
+}
+
+// indices returns the epoch of the update chunk found looking up time `at`
+// and the epoch of the next update at that time
+func indices(topic []byte, ch swarm.Chunk, at int64) (swarm.Chunk, feeds.Index, feeds.Index, error) {
+	if ch == nil {
+		return nil, nil, &epoch{0, maxLevel}, nil
+	}
+	ts, err := feeds.UpdatedAt(ch)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// the update is placed in one of the epochs spanning its timestamp
+	for level := uint8(0); level <= maxLevel; level++ {
+		length := uint64(1) << level
+		e := &epoch{ts / length * length, level}
+		id, err := feeds.Id(topic, e)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if bytes.Equal(id, ch.Data()[:swarm.HashSize]) {
+			return ch, e, e.Next(int64(ts), uint64(at)), nil
+		}
+	}
+	return nil, nil, nil, errEpochNotFound
 }
 
 // common returns the lowest common ancestor for which a feed update chunk is found in the chunk store
@@ -166,9 +199,11 @@ func (f *asyncFinder) at(ctx context.Context, at int64, p *path, e *epoch, c cha
 	}
 }
 func (f *asyncFinder) At(ctx context.Context, at, after int64) (swarm.Chunk, feeds.Index, feeds.Index, error) {
-	// TODO: current and next index return values need to be implemented
 	ch, err := f.asyncAt(ctx, at, after)
-	return ch, nil, nil, err
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return indices(f.getter.Topic, ch, at)
 }
 
 // At looks up the version valid at time `at`
@@ -0,0 +1,103 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package epochs
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/ethersphere/bee/pkg/feeds"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+var _ feeds.History = (*history)(nil)
+
+// history walks the epoch tree to collect the updates of a feed
+type history struct {
+	getter *feeds.Getter
+}
+
+// NewHistory constructs a feeds.History for epoch based feeds
+func NewHistory(getter storage.Getter, feed *feeds.Feed) feeds.History {
+	return &history{feeds.NewGetter(getter, feed)}
+}
+
+// Updates returns the updates between from and to, the latest first
+// the updater places every update in a child of an epoch that already
+// has an update, so the epochs with updates form a subtree under the
+// toplevel epoch and the walk does not descend below missing epochs
+func (h *history) Updates(ctx context.Context, from, to int64, limit int) ([]feeds.Entry, error) {
+	c := &collector{limit: limit}
+	if err := h.walk(ctx, uint64(from), uint64(to), &epoch{0, maxLevel}, c); err != nil {
+		return nil, err
+	}
+	return c.entries, nil
+}
+
+// walk collects the updates in the subtree of the epoch that
+// fall between from and to
+func (h *history) walk(ctx context.Context, from, to uint64, e *epoch, c *collector) error {
+	// updates are placed in an epoch that spans the time of the update
+	if e.start > to || e.start+e.length() <= from {
+		return nil
+	}
+	// the subtree can not have updates later than the ones collected
+	if c.full() && e.start+e.length()-1 <= c.oldest() {
+		return nil
+	}
+	ch, err := h.getter.Get(ctx, e)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	ts, err := feeds.UpdatedAt(ch)
+	if err != nil {
+		return err
+	}
+	if ts >= from && ts <= to {
+		c.add(feeds.Entry{Index: e, Timestamp: ts, Chunk: ch})
+	}
+	if e.level == 0 {
+		return nil
+	}
+	// the right child is walked first as it holds the later updates
+	left := &epoch{e.start, e.level - 1}
+	right := &epoch{e.start | left.length(), e.level - 1}
+	if err := h.walk(ctx, from, to, right, c); err != nil {
+		return err
+	}
+	return h.walk(ctx, from, to, left, c)
+}
+
+// collector keeps at most limit of the latest updates, the latest first,
+// unlimited if limit is not positive
+type collector struct {
+	limit   int
+	entries []feeds.Entry
+}
+
+func (c *collector) add(e feeds.Entry) {
+	i := sort.Search(len(c.entries), func(i int) bool {
+		return c.entries[i].Timestamp < e.Timestamp
+	})
+	c.entries = append(c.entries, feeds.Entry{})
+	copy(c.entries[i+1:], c.entries[i:])
+	c.entries[i] = e
+	if c.limit > 0 && len(c.entries) > c.limit {
+		c.entries = c.entries[:c.limit]
+	}
+}
+
+func (c *collector) full() bool {
+	return c.limit > 0 && len(c.entries) == c.limit
+}
+
+// oldest returns the timestamp of the earliest update kept
+func (c *collector) oldest() uint64 {
+	return c.entries[len(c.entries)-1].Timestamp
+}
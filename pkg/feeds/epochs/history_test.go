@@ -0,0 +1,16 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package epochs_test
+
+import (
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/feeds/epochs"
+	feedstesting "github.com/ethersphere/bee/pkg/feeds/testing"
+)
+
+func TestHistory(t *testing.T) {
+	feedstesting.TestHistory(t, epochs.NewHistory, epochs.NewUpdater)
+}
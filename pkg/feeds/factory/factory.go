@@ -29,3 +29,14 @@ func (f *factory) NewLookup(t feeds.Type, feed *feeds.Feed) (feeds.Lookup, error
 
 	return nil, feeds.ErrFeedTypeNotFound
 }
+
+func (f *factory) NewHistory(t feeds.Type, feed *feeds.Feed) (feeds.History, error) {
+	switch t {
+	case feeds.Sequence:
+		return sequence.NewHistory(f.Getter, feed), nil
+	case feeds.Epoch:
+		return epochs.NewHistory(f.Getter, feed), nil
+	}
+
+	return nil, feeds.ErrFeedTypeNotFound
+}
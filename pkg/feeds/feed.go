@@ -26,6 +26,7 @@ var ErrFeedTypeNotFound = errors.New("no such feed type")
 // Factory creates feed lookups for different types of feeds.
 type Factory interface {
 	NewLookup(Type, *Feed) (Lookup, error)
+	NewHistory(Type, *Feed) (History, error)
 }
 
 // Type enumerates the time-based feed types
@@ -20,6 +20,21 @@ type Lookup interface {
 	At(ctx context.Context, at, after int64) (chunk swarm.Chunk, currentIndex, nextIndex Index, err error)
 }
 
+// Entry is an update found when walking the history of a feed.
+type Entry struct {
+	Index     Index
+	Timestamp uint64
+	Chunk     swarm.Chunk
+}
+
+// History is the interface for walking the updates of a feed
+type History interface {
+	// Updates returns at most limit updates with timestamps between
+	// from and to inclusive, the latest update first. All updates are
+	// returned if limit is zero.
+	Updates(ctx context.Context, from, to int64, limit int) ([]Entry, error)
+}
+
 // Getter encapsulates a chunk Getter getter and a feed and provides
 //  non-concurrent lookup methods
 type Getter struct {
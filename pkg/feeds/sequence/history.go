@@ -0,0 +1,66 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sequence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethersphere/bee/pkg/feeds"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+var _ feeds.History = (*history)(nil)
+
+// history finds the latest update with the async finder and
+// walks the preceding indexes down to the first update
+type history struct {
+	finder feeds.Lookup
+	getter *feeds.Getter
+}
+
+// NewHistory constructs a feeds.History for sequence feeds
+func NewHistory(getter storage.Getter, feed *feeds.Feed) feeds.History {
+	return &history{
+		finder: NewAsyncFinder(getter, feed),
+		getter: feeds.NewGetter(getter, feed),
+	}
+}
+
+// Updates returns the updates between from and to, the latest first
+func (h *history) Updates(ctx context.Context, from, to int64, limit int) ([]feeds.Entry, error) {
+	ch, cur, _, err := h.finder.At(ctx, to, 0)
+	if err != nil {
+		return nil, err
+	}
+	if ch == nil || cur == nil {
+		return nil, nil
+	}
+
+	i := cur.(*index).index
+	var entries []feeds.Entry
+	for {
+		ts, err := feeds.UpdatedAt(ch)
+		if err != nil {
+			return nil, err
+		}
+		if ts < uint64(from) {
+			return entries, nil
+		}
+		entries = append(entries, feeds.Entry{Index: &index{i}, Timestamp: ts, Chunk: ch})
+		if len(entries) == limit || i == 0 {
+			return entries, nil
+		}
+		i--
+		ch, err = h.getter.Get(ctx, &index{i})
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				// the earlier updates are not retrievable
+				return entries, nil
+			}
+			return nil, err
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -206,3 +207,119 @@ func TestFinderRandomIntervals(t *testing.T, finderf func(storage.Getter, *feeds
 		})
 	}
 }
+
+func TestHistory(t *testing.T, historyf func(storage.Getter, *feeds.Feed) feeds.History, updaterf func(putter storage.Putter, signer crypto.Signer, topic []byte) (feeds.Updater, error)) {
+	storer := mock.NewStorer()
+	topic, err := crypto.LegacyKeccak256([]byte("testtopic"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, _ := crypto.GenerateSecp256k1Key()
+	signer := crypto.NewDefaultSigner(pk)
+
+	updater, err := updaterf(storer, signer, topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	history := historyf(storer, updater.Feed())
+
+	ctx := context.Background()
+	t.Run("no update", func(t *testing.T) {
+		entries, err := history.Updates(ctx, 0, time.Now().Unix(), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 0 {
+			t.Fatalf("expected no updates, got %d", len(entries))
+		}
+	})
+
+	var ats []int64
+	at := time.Now().Unix()
+	for i := 0; i < 20; i++ {
+		at += int64(rand.Intn(1<<10) + 1) // skipcq: GSC-G404
+		ats = append(ats, at)
+		payload := make([]byte, 8)
+		binary.BigEndian.PutUint64(payload, uint64(at))
+		if err := updater.Update(ctx, at, payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	check := func(t *testing.T, entries []feeds.Entry, want []int64) {
+		t.Helper()
+		if len(entries) != len(want) {
+			t.Fatalf("got %d updates, want %d", len(entries), len(want))
+		}
+		for i, e := range entries {
+			// the latest update comes first
+			at := want[len(want)-1-i]
+			if e.Timestamp != uint64(at) {
+				t.Fatalf("update %d: timestamp mismatch: expected %v, got %v", i, at, e.Timestamp)
+			}
+			_, payload, err := feeds.FromChunk(e.Chunk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if content := binary.BigEndian.Uint64(payload); content != uint64(at) {
+				t.Fatalf("update %d: payload mismatch: expected %v, got %v", i, at, content)
+			}
+			id, err := feeds.Id(topic, e.Index)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(id, e.Chunk.Data()[:32]) {
+				t.Fatalf("update %d: index mismatch", i)
+			}
+		}
+	}
+
+	t.Run("all", func(t *testing.T) {
+		entries, err := history.Updates(ctx, 0, ats[len(ats)-1], 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, entries, ats)
+	})
+	t.Run("range", func(t *testing.T) {
+		entries, err := history.Updates(ctx, ats[5], ats[14], 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, entries, ats[5:15])
+	})
+	t.Run("limit", func(t *testing.T) {
+		entries, err := history.Updates(ctx, 0, ats[len(ats)-1], 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, entries, ats[len(ats)-3:])
+	})
+	t.Run("limit bounds retrievals", func(t *testing.T) {
+		gets := func(limit int) int32 {
+			counter := &counting{Storer: storer}
+			entries, err := historyf(counter, updater.Feed()).Updates(ctx, 0, ats[len(ats)-1], limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if limit > 0 {
+				check(t, entries, ats[len(ats)-limit:])
+			}
+			return atomic.LoadInt32(&counter.gets)
+		}
+		if limited, all := gets(1), gets(0); limited >= all {
+			t.Fatalf("got %d retrievals with limit, want less than %d without", limited, all)
+		}
+	})
+}
+
+// counting counts the retrievals from the storer
+type counting struct {
+	storage.Storer
+	gets int32
+}
+
+func (c *counting) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address) (swarm.Chunk, error) {
+	atomic.AddInt32(&c.gets, 1)
+	return c.Storer.Get(ctx, mode, addr)
+}
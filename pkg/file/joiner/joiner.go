@@ -74,7 +74,7 @@ func (j *joiner) ReadAt(b []byte, off int64) (read int, err error) {
 		return 0, io.EOF
 	}
 
-	readLen := int64(cap(b))
+	readLen := int64(len(b))
 	if readLen > j.span-off {
 		readLen = j.span - off
 	}
@@ -0,0 +1,198 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package integrity provides verification of the integrity
+// of the content stored in the local store.
+package integrity
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/cac"
+	"github.com/ethersphere/bee/pkg/soc"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/traversal"
+)
+
+var (
+	// errReadOnly is returned when chunks are stored through the getter
+	// used to verify the content.
+	errReadOnly = errors.New("read only")
+	// errCorrupt is returned to the traverser for chunks whose data does
+	// not match their address.
+	errCorrupt = errors.New("corrupt chunk")
+)
+
+// Report holds the results of the verification of content.
+type Report struct {
+	// Total is the number of chunks verified.
+	Total int
+	// Corrupt are the chunks whose data does not match their address.
+	Corrupt []swarm.Address
+	// Missing are the chunks not found in the local store.
+	Missing []swarm.Address
+	// Unstamped are the chunks without a valid postage stamp.
+	Unstamped []swarm.Address
+	// Incomplete reports whether parts of the content could not be
+	// verified as they are referenced by missing or corrupt chunks.
+	Incomplete bool
+}
+
+// OK reports whether all the content was verified and all the verified
+// chunks are present and intact.
+func (r *Report) OK() bool {
+	return !r.Incomplete && len(r.Corrupt) == 0 && len(r.Missing) == 0 && len(r.Unstamped) == 0
+}
+
+type Verifier interface {
+	// Verify traverses the content with the given root hash in the local
+	// store and checks the hash and the postage stamp of every chunk.
+	Verify(context.Context, swarm.Address) (*Report, error)
+}
+
+type verifier struct {
+	store      storage.Getter
+	validStamp func(swarm.Chunk, []byte) (swarm.Chunk, error)
+}
+
+// New constructs a Verifier reading the chunks from the given store and
+// validating their postage stamps with the validStamp function.
+func New(store storage.Getter, validStamp func(swarm.Chunk, []byte) (swarm.Chunk, error)) Verifier {
+	return &verifier{store: store, validStamp: validStamp}
+}
+
+// Verify implements the Verifier interface. Traversal continues past broken
+// chunks, but the chunks below a missing or corrupt intermediate chunk or
+// manifest node can not be discovered, and the report is marked incomplete.
+func (v *verifier) Verify(ctx context.Context, root swarm.Address) (*Report, error) {
+	g := &checkingGetter{
+		verifier: v,
+		checked:  make(map[string]struct{}),
+	}
+
+	err := traversal.NewTolerant(g).Traverse(ctx, root, func(addr swarm.Address) error {
+		g.check(ctx, addr)
+		return nil
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil && !errors.Is(err, traversal.ErrIncomplete) {
+		return nil, fmt.Errorf("traversal of %s failed: %w", root.String(), err)
+	}
+
+	r := g.report()
+	r.Incomplete = err != nil
+	return r, nil
+}
+
+// checkingGetter verifies every chunk it gets from the local store
+// and keeps track of the chunks that are broken.
+type checkingGetter struct {
+	*verifier
+	mu        sync.Mutex
+	checked   map[string]struct{}
+	corrupt   []swarm.Address
+	missing   []swarm.Address
+	unstamped []swarm.Address
+}
+
+// Get implements the storage.Getter interface.
+func (g *checkingGetter) Get(ctx context.Context, _ storage.ModeGet, addr swarm.Address) (swarm.Chunk, error) {
+	ch, err := g.store.Get(ctx, storage.ModeGetLookup, addr)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		if errors.Is(err, storage.ErrNotFound) {
+			g.record(addr, &g.missing)
+		} else {
+			// the store fails to decode the chunk
+			g.record(addr, &g.corrupt)
+		}
+		return nil, err
+	}
+
+	if !cac.Valid(ch) && !soc.Valid(ch) {
+		g.record(addr, &g.corrupt)
+		return nil, errCorrupt
+	}
+
+	if !g.validStamped(ch) {
+		g.record(addr, &g.unstamped)
+	} else {
+		g.record(addr, nil)
+	}
+	return ch, nil
+}
+
+// Put implements the storage.Putter interface. Nothing is stored as the
+// content is only read.
+func (g *checkingGetter) Put(_ context.Context, _ storage.ModePut, _ ...swarm.Chunk) ([]bool, error) {
+	return nil, errReadOnly
+}
+
+// check verifies the chunk unless it has already been verified.
+func (g *checkingGetter) check(ctx context.Context, addr swarm.Address) {
+	g.mu.Lock()
+	_, checked := g.checked[addr.ByteString()]
+	g.mu.Unlock()
+	if checked {
+		return
+	}
+	_, _ = g.Get(ctx, storage.ModeGetLookup, addr)
+}
+
+func (g *checkingGetter) validStamped(ch swarm.Chunk) bool {
+	if ch.Stamp() == nil {
+		return false
+	}
+	stamp, err := ch.Stamp().MarshalBinary()
+	if err != nil {
+		return false
+	}
+	_, err = g.validStamp(ch, stamp)
+	return err == nil
+}
+
+// record marks the chunk as verified and adds it to the list
+// unless it has already been verified.
+func (g *checkingGetter) record(addr swarm.Address, list *[]swarm.Address) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.checked[addr.ByteString()]; ok {
+		return
+	}
+	g.checked[addr.ByteString()] = struct{}{}
+	if list != nil {
+		*list = append(*list, addr)
+	}
+}
+
+func (g *checkingGetter) report() *Report {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return &Report{
+		Total:     len(g.checked),
+		Corrupt:   sorted(g.corrupt),
+		Missing:   sorted(g.missing),
+		Unstamped: sorted(g.unstamped),
+	}
+}
+
+// sorted returns the addresses in a stable order.
+func sorted(addrs []swarm.Address) []swarm.Address {
+	s := make([]swarm.Address, len(addrs))
+	copy(s, addrs)
+	sort.Slice(s, func(i, j int) bool {
+		return bytes.Compare(s[i].Bytes(), s[j].Bytes()) < 0
+	})
+	return s
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package integrity_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/file/pipeline/builder"
+	"github.com/ethersphere/bee/pkg/integrity"
+	postagetesting "github.com/ethersphere/bee/pkg/postage/testing"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestVerify(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		data  = make([]byte, 300*swarm.ChunkSize)
+		store = mock.NewStorer()
+	)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &stampingStore{Storer: store}
+	pipe := builder.NewPipelineBuilder(ctx, s, storage.ModePutUpload, false)
+	root, err := builder.FeedPipeline(ctx, pipe, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first chunks stored by the pipeline are data chunks, the first
+	// one is kept intact as its header tells whether the root is a manifest
+	var (
+		unstamped = s.addrs[0]
+		missing   = s.addrs[1]
+		corrupt   = s.addrs[2]
+	)
+	validStamp := func(ch swarm.Chunk, _ []byte) (swarm.Chunk, error) {
+		if ch.Address().Equal(unstamped) {
+			return nil, errors.New("invalid stamp")
+		}
+		return ch, nil
+	}
+
+	t.Run("intact", func(t *testing.T) {
+		r, err := integrity.New(store, validStamp).Verify(ctx, root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(r.Unstamped) != 1 || !r.Unstamped[0].Equal(unstamped) {
+			t.Fatalf("got unstamped chunks %v, want %v", r.Unstamped, unstamped)
+		}
+		if len(r.Missing) != 0 || len(r.Corrupt) != 0 {
+			t.Fatalf("got %d missing and %d corrupt chunks, want none", len(r.Missing), len(r.Corrupt))
+		}
+		if r.Total != len(s.addrs) {
+			t.Fatalf("got %d verified chunks, want %d", r.Total, len(s.addrs))
+		}
+		if r.Incomplete {
+			t.Fatal("got incomplete verification")
+		}
+	})
+
+	t.Run("broken", func(t *testing.T) {
+		b := &brokenStore{Getter: store, missing: missing, corrupt: corrupt}
+		r, err := integrity.New(b, validStamp).Verify(ctx, root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.OK() {
+			t.Fatal("expected broken content")
+		}
+		if len(r.Missing) != 1 || !r.Missing[0].Equal(missing) {
+			t.Fatalf("got missing chunks %v, want %v", r.Missing, missing)
+		}
+		if len(r.Corrupt) != 1 || !r.Corrupt[0].Equal(corrupt) {
+			t.Fatalf("got corrupt chunks %v, want %v", r.Corrupt, corrupt)
+		}
+		// the broken data chunks do not hide the rest of the content
+		if r.Total != len(s.addrs) || r.Incomplete {
+			t.Fatalf("got %d verified chunks and incomplete %t, want %d and complete", r.Total, r.Incomplete, len(s.addrs))
+		}
+	})
+
+	t.Run("broken intermediate chunk", func(t *testing.T) {
+		// the intermediate chunk of the first data chunks follows them
+		intermediate := s.addrs[swarm.Branches]
+		b := &brokenStore{Getter: store, missing: intermediate, corrupt: swarm.ZeroAddress}
+		r, err := integrity.New(b, validStamp).Verify(ctx, root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !r.Incomplete || r.OK() {
+			t.Fatal("got complete verification")
+		}
+		if len(r.Missing) != 1 || !r.Missing[0].Equal(intermediate) {
+			t.Fatalf("got missing chunks %v, want %v", r.Missing, intermediate)
+		}
+	})
+
+	t.Run("missing root", func(t *testing.T) {
+		b := &brokenStore{Getter: store, missing: root, corrupt: swarm.ZeroAddress}
+		r, err := integrity.New(b, validStamp).Verify(ctx, root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(r.Missing) != 1 || !r.Missing[0].Equal(root) {
+			t.Fatalf("got missing chunks %v, want %v", r.Missing, root)
+		}
+		if !r.Incomplete {
+			t.Fatal("got complete verification")
+		}
+	})
+}
+
+// stampingStore attaches a postage stamp to the stored chunks and records
+// their addresses.
+type stampingStore struct {
+	storage.Storer
+	addrs []swarm.Address
+}
+
+func (s *stampingStore) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk) ([]bool, error) {
+	for i, c := range chs {
+		s.addrs = append(s.addrs, c.Address())
+		chs[i] = c.WithStamp(postagetesting.MustNewStamp())
+	}
+	return s.Storer.Put(ctx, mode, chs...)
+}
+
+// brokenStore pretends to have lost one chunk and to have
+// the data of another one corrupted.
+type brokenStore struct {
+	storage.Getter
+	missing swarm.Address
+	corrupt swarm.Address
+}
+
+func (b *brokenStore) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address) (swarm.Chunk, error) {
+	if addr.Equal(b.missing) {
+		return nil, storage.ErrNotFound
+	}
+	ch, err := b.Getter.Get(ctx, mode, addr)
+	if err != nil {
+		return nil, err
+	}
+	if addr.Equal(b.corrupt) {
+		data := make([]byte, len(ch.Data()))
+		copy(data, ch.Data())
+		data[len(data)-1] ^= 0xff
+		return swarm.NewChunk(addr, data).WithStamp(ch.Stamp()), nil
+	}
+	return ch, nil
+}
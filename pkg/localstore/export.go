@@ -18,202 +18,603 @@ package localstore
 
 import (
 	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"sort"
+	"strings"
 	"sync"
 
+	"github.com/ethersphere/bee/pkg/cac"
 	"github.com/ethersphere/bee/pkg/postage"
 	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/soc"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/traversal"
+	"github.com/klauspost/compress/zstd"
 )
 
 const (
 	// filename in tar archive that holds the information
 	// about exported data format version
 	exportVersionFilename = ".swarm-export-version"
+	// filename in tar archive that holds the export manifest,
+	// it is written after all the chunks
+	exportManifestFilename = ".swarm-export-manifest"
+	// filename in tar archive that holds the checksums of the
+	// files written since the previous one, a line with the name
+	// and the hex encoded sha256 checksum per file
+	exportChecksumsFilename = ".swarm-export-checksums"
+	// pax record of the tar headers with the sha256 checksum
+	// of the file
+	exportChecksumRecord = "SWARM.sha256"
 	// current export format version
-	currentExportVersion = "3"
+	currentExportVersion = "5"
+	// export format version without the checksums
+	exportVersionV4 = "4"
+	// export format version without the export manifest
+	exportVersionV3 = "3"
 )
 
+// Compressions of the export archive.
+const (
+	CompressionNone = ""
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+var (
+	// ErrUnsupportedCompression is returned when exporting with or
+	// importing an archive of an unsupported compression.
+	ErrUnsupportedCompression = errors.New("unsupported compression")
+
+	// exportChecksumsInterval is the number of files written
+	// between the checksums files of the archive.
+	exportChecksumsInterval = 1024
+
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// ExportOptions select the chunks to be exported. All the set options
+// apply, the zero value selects all the chunks.
+type ExportOptions struct {
+	// Roots selects the chunks of the content under the root references.
+	Roots []swarm.Address
+	// Pinned selects only the pinned chunks.
+	Pinned bool
+	// Since selects only the chunks stored after the bin IDs of the
+	// proximity order bins. The BinIDs of the manifest of a previous
+	// export can be used to export incrementally.
+	Since map[uint8]uint64
+	// Compression of the archive, CompressionNone, CompressionGzip
+	// or CompressionZstd.
+	Compression string
+}
+
+// ExportManifest describes the content of an export, it is included in
+// the archive.
+type ExportManifest struct {
+	Roots  []swarm.Address  `json:"roots,omitempty"`
+	Pinned bool             `json:"pinned,omitempty"`
+	Since  map[uint8]uint64 `json:"since,omitempty"`
+	// BinIDs are the last bin IDs of the database when the export started.
+	BinIDs map[uint8]uint64 `json:"binIDs"`
+	// Stamps are the numbers of the exported chunks per postage batch ID.
+	Stamps map[string]int64 `json:"stamps"`
+	Chunks int64            `json:"chunks"`
+}
+
 // Export writes a tar structured data to the writer of
 // all chunks in the retrieval data index. It returns the
 // number of chunks exported.
 func (db *DB) Export(w io.Writer) (count int64, err error) {
-	tw := tar.NewWriter(w)
-	defer tw.Close()
+	return db.ExportSelected(context.Background(), w, ExportOptions{})
+}
 
-	if err := tw.WriteHeader(&tar.Header{
-		Name: exportVersionFilename,
-		Mode: 0644,
-		Size: int64(len(currentExportVersion)),
-	}); err != nil {
-		return 0, err
+// ExportSelected writes a tar structured data to the writer of the chunks
+// selected by the options, followed by the export manifest. The checksums
+// of the files are written in between them every exportChecksumsInterval
+// files and at the end. It returns the number of chunks exported.
+func (db *DB) ExportSelected(ctx context.Context, w io.Writer, o ExportOptions) (count int64, err error) {
+	var cw io.WriteCloser
+	switch o.Compression {
+	case CompressionNone:
+	case CompressionGzip:
+		cw = gzip.NewWriter(w)
+		w = cw
+	case CompressionZstd:
+		if cw, err = zstd.NewWriter(w); err != nil {
+			return 0, err
+		}
+		w = cw
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnsupportedCompression, o.Compression)
 	}
-	if _, err := tw.Write([]byte(currentExportVersion)); err != nil {
+
+	ew := &exportWriter{tw: tar.NewWriter(w)}
+
+	if err := ew.write(exportVersionFilename, []byte(currentExportVersion)); err != nil {
 		return 0, err
 	}
 
-	err = db.retrievalDataIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+	m := ExportManifest{
+		Roots:  o.Roots,
+		Pinned: o.Pinned,
+		Since:  o.Since,
+		BinIDs: make(map[uint8]uint64),
+		Stamps: make(map[string]int64),
+	}
+	for bin := uint8(0); bin <= swarm.MaxPO; bin++ {
+		id, err := db.binIDs.Get(uint64(bin))
+		if err != nil {
+			return 0, fmt.Errorf("get bin id: %w", err)
+		}
+		if id > 0 {
+			m.BinIDs[bin] = id
+		}
+	}
+
+	err = db.iterateExport(ctx, o, func(item shed.Item) error {
+		err := ew.write(hex.EncodeToString(item.Address), item.BatchID, item.Index, item.Timestamp, item.Sig, item.Data)
+		if err != nil {
+			return err
+		}
+		m.Stamps[hex.EncodeToString(item.BatchID)]++
+		m.Chunks++
+		return nil
+	})
+	if err != nil {
+		return m.Chunks, err
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return m.Chunks, err
+	}
+	if err := ew.write(exportManifestFilename, data); err != nil {
+		return m.Chunks, err
+	}
+	if err := ew.flush(); err != nil {
+		return m.Chunks, err
+	}
 
-		hdr := &tar.Header{
-			Name: hex.EncodeToString(item.Address),
-			Mode: 0644,
-			Size: int64(postage.StampSize + len(item.Data)),
+	if err := ew.tw.Close(); err != nil {
+		return m.Chunks, err
+	}
+	if cw != nil {
+		if err := cw.Close(); err != nil {
+			return m.Chunks, err
 		}
+	}
+	return m.Chunks, nil
+}
 
-		if err := tw.WriteHeader(hdr); err != nil {
-			return false, err
+// iterateExport calls the function with the retrieval data index items of
+// the chunks selected by the options.
+func (db *DB) iterateExport(ctx context.Context, o ExportOptions, fn func(shed.Item) error) error {
+	// chunks stored after the since bin ids are looked up in the pull index
+	// as the proximity order of an address can not be computed without
+	// the base key, which the database may be opened without
+	var stored map[string]struct{}
+	if o.Since != nil && (len(o.Roots) > 0 || o.Pinned) {
+		stored = make(map[string]struct{})
+		if err := db.iterateStoredSince(o.Since, func(addr []byte) error {
+			stored[string(addr)] = struct{}{}
+			return nil
+		}); err != nil {
+			return err
 		}
-		if _, err := tw.Write(item.BatchID); err != nil {
-			return false, err
+	}
+
+	// export calls the function with the item of the address
+	// if the address is selected by the options
+	export := func(addr []byte) error {
+		if err := ctx.Err(); err != nil {
+			return err
 		}
-		if _, err := tw.Write(item.Index); err != nil {
-			return false, err
+		if stored != nil {
+			if _, ok := stored[string(addr)]; !ok {
+				return nil
+			}
+		}
+		if o.Pinned {
+			has, err := db.pinIndex.Has(shed.Item{Address: addr})
+			if err != nil {
+				return err
+			}
+			if !has {
+				return nil
+			}
+		}
+		item, err := db.retrievalDataIndex.Get(shed.Item{Address: addr})
+		if err != nil {
+			return fmt.Errorf("get chunk %x: %w", addr, err)
+		}
+		return fn(item)
+	}
+
+	switch {
+	case len(o.Roots) > 0:
+		t := traversal.New(db)
+		seen := make(map[string]struct{})
+		for _, root := range o.Roots {
+			err := t.Traverse(ctx, root, func(addr swarm.Address) error {
+				if _, ok := seen[addr.ByteString()]; ok {
+					return nil
+				}
+				seen[addr.ByteString()] = struct{}{}
+				return export(addr.Bytes())
+			})
+			if err != nil {
+				return fmt.Errorf("traverse %s: %w", root, err)
+			}
+		}
+		return nil
+	case o.Pinned:
+		return db.pinIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			return false, export(item.Address)
+		}, nil)
+	case o.Since != nil:
+		return db.iterateStoredSince(o.Since, export)
+	default:
+		return db.retrievalDataIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			if err := ctx.Err(); err != nil {
+				return true, err
+			}
+			return false, fn(item)
+		}, nil)
+	}
+}
+
+// iterateStoredSince calls the function with the addresses of the chunks
+// stored after the bin IDs of their proximity order bins.
+func (db *DB) iterateStoredSince(since map[uint8]uint64, fn func(addr []byte) error) error {
+	for bin := uint8(0); bin <= swarm.MaxPO; bin++ {
+		err := db.pullIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			if item.BinID <= since[bin] {
+				return false, nil
+			}
+			return false, fn(item.Address)
+		}, &shed.IterateOptions{
+			Prefix: []byte{bin},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportWriter writes the files of the tar archive with their sha256
+// checksums in the pax records, and the checksums files listing them.
+type exportWriter struct {
+	tw        *tar.Writer
+	checksums bytes.Buffer // lines of the files since the last checksums file
+	count     int          // number of the files since the last checksums file
+}
+
+// write writes the file with the content of the concatenated parts.
+func (w *exportWriter) write(name string, parts ...[]byte) error {
+	h := sha256.New()
+	var size int64
+	for _, p := range parts {
+		_, _ = h.Write(p)
+		size += int64(len(p))
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+
+	if err := w.tw.WriteHeader(&tar.Header{
+		Name:       name,
+		Mode:       0644,
+		Size:       size,
+		PAXRecords: map[string]string{exportChecksumRecord: sum},
+	}); err != nil {
+		return err
+	}
+	for _, p := range parts {
+		if _, err := w.tw.Write(p); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(&w.checksums, "%s %s\n", name, sum)
+	w.count++
+	if w.count >= exportChecksumsInterval {
+		return w.flush()
+	}
+	return nil
+}
+
+// flush writes the checksums file of the files written since the last one.
+func (w *exportWriter) flush() error {
+	if w.count == 0 {
+		return nil
+	}
+	if err := writeExportFile(w.tw, exportChecksumsFilename, w.checksums.Bytes()); err != nil {
+		return err
+	}
+	w.checksums.Reset()
+	w.count = 0
+	return nil
+}
+
+func writeExportFile(tw *tar.Writer, name string, data []byte) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name: name,
+		Mode: 0644,
+		Size: int64(len(data)),
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// exportReader returns the reader of the tar structured data of the
+// export, decompressing it if the export is compressed. The reader
+// must be closed to release the resources of the decompression.
+func exportReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(magic, zstdMagic):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
 		}
-		if _, err := tw.Write(item.Timestamp); err != nil {
-			return false, err
+		return zr.IOReadCloser(), nil
+	}
+	return ioutil.NopCloser(br), nil
+}
+
+// ReadExportManifest returns the manifest of the export read from the tar
+// structured data, or nil if the export does not include one.
+func ReadExportManifest(r io.Reader) (*ExportManifest, error) {
+	er, err := exportReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer er.Close()
+	tr := tar.NewReader(er)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil, nil
+			}
+			return nil, err
 		}
-		if _, err := tw.Write(item.Sig); err != nil {
-			return false, err
+		if hdr.Name != exportManifestFilename {
+			continue
 		}
-		if _, err := tw.Write(item.Data); err != nil {
-			return false, err
+		m := new(ExportManifest)
+		if err := json.NewDecoder(tr).Decode(m); err != nil {
+			return nil, fmt.Errorf("decode export manifest: %w", err)
 		}
-		count++
-		return false, nil
-	}, nil)
+		return m, nil
+	}
+}
 
-	return count, err
+// ImportReport describes the outcome of an import.
+type ImportReport struct {
+	// Imported is the number of the stored chunks.
+	Imported int64
+	// Skipped are the reasons of skipping the files of the archive
+	// that failed the verification, by file name.
+	Skipped map[string]string
+	// Missing are the names of the files listed in the checksums
+	// of the archive that are not in the archive.
+	Missing []string
+	// Mismatched are the names of the files in the archive with a
+	// checksum other than the one listed in the checksums of the
+	// archive. The skipped files are not included.
+	Mismatched []string
 }
 
 // Import reads a tar structured data from the reader and
 // stores chunks in the database. It returns the number of
 // chunks imported.
 func (db *DB) Import(ctx context.Context, r io.Reader) (count int64, err error) {
-	tr := tar.NewReader(r)
-
-	errC := make(chan error)
-	doneC := make(chan struct{})
-	tokenPool := make(chan struct{}, 100)
-	var wg sync.WaitGroup
-	go func() {
-		var (
-			firstFile = true
-
-			// if exportVersionFilename file is not present
-			// assume current version
-			version = currentExportVersion
-		)
-		for {
-			hdr, err := tr.Next()
-			if err != nil {
-				if err == io.EOF {
-					break
-				}
-				select {
-				case errC <- err:
-				case <-ctx.Done():
-				}
-			}
-			if firstFile {
-				firstFile = false
-				if hdr.Name == exportVersionFilename {
-					data, err := ioutil.ReadAll(tr)
-					if err != nil {
-						select {
-						case errC <- err:
-						case <-ctx.Done():
-						}
-					}
-					version = string(data)
-					continue
-				}
-			}
+	report, err := db.ImportWithReport(ctx, r)
+	return report.Imported, err
+}
 
-			if len(hdr.Name) != 64 {
-				db.logger.Warningf("localstore export: ignoring non-chunk file: %s", hdr.Name)
-				continue
-			}
+// ImportWithReport reads a tar structured data, optionally compressed, from
+// the reader and stores the chunks in the database. The checksums of the
+// files and the addresses of the chunks are verified before storing them,
+// the files failing the verification are skipped and reported. The files
+// missing from the archive or not matching the checksums listed at its end
+// are reported too. The listed checksums are verified as the checksums files
+// are read, against the files read since the previous checksums file.
+func (db *DB) ImportWithReport(ctx context.Context, r io.Reader) (report ImportReport, err error) {
+	report.Skipped = make(map[string]string)
 
-			keybytes, err := hex.DecodeString(hdr.Name)
-			if err != nil {
-				db.logger.Warningf("localstore export: ignoring invalid chunk file %s: %v", hdr.Name, err)
+	er, err := exportReader(r)
+	if err != nil {
+		return report, err
+	}
+	defer er.Close()
+	tr := tar.NewReader(er)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		putErr    error
+		tokenPool = make(chan struct{}, 100)
+
+		// if exportVersionFilename file is not present
+		// assume current version
+		version = currentExportVersion
+
+		// checksums of the files read since the last checksums
+		// file, empty for the skipped files as they are reported
+		read = make(map[string]string)
+	)
+
+	skip := func(name, reason string) {
+		db.logger.Warningf("localstore import: skipping %s: %s", name, reason)
+		report.Skipped[name] = reason
+	}
+
+	// verify compares the checksums listed in the checksums file with
+	// the ones of the files read since the previous checksums file
+	verify := func(r io.Reader) error {
+		s := bufio.NewScanner(r)
+		for s.Scan() {
+			fields := strings.Fields(s.Text())
+			if len(fields) != 2 {
+				db.logger.Warningf("localstore import: invalid export checksums line: %q", s.Text())
 				continue
 			}
+			name, want := fields[0], fields[1]
+			got, ok := read[name]
+			switch {
+			case !ok:
+				db.logger.Warningf("localstore import: missing %s", name)
+				report.Missing = append(report.Missing, name)
+			case got != "" && got != want:
+				db.logger.Warningf("localstore import: checksum mismatch %s", name)
+				report.Mismatched = append(report.Mismatched, name)
+			}
+		}
+		read = make(map[string]string)
+		return s.Err()
+	}
 
-			rawdata, err := ioutil.ReadAll(tr)
-			if err != nil {
-				select {
-				case errC <- err:
-				case <-ctx.Done():
-				}
+	for first := true; ; first = false {
+		hdr, err := tr.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
 			}
-			stamp := new(postage.Stamp)
-			err = stamp.UnmarshalBinary(rawdata[:postage.StampSize])
-			if err != nil {
-				select {
-				case errC <- err:
-				case <-ctx.Done():
-				}
+			cancel()
+			wg.Wait()
+			return report, err
+		}
+		if hdr.Name == exportChecksumsFilename {
+			if err := verify(tr); err != nil {
+				db.logger.Warningf("localstore import: read export checksums: %v", err)
+			}
+			continue
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			cancel()
+			wg.Wait()
+			return report, err
+		}
+
+		// a well formed archive lists the files before more of them
+		// are read, the ones read beyond are not tracked
+		track := len(read) <= exportChecksumsInterval
+		h := sha256.Sum256(data)
+		sum := hex.EncodeToString(h[:])
+		if want, ok := hdr.PAXRecords[exportChecksumRecord]; ok && want != sum {
+			skip(hdr.Name, "checksum mismatch")
+			if track {
+				read[hdr.Name] = ""
 			}
-			data := rawdata[postage.StampSize:]
-			key := swarm.NewAddress(keybytes)
+			continue
+		}
+		if track {
+			read[hdr.Name] = sum
+		}
 
-			var ch swarm.Chunk
+		switch {
+		case first && hdr.Name == exportVersionFilename:
+			version = string(data)
 			switch version {
-			case currentExportVersion:
-				ch = swarm.NewChunk(key, data).WithStamp(stamp)
+			case currentExportVersion, exportVersionV4, exportVersionV3:
 			default:
-				select {
-				case errC <- fmt.Errorf("unsupported export data version %q", version):
-				case <-ctx.Done():
-				}
+				return report, fmt.Errorf("unsupported export data version %q", version)
 			}
-			tokenPool <- struct{}{}
-			wg.Add(1)
-
-			go func() {
-				_, err := db.Put(ctx, storage.ModePutUpload, ch)
-				select {
-				case errC <- err:
-				case <-ctx.Done():
-					wg.Done()
-					<-tokenPool
-				default:
-					_, err := db.Put(ctx, storage.ModePutUpload, ch)
-					if err != nil {
-						errC <- err
-					}
-					wg.Done()
-					<-tokenPool
-				}
-			}()
+			continue
+		case hdr.Name == exportManifestFilename:
+			var m ExportManifest
+			if err := json.Unmarshal(data, &m); err != nil {
+				db.logger.Warningf("localstore import: invalid export manifest: %v", err)
+				continue
+			}
+			db.logger.Infof("localstore import: export of %d chunks with %d roots and %d postage batches", m.Chunks, len(m.Roots), len(m.Stamps))
+			continue
+		case len(hdr.Name) != 64:
+			db.logger.Warningf("localstore import: ignoring non-chunk file: %s", hdr.Name)
+			continue
+		}
 
-			count++
+		keybytes, err := hex.DecodeString(hdr.Name)
+		if err != nil {
+			skip(hdr.Name, "invalid chunk file name")
+			continue
+		}
+		if len(data) < postage.StampSize {
+			skip(hdr.Name, "short chunk file")
+			continue
+		}
+		stamp := new(postage.Stamp)
+		if err := stamp.UnmarshalBinary(data[:postage.StampSize]); err != nil {
+			skip(hdr.Name, "invalid postage stamp")
+			continue
+		}
+		ch := swarm.NewChunk(swarm.NewAddress(keybytes), data[postage.StampSize:]).WithStamp(stamp)
+		if !cac.Valid(ch) && !soc.Valid(ch) {
+			skip(hdr.Name, "invalid chunk address")
+			continue
 		}
-		wg.Wait()
-		close(doneC)
-	}()
 
-	// wait for all chunks to be stored
-	for {
 		select {
-		case err := <-errC:
-			if err != nil {
-				return count, err
-			}
+		case tokenPool <- struct{}{}:
 		case <-ctx.Done():
-			return count, ctx.Err()
-		default:
-			select {
-			case <-doneC:
-				return count, nil
-			default:
-			}
 		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-tokenPool
+				wg.Done()
+			}()
+			_, err := db.Put(ctx, storage.ModePutUpload, ch)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if putErr == nil {
+					putErr = err
+					cancel()
+				}
+				return
+			}
+			report.Imported++
+		}()
 	}
+	wg.Wait()
+
+	if putErr != nil {
+		return report, putErr
+	}
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+
+	sort.Strings(report.Missing)
+	sort.Strings(report.Mismatched)
+	return report, nil
 }
@@ -17,11 +17,23 @@
 package localstore
 
 import (
+	"archive/tar"
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
 	"testing"
 
+	"github.com/ethersphere/bee/pkg/file/pipeline/builder"
+	postagetesting "github.com/ethersphere/bee/pkg/postage/testing"
+	"github.com/ethersphere/bee/pkg/shed"
 	"github.com/ethersphere/bee/pkg/storage"
+	chunktesting "github.com/ethersphere/bee/pkg/storage/testing"
 	"github.com/ethersphere/bee/pkg/swarm"
 )
 
@@ -85,3 +97,403 @@ func TestExportImport(t *testing.T) {
 		}
 	}
 }
+
+// stampPutter stamps the chunks put by the pipeline.
+type stampPutter struct {
+	db *DB
+}
+
+func (p stampPutter) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk) ([]bool, error) {
+	for i, ch := range chs {
+		chs[i] = ch.WithStamp(postagetesting.MustNewStamp())
+	}
+	return p.db.Put(ctx, mode, chs...)
+}
+
+// TestExportSelected validates that only the chunks selected
+// by the export options are exported and imported.
+func TestExportSelected(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, nil)
+
+	putChunks := func(t *testing.T, n int) []swarm.Address {
+		t.Helper()
+
+		addrs := make([]swarm.Address, n)
+		for i := range addrs {
+			ch := generateTestRandomChunk()
+			if _, err := db.Put(ctx, storage.ModePutUpload, ch); err != nil {
+				t.Fatal(err)
+			}
+			addrs[i] = ch.Address()
+		}
+		return addrs
+	}
+
+	// export returns the addresses of the exported chunks and the manifest
+	export := func(t *testing.T, o ExportOptions) (map[string]struct{}, *ExportManifest) {
+		t.Helper()
+
+		var buf bytes.Buffer
+		count, err := db.ExportSelected(ctx, &buf, o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := ReadExportManifest(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m == nil {
+			t.Fatal("export manifest missing")
+		}
+		if m.Chunks != count {
+			t.Fatalf("got manifest chunks %d, want %d", m.Chunks, count)
+		}
+		var stamped int64
+		for _, n := range m.Stamps {
+			stamped += n
+		}
+		if stamped != count {
+			t.Fatalf("got %d stamped chunks, want %d", stamped, count)
+		}
+
+		db2 := newTestDB(t, nil)
+		if _, err := db2.Import(ctx, &buf); err != nil {
+			t.Fatal(err)
+		}
+		exported := make(map[string]struct{})
+		if err := db2.retrievalDataIndex.Iterate(func(item shed.Item) (bool, error) {
+			exported[string(item.Address)] = struct{}{}
+			return false, nil
+		}, nil); err != nil {
+			t.Fatal(err)
+		}
+		if int64(len(exported)) != count {
+			t.Fatalf("got %d imported chunks, want %d", len(exported), count)
+		}
+		return exported, m
+	}
+
+	checkExported := func(t *testing.T, exported map[string]struct{}, want []swarm.Address) {
+		t.Helper()
+
+		if len(exported) != len(want) {
+			t.Fatalf("got %d exported chunks, want %d", len(exported), len(want))
+		}
+		for _, addr := range want {
+			if _, ok := exported[string(addr.Bytes())]; !ok {
+				t.Fatalf("chunk %s not exported", addr)
+			}
+		}
+	}
+
+	chunks := putChunks(t, 10)
+	for _, addr := range chunks[:3] {
+		if err := db.Set(ctx, storage.ModeSetPin, addr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pipe := builder.NewPipelineBuilder(ctx, stampPutter{db: db}, storage.ModePutUpload, false)
+	root, err := builder.FeedPipeline(ctx, pipe, bytes.NewReader(make([]byte, 3*swarm.ChunkSize+1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("pinned", func(t *testing.T) {
+		exported, m := export(t, ExportOptions{Pinned: true})
+		checkExported(t, exported, chunks[:3])
+		if !m.Pinned {
+			t.Fatal("manifest not marked pinned")
+		}
+	})
+
+	t.Run("roots", func(t *testing.T) {
+		exported, m := export(t, ExportOptions{Roots: []swarm.Address{root}})
+		// three data chunks of the same content, a single data chunk
+		// with the last byte and the intermediate chunk
+		if len(exported) != 3 {
+			t.Fatalf("got %d exported chunks, want 3", len(exported))
+		}
+		if _, ok := exported[string(root.Bytes())]; !ok {
+			t.Fatal("root chunk not exported")
+		}
+		if len(m.Roots) != 1 || !m.Roots[0].Equal(root) {
+			t.Fatalf("got manifest roots %v, want %v", m.Roots, root)
+		}
+	})
+
+	t.Run("incremental", func(t *testing.T) {
+		_, m := export(t, ExportOptions{})
+
+		newChunks := putChunks(t, 5)
+		exported, _ := export(t, ExportOptions{Since: m.BinIDs})
+		checkExported(t, exported, newChunks)
+
+		if err := db.Set(ctx, storage.ModeSetPin, newChunks[0]); err != nil {
+			t.Fatal(err)
+		}
+		exported, _ = export(t, ExportOptions{Since: m.BinIDs, Pinned: true})
+		checkExported(t, exported, newChunks[:1])
+	})
+}
+
+// TestImportV3 validates that the exports without
+// the export manifest are imported.
+func TestImportV3(t *testing.T) {
+	ch := generateTestRandomChunk()
+	stamp, err := ch.Stamp().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := writeExportFile(tw, exportVersionFilename, []byte(exportVersionV3)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeExportFile(tw, hex.EncodeToString(ch.Address().Bytes()), append(stamp, ch.Data()...)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db := newTestDB(t, nil)
+	c, err := db.Import(context.Background(), &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c != 1 {
+		t.Fatalf("got import count %d, want 1", c)
+	}
+	got, err := db.Get(context.Background(), storage.ModeGetRequest, ch.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Data(), ch.Data()) {
+		t.Fatal("imported chunk data mismatch")
+	}
+}
+
+// TestExportCompressed validates that the gzip and zstd compressed
+// exports are imported and their manifests are read.
+func TestExportCompressed(t *testing.T) {
+	ctx := context.Background()
+	db1 := newTestDB(t, nil)
+
+	chunks := make([]swarm.Chunk, 10)
+	for i := range chunks {
+		chunks[i] = generateTestRandomChunk()
+	}
+	if _, err := db1.Put(ctx, storage.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db1.ExportSelected(ctx, ioutil.Discard, ExportOptions{Compression: "lz4"}); !errors.Is(err, ErrUnsupportedCompression) {
+		t.Fatalf("got error %v, want %v", err, ErrUnsupportedCompression)
+	}
+
+	for _, tc := range []struct {
+		compression string
+		magic       []byte
+	}{
+		{compression: CompressionGzip, magic: gzipMagic},
+		{compression: CompressionZstd, magic: zstdMagic},
+	} {
+		t.Run(tc.compression, func(t *testing.T) {
+			var buf bytes.Buffer
+			c, err := db1.ExportSelected(ctx, &buf, ExportOptions{Compression: tc.compression})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c != int64(len(chunks)) {
+				t.Fatalf("got export count %d, want %d", c, len(chunks))
+			}
+			if !bytes.HasPrefix(buf.Bytes(), tc.magic) {
+				t.Fatalf("export is not %s compressed", tc.compression)
+			}
+
+			m, err := ReadExportManifest(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m == nil || m.Chunks != int64(len(chunks)) {
+				t.Fatalf("got manifest %+v, want %d chunks", m, len(chunks))
+			}
+
+			db2 := newTestDB(t, nil)
+			report, err := db2.ImportWithReport(ctx, &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Imported != int64(len(chunks)) {
+				t.Fatalf("got import count %d, want %d", report.Imported, len(chunks))
+			}
+			if len(report.Skipped) != 0 || len(report.Missing) != 0 {
+				t.Fatalf("got skipped %v and missing %v, want none", report.Skipped, report.Missing)
+			}
+			for _, ch := range chunks {
+				if _, err := db2.Get(ctx, storage.ModeGetRequest, ch.Address()); err != nil {
+					t.Fatalf("get chunk %s: %v", ch.Address(), err)
+				}
+			}
+		})
+	}
+}
+
+// TestImportVerification validates that the files with a mismatching
+// checksum and the chunks with an invalid address are skipped and that
+// the files listed in the checksums but not in the archive or with another
+// checksum are reported, the skipped ones only once.
+func TestImportVerification(t *testing.T) {
+	chunkFile := func(t *testing.T, ch swarm.Chunk) (string, []byte) {
+		t.Helper()
+		stamp, err := ch.Stamp().MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hex.EncodeToString(ch.Address().Bytes()), append(stamp, ch.Data()...)
+	}
+
+	var buf bytes.Buffer
+	ew := &exportWriter{tw: tar.NewWriter(&buf)}
+	if err := ew.write(exportVersionFilename, []byte(currentExportVersion)); err != nil {
+		t.Fatal(err)
+	}
+
+	valid := generateTestRandomChunk()
+	validName, validData := chunkFile(t, valid)
+	if err := ew.write(validName, validData); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := chunktesting.GenerateTestRandomInvalidChunk()
+	invalidName, invalidData := chunkFile(t, invalid)
+	if err := ew.write(invalidName, invalidData); err != nil {
+		t.Fatal(err)
+	}
+
+	corrupted := generateTestRandomChunk()
+	corruptedName, corruptedData := chunkFile(t, corrupted)
+	sum := sha256.Sum256(corruptedData)
+	corruptedData[len(corruptedData)-1]++
+	if err := ew.tw.WriteHeader(&tar.Header{
+		Name:       corruptedName,
+		Mode:       0644,
+		Size:       int64(len(corruptedData)),
+		PAXRecords: map[string]string{exportChecksumRecord: hex.EncodeToString(sum[:])},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ew.tw.Write(corruptedData); err != nil {
+		t.Fatal(err)
+	}
+
+	fmt.Fprintf(&ew.checksums, "%s %x\n", corruptedName, sum)
+
+	// the chunk is valid but its checksum in the checksums file is not
+	tampered := generateTestRandomChunk()
+	tamperedName, tamperedData := chunkFile(t, tampered)
+	if err := ew.tw.WriteHeader(&tar.Header{
+		Name: tamperedName,
+		Mode: 0644,
+		Size: int64(len(tamperedData)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ew.tw.Write(tamperedData); err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintf(&ew.checksums, "%s %x\n", tamperedName, sum)
+
+	missingName := hex.EncodeToString(generateTestRandomChunk().Address().Bytes())
+	fmt.Fprintf(&ew.checksums, "%s %x\n", missingName, sum)
+	if err := ew.flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ew.tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db := newTestDB(t, nil)
+	report, err := db.ImportWithReport(context.Background(), &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Imported != 2 {
+		t.Fatalf("got import count %d, want 2", report.Imported)
+	}
+	wantSkipped := map[string]string{
+		invalidName:   "invalid chunk address",
+		corruptedName: "checksum mismatch",
+	}
+	if !reflect.DeepEqual(report.Skipped, wantSkipped) {
+		t.Fatalf("got skipped %v, want %v", report.Skipped, wantSkipped)
+	}
+	if !reflect.DeepEqual(report.Missing, []string{missingName}) {
+		t.Fatalf("got missing %v, want %v", report.Missing, []string{missingName})
+	}
+	if !reflect.DeepEqual(report.Mismatched, []string{tamperedName}) {
+		t.Fatalf("got mismatched %v, want %v", report.Mismatched, []string{tamperedName})
+	}
+
+	if _, err := db.Get(context.Background(), storage.ModeGetRequest, valid.Address()); err != nil {
+		t.Fatal(err)
+	}
+	for _, ch := range []swarm.Chunk{invalid, corrupted} {
+		if _, err := db.Get(context.Background(), storage.ModeGetRequest, ch.Address()); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+		}
+	}
+}
+
+// TestExportChecksumsInterval validates that the checksums files are written
+// every exportChecksumsInterval files and verified on import.
+func TestExportChecksumsInterval(t *testing.T) {
+	defer func(interval int) { exportChecksumsInterval = interval }(exportChecksumsInterval)
+	exportChecksumsInterval = 3
+
+	db1 := newTestDB(t, nil)
+	for i := 0; i < 10; i++ {
+		if _, err := db1.Put(context.Background(), storage.ModePutUpload, generateTestRandomChunk()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := db1.Export(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// the version file, the chunks and the manifest are listed
+	var checksums int
+	tr := tar.NewReader(bytes.NewReader(buf.Bytes()))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Name == exportChecksumsFilename {
+			checksums++
+		}
+	}
+	if checksums != 4 {
+		t.Fatalf("got %d checksums files, want 4", checksums)
+	}
+
+	db2 := newTestDB(t, nil)
+	report, err := db2.ImportWithReport(context.Background(), &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Imported != 10 {
+		t.Fatalf("got import count %d, want 10", report.Imported)
+	}
+	if len(report.Skipped) != 0 || len(report.Missing) != 0 || len(report.Mismatched) != 0 {
+		t.Fatalf("got skipped %v, missing %v and mismatched %v, want none", report.Skipped, report.Missing, report.Mismatched)
+	}
+}
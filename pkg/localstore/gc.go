@@ -38,6 +38,11 @@ var (
 	// gcBatchSize limits the number of chunks in a single
 	// transaction on garbage collection.
 	gcBatchSize uint64 = 2000
+	// gcProtectOverflowRatio is the ratio of the cache capacity by which
+	// the chunks protected from the garbage collection may make the gc
+	// size exceed the capacity. Beyond it the protection is lifted until
+	// the gc size gets back within the limit.
+	gcProtectOverflowRatio = 0.1
 
 	// reserveCollectionRatio is the ratio of the cache to evict from
 	// the reserve every time it hits the limit. If the cache size is
@@ -125,7 +130,29 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 	first := true
 	start := time.Now()
 	candidates := make([]shed.Item, 0)
-	err = db.gcIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+
+	// the gc index is ordered by the access timestamps, the iteration
+	// stops at the first chunk accessed within the protection duration
+	// unless the protected chunks make the gc size exceed the limit
+	var protected bool
+	protect := db.gcProtect > 0
+	if protect && gcSize > db.gcProtectLimit() {
+		protect = false
+		db.metrics.GCProtectOverflowCounter.Inc()
+		db.logger.Warningf("localstore: gc size %d exceeds the limit %d, evicting the protected chunks", gcSize, db.gcProtectLimit())
+	}
+	protectFrom := now() - db.gcProtect.Nanoseconds()
+	iterate := func(fn shed.IndexIterFunc) error {
+		return db.gcIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			if protect && item.AccessTimestamp > protectFrom {
+				protected = true
+				return true, nil
+			}
+			return fn(item)
+		}, nil)
+	}
+
+	err = db.gcPolicy.candidates(iterate, func(item shed.Item) (stop bool, err error) {
 		if first {
 			totalTimeMetric(db.metrics.TotalTimeGCFirstItem, start)
 			first = false
@@ -144,7 +171,7 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 			return true, nil
 		}
 		return false, nil
-	}, nil)
+	})
 	if err != nil {
 		return 0, false, err
 	}
@@ -166,6 +193,7 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 	}
 
 	// get rid of dirty entries
+	evicted := make([]shed.Item, 0, len(candidates))
 	for _, item := range candidates {
 		if swarm.NewAddress(item.Address).MemberOf(db.dirtyAddresses) {
 			collectedCount--
@@ -201,9 +229,17 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 			return 0, false, err
 		}
 
+		evicted = append(evicted, item)
 	}
+	// the protected chunks are not collected until
+	// the garbage collection is triggered again
 	if gcSize-collectedCount > target {
-		done = false
+		if protected {
+			db.metrics.GCProtectedCounter.Inc()
+			db.logger.Debugf("localstore: gc stopped by the protected chunks at gc size %d", gcSize-collectedCount)
+		} else {
+			done = false
+		}
 	}
 
 	db.metrics.GCCommittedCounter.Add(float64(collectedCount))
@@ -214,9 +250,18 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 		db.metrics.GCErrorCounter.Inc()
 		return 0, false, err
 	}
+	for _, item := range evicted {
+		db.gcPolicy.removed(item)
+	}
 	return collectedCount, done, nil
 }
 
+// gcProtectLimit returns the gc size beyond which the chunks protected from
+// the garbage collection are evicted too.
+func (db *DB) gcProtectLimit() uint64 {
+	return db.cacheCapacity + uint64(float64(db.cacheCapacity)*gcProtectOverflowRatio)
+}
+
 // gcTrigger retruns the absolute value for garbage collection
 // target value, calculated from db.capacity and gcTargetRatio.
 func (db *DB) gcTarget() (target uint64) {
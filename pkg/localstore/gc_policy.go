@@ -0,0 +1,140 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/shed"
+)
+
+// Garbage collection policies selecting the chunks to evict.
+const (
+	// GCPolicyLRU evicts the least recently accessed chunks.
+	GCPolicyLRU = "lru"
+	// GCPolicyLFU evicts the least frequently accessed chunks among the
+	// least recently accessed ones.
+	GCPolicyLFU = "lfu"
+)
+
+// ErrUnknownGCPolicy is returned when constructing the database
+// with an unknown garbage collection policy.
+var ErrUnknownGCPolicy = errors.New("unknown gc policy")
+
+// lfuSampleRatio is the number of gc batches of the least recently
+// accessed chunks that the LFU policy orders by access counts.
+var lfuSampleRatio uint64 = 5
+
+// gcPolicy selects the chunks evicted by the garbage collection among
+// the items of the gc index.
+type gcPolicy interface {
+	// name is the name of the policy, it is used as a metrics label.
+	name() string
+	// accessed is called with the item of a requested chunk.
+	accessed(item shed.Item)
+	// removed is called with the item of a chunk removed from the gc
+	// index by the garbage collection, a pin, a reserve move or a
+	// removal.
+	removed(item shed.Item)
+	// candidates calls the function with the items to evict in the order
+	// of eviction until it returns stop or an error. The iterate function
+	// iterates over the gc index in the least recently accessed order.
+	candidates(iterate func(shed.IndexIterFunc) error, fn shed.IndexIterFunc) error
+}
+
+// newGCPolicy returns the garbage collection policy of the name, the
+// LRU policy if the name is empty.
+func newGCPolicy(name string) (gcPolicy, error) {
+	switch name {
+	case "", GCPolicyLRU:
+		return lruPolicy{}, nil
+	case GCPolicyLFU:
+		return &lfuPolicy{counts: make(map[string]uint64)}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownGCPolicy, name)
+	}
+}
+
+// lruPolicy evicts the chunks in the gc index order.
+type lruPolicy struct{}
+
+func (lruPolicy) name() string { return GCPolicyLRU }
+
+func (lruPolicy) accessed(shed.Item) {}
+
+func (lruPolicy) removed(shed.Item) {}
+
+func (lruPolicy) candidates(iterate func(shed.IndexIterFunc) error, fn shed.IndexIterFunc) error {
+	return iterate(fn)
+}
+
+// lfuPolicy counts the chunk accesses and evicts the least frequently
+// accessed chunks first among a sample of the least recently accessed
+// ones, which keeps the eviction cost independent of the gc index size.
+// The counts are kept in memory for the items of the gc index only, they
+// start from zero on every start.
+type lfuPolicy struct {
+	mu     sync.Mutex
+	counts map[string]uint64
+}
+
+func (p *lfuPolicy) name() string { return GCPolicyLFU }
+
+func (p *lfuPolicy) accessed(item shed.Item) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.counts[string(item.Address)]++
+}
+
+func (p *lfuPolicy) removed(item shed.Item) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.counts, string(item.Address))
+}
+
+func (p *lfuPolicy) candidates(iterate func(shed.IndexIterFunc) error, fn shed.IndexIterFunc) error {
+	size := int(gcBatchSize * lfuSampleRatio)
+	sample := make([]shed.Item, 0, size)
+	err := iterate(func(item shed.Item) (stop bool, err error) {
+		sample = append(sample, item)
+		return len(sample) >= size, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	counts := make([]uint64, len(sample))
+	for i, item := range sample {
+		counts[i] = p.counts[string(item.Address)]
+	}
+	p.mu.Unlock()
+
+	// the stable sort keeps the least recently
+	// accessed first among the equal counts
+	order := make([]int, len(sample))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return counts[order[i]] < counts[order[j]]
+	})
+
+	for _, i := range order {
+		stop, err := fn(sample[i])
+		if err != nil {
+			return err
+		}
+		if stop {
+			return nil
+		}
+	}
+	return nil
+}
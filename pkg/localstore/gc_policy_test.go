@@ -0,0 +1,240 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+// TestLFUPolicy validates that the LFU policy offers the least frequently
+// accessed items first and the least recently accessed first among the
+// equally accessed ones.
+func TestLFUPolicy(t *testing.T) {
+	p, err := newGCPolicy(GCPolicyLFU)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// items in the least recently accessed order
+	items := []shed.Item{
+		{Address: []byte("a")},
+		{Address: []byte("b")},
+		{Address: []byte("c")},
+		{Address: []byte("d")},
+	}
+	iterate := func(fn shed.IndexIterFunc) error {
+		for _, item := range items {
+			stop, err := fn(item)
+			if err != nil || stop {
+				return err
+			}
+		}
+		return nil
+	}
+	candidates := func() (got string) {
+		if err := p.candidates(iterate, func(item shed.Item) (bool, error) {
+			got += string(item.Address)
+			return false, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	for i := 0; i < 3; i++ {
+		p.accessed(items[0])
+	}
+	p.accessed(items[2])
+	if got, want := candidates(), "bdca"; got != want {
+		t.Fatalf("got candidates %q, want %q", got, want)
+	}
+
+	p.removed(items[0])
+	if got, want := candidates(), "abdc"; got != want {
+		t.Fatalf("got candidates %q, want %q", got, want)
+	}
+
+	if _, err := newGCPolicy("mru"); !errors.Is(err, ErrUnknownGCPolicy) {
+		t.Fatalf("got error %v, want %v", err, ErrUnknownGCPolicy)
+	}
+}
+
+// TestLFUPolicyCounts validates that the access counts of the LFU policy
+// are dropped when the chunks leave the gc index.
+func TestLFUPolicyCounts(t *testing.T) {
+	t.Cleanup(setWithinRadiusFunc(func(_ *DB, _ shed.Item) bool { return false }))
+
+	db := newTestDB(t, &Options{
+		GCPolicy: GCPolicyLFU,
+	})
+	counts := func() int {
+		p := db.gcPolicy.(*lfuPolicy)
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return len(p.counts)
+	}
+
+	ctx := context.Background()
+	for _, mode := range []storage.ModeSet{storage.ModeSetPin, storage.ModeSetRemove} {
+		ch := generateTestRandomChunk()
+		unreserveChunkBatch(t, db, 0, ch)
+		if _, err := db.Put(ctx, storage.ModePutUpload, ch); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Set(ctx, storage.ModeSetSync, ch.Address()); err != nil {
+			t.Fatal(err)
+		}
+
+		updated := make(chan struct{})
+		reset := setTestHookUpdateGC(func() { close(updated) })
+		if _, err := db.Get(ctx, storage.ModeGetRequest, ch.Address()); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-updated:
+		case <-time.After(10 * time.Second):
+			t.Fatal("gc index not updated")
+		}
+		reset()
+		if got := counts(); got != 1 {
+			t.Fatalf("got %d counts, want 1", got)
+		}
+
+		if err := db.Set(ctx, mode, ch.Address()); err != nil {
+			t.Fatal(err)
+		}
+		if got := counts(); got != 0 {
+			t.Fatalf("got %d counts after %v, want none", got, mode)
+		}
+	}
+}
+
+// TestGCProtect validates that the chunks accessed within the
+// protection duration are not collected by the garbage collection.
+func TestGCProtect(t *testing.T) {
+	// within the limit of the protected chunks
+	chunkCount := 105
+
+	var closed chan struct{}
+	testHookCollectGarbageChan := make(chan uint64)
+	t.Cleanup(setTestHookCollectGarbage(func(collectedCount uint64) {
+		if collectedCount == 0 {
+			return
+		}
+		select {
+		case testHookCollectGarbageChan <- collectedCount:
+		case <-closed:
+		}
+	}))
+	t.Cleanup(setWithinRadiusFunc(func(_ *DB, _ shed.Item) bool { return false }))
+
+	// the time is read by the garbage collection worker
+	ts := time.Now().UnixNano()
+	t.Cleanup(setNow(func() int64 { return atomic.LoadInt64(&ts) }))
+
+	db := newTestDB(t, &Options{
+		Capacity:  100,
+		GCProtect: time.Hour,
+	})
+	closed = db.close
+
+	ctx := context.Background()
+	for i := 0; i < chunkCount; i++ {
+		ch := generateTestRandomChunk()
+		unreserveChunkBatch(t, db, 0, ch)
+		if _, err := db.Put(ctx, storage.ModePutUpload, ch); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Set(ctx, storage.ModeSetSync, ch.Address()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	collected, done, err := db.collectGarbage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if collected != 0 || !done {
+		t.Fatalf("got collected %d and done %v, want none collected and done", collected, done)
+	}
+	t.Run("gc index count", newItemsCountTest(db.gcIndex, chunkCount))
+
+	// the chunks are not protected any more
+	atomic.AddInt64(&ts, int64(2*time.Hour))
+	db.triggerGarbageCollection()
+
+	gcTarget := db.gcTarget()
+	for {
+		select {
+		case <-testHookCollectGarbageChan:
+		case <-time.After(10 * time.Second):
+			t.Fatal("collect garbage timeout")
+		}
+		gcSize, err := db.gcSize.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gcSize == gcTarget {
+			break
+		}
+	}
+	t.Run("gc index count after protection", newItemsCountTest(db.gcIndex, int(gcTarget)))
+}
+
+// TestGCProtectOverflow validates that the protected chunks are collected
+// when they make the gc size exceed the limit.
+func TestGCProtectOverflow(t *testing.T) {
+	chunkCount := 150
+
+	var closed chan struct{}
+	testHookCollectGarbageChan := make(chan uint64)
+	t.Cleanup(setTestHookCollectGarbage(func(collectedCount uint64) {
+		select {
+		case testHookCollectGarbageChan <- collectedCount:
+		case <-closed:
+		}
+	}))
+	t.Cleanup(setWithinRadiusFunc(func(_ *DB, _ shed.Item) bool { return false }))
+
+	db := newTestDB(t, &Options{
+		Capacity:  100,
+		GCProtect: time.Hour,
+	})
+	closed = db.close
+
+	ctx := context.Background()
+	for i := 0; i < chunkCount; i++ {
+		ch := generateTestRandomChunk()
+		unreserveChunkBatch(t, db, 0, ch)
+		if _, err := db.Put(ctx, storage.ModePutUpload, ch); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Set(ctx, storage.ModeSetSync, ch.Address()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for {
+		select {
+		case <-testHookCollectGarbageChan:
+		case <-time.After(10 * time.Second):
+			t.Fatal("collect garbage timeout")
+		}
+		gcSize, err := db.gcSize.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gcSize <= db.gcProtectLimit() {
+			break
+		}
+	}
+}
@@ -113,6 +113,13 @@ type DB struct {
 	// the cacheCapacity value
 	cacheCapacity uint64
 
+	// gcPolicy selects the chunks evicted by the garbage collection
+	gcPolicy gcPolicy
+	// chunks accessed within the gcProtect duration are not
+	// evicted by the garbage collection, unless the gc size
+	// exceeds the limit returned by gcProtectLimit
+	gcProtect time.Duration
+
 	// the size of the reserve in chunks
 	reserveCapacity uint64
 
@@ -188,6 +195,13 @@ type Options struct {
 	// DisableSeeksCompaction toggles the seek driven compactions feature on leveldb
 	// and is passed on to shed.
 	DisableSeeksCompaction bool
+	// GCPolicy is the name of the garbage collection policy,
+	// GCPolicyLRU if empty.
+	GCPolicy string
+	// GCProtect is the duration after the last access during which
+	// the chunks are not evicted by the garbage collection, as long as
+	// they do not make the cache exceed its capacity by more than 10%.
+	GCProtect time.Duration
 
 	// MetricsPrefix defines a prefix for metrics names.
 	MetricsPrefix string
@@ -209,6 +223,7 @@ func New(path string, baseKey []byte, ss storage.StateStorer, o *Options, logger
 	db = &DB{
 		stateStore:      ss,
 		cacheCapacity:   o.Capacity,
+		gcProtect:       o.GCProtect,
 		reserveCapacity: o.ReserveCapacity,
 		unreserveFunc:   o.UnreserveFunc,
 		baseKey:         baseKey,
@@ -228,6 +243,10 @@ func New(path string, baseKey []byte, ss storage.StateStorer, o *Options, logger
 	if db.cacheCapacity == 0 {
 		db.cacheCapacity = defaultCacheCapacity
 	}
+	db.gcPolicy, err = newGCPolicy(o.GCPolicy)
+	if err != nil {
+		return nil, err
+	}
 
 	capacityMB := float64((db.cacheCapacity+uint64(batchstore.Capacity))*swarm.ChunkSize) * 9.5367431640625e-7
 
@@ -33,6 +33,10 @@ type metrics struct {
 	GCExcludeWriteBatchError prometheus.Counter
 	GCUpdate                 prometheus.Counter
 	GCUpdateError            prometheus.Counter
+	GCPolicyHits             *prometheus.CounterVec
+	GCPolicyMisses           *prometheus.CounterVec
+	GCProtectedCounter       prometheus.Counter
+	GCProtectOverflowCounter prometheus.Counter
 
 	ModeGet                       prometheus.Counter
 	ModeGetFailure                prometheus.Counter
@@ -330,6 +334,36 @@ func newMetrics() metrics {
 			Help:      "Number of times SUBSCRIBE_PUSH_ITERATION_FAILURE is invoked.",
 		}),
 
+		GCPolicyHits: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: m.Namespace,
+				Subsystem: subsystem,
+				Name:      "gc_policy_hit_count",
+				Help:      "Number of requested chunks found in the database grouped by garbage collection policy.",
+			},
+			[]string{"policy"},
+		),
+		GCPolicyMisses: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: m.Namespace,
+				Subsystem: subsystem,
+				Name:      "gc_policy_miss_count",
+				Help:      "Number of requested chunks not found in the database grouped by garbage collection policy.",
+			},
+			[]string{"policy"},
+		),
+		GCProtectedCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "gc_protected_count",
+			Help:      "Number of times the GC stopped above the target at a protected chunk.",
+		}),
+		GCProtectOverflowCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "gc_protect_overflow_count",
+			Help:      "Number of times the GC evicted the protected chunks as the gc size exceeded the limit.",
+		}),
 		GCSize: prometheus.NewGauge(prometheus.GaugeOpts{
 			Namespace: m.Namespace,
 			Subsystem: subsystem,
@@ -46,10 +46,16 @@ func (db *DB) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address)
 	out, err := db.get(mode, addr)
 	if err != nil {
 		if errors.Is(err, leveldb.ErrNotFound) {
+			if mode == storage.ModeGetRequest {
+				db.metrics.GCPolicyMisses.WithLabelValues(db.gcPolicy.name()).Inc()
+			}
 			return nil, storage.ErrNotFound
 		}
 		return nil, err
 	}
+	if mode == storage.ModeGetRequest {
+		db.metrics.GCPolicyHits.WithLabelValues(db.gcPolicy.name()).Inc()
+	}
 	return swarm.NewChunk(swarm.NewAddress(out.Address), out.Data).
 		WithStamp(postage.NewStamp(out.BatchID, out.Index, out.Timestamp, out.Sig)), nil
 }
@@ -159,6 +165,8 @@ func (db *DB) updateGC(item shed.Item) (err error) {
 	_, err = db.gcIndex.Get(item)
 	item.AccessTimestamp = now()
 	if err == nil {
+		// only the accesses of the gc index items are counted
+		db.gcPolicy.accessed(item)
 		err = db.gcIndex.PutInBatch(batch, item)
 		if err != nil {
 			return err
@@ -252,6 +252,7 @@ func (db *DB) setRemove(batch *leveldb.Batch, item shed.Item, check bool) (gcSiz
 	if err != nil {
 		return 0, err
 	}
+	db.gcPolicy.removed(item)
 	return -1, nil
 }
 
@@ -290,6 +291,7 @@ func (db *DB) setPin(batch *leveldb.Batch, item shed.Item) (gcSizeChange int64,
 			if err != nil {
 				return 0, err
 			}
+			db.gcPolicy.removed(item)
 			gcSizeChange = -1
 		}
 	}
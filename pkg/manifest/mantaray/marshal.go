@@ -85,6 +85,21 @@ var (
 
 var obfuscationKeyFn = rand.Read
 
+// NodeHeaderSize is the size of the header of a serialised node
+// holding its obfuscation key and version hash.
+const NodeHeaderSize = nodeHeaderSize
+
+// IsNodeHeader reports whether the data starts with the header of a
+// serialised node of a known version.
+func IsNodeHeader(data []byte) bool {
+	if len(data) < nodeHeaderSize {
+		return false
+	}
+	obfuscationKey := data[:nodeObfuscationKeySize]
+	versionHash := encryptDecrypt(data[nodeObfuscationKeySize:nodeObfuscationKeySize+versionHashSize], obfuscationKey)
+	return bytes.Equal(versionHash, version01HashBytes) || bytes.Equal(versionHash, version02HashBytes)
+}
+
 // SetObfuscationKeyFn allows configuring custom function for generating
 // obfuscation key.
 //
@@ -26,9 +26,12 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethersphere/bee/pkg/accessstats"
 	"github.com/ethersphere/bee/pkg/accounting"
+	"github.com/ethersphere/bee/pkg/act"
 	"github.com/ethersphere/bee/pkg/addressbook"
 	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/auth"
 	"github.com/ethersphere/bee/pkg/config"
 	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/debugapi"
@@ -50,6 +53,7 @@ import (
 	"github.com/ethersphere/bee/pkg/pricer"
 	"github.com/ethersphere/bee/pkg/pricing"
 	"github.com/ethersphere/bee/pkg/pss"
+	"github.com/ethersphere/bee/pkg/pss/mailboxprotocol"
 	"github.com/ethersphere/bee/pkg/puller"
 	"github.com/ethersphere/bee/pkg/pullsync"
 	"github.com/ethersphere/bee/pkg/pullsync/pullstorage"
@@ -73,6 +77,7 @@ import (
 	"github.com/ethersphere/bee/pkg/tracing"
 	"github.com/ethersphere/bee/pkg/transaction"
 	"github.com/ethersphere/bee/pkg/traversal"
+	"github.com/ethersphere/bee/pkg/webhook"
 	"github.com/hashicorp/go-multierror"
 	ma "github.com/multiformats/go-multiaddr"
 	"github.com/sirupsen/logrus"
@@ -85,6 +90,7 @@ type Bee struct {
 	p2pHalter                p2p.Halter
 	p2pCancel                context.CancelFunc
 	apiCloser                io.Closer
+	stewardCloser            io.Closer
 	apiServer                *http.Server
 	debugAPIServer           *http.Server
 	resolverCloser           io.Closer
@@ -107,6 +113,7 @@ type Bee struct {
 	listenerCloser           io.Closer
 	postageServiceCloser     io.Closer
 	priceOracleCloser        io.Closer
+	webhookCloser            io.Closer
 	shutdownInProgress       bool
 	shutdownMutex            sync.Mutex
 }
@@ -114,6 +121,8 @@ type Bee struct {
 type Options struct {
 	DataDir                    string
 	CacheCapacity              uint64
+	CacheGCPolicy              string
+	CacheGCProtect             time.Duration
 	DBOpenFilesLimit           uint64
 	DBWriteBufferSize          uint64
 	DBBlockCacheCapacity       uint64
@@ -133,11 +142,15 @@ type Options struct {
 	TracingEndpoint            string
 	TracingServiceName         string
 	GlobalPinningEnabled       bool
+	PssMailboxEnabled          bool
 	PaymentThreshold           string
 	PaymentTolerance           string
 	PaymentEarly               string
 	ResolverConnectionCfgs     []multiresolver.ConnectionConfig
 	GatewayMode                bool
+	GatewayMaxUploadSize       int64
+	GatewayContentTypes        []string
+	GatewayMaxManifestFiles    int
 	BootnodeMode               bool
 	SwapEndpoint               string
 	SwapFactoryAddress         string
@@ -153,6 +166,20 @@ type Options struct {
 	DeployGasPrice             string
 	WarmupTime                 time.Duration
 	ChainID                    int64
+	WebhookEndpoints           []string
+	WebhookBatchTTLThreshold   time.Duration
+	WebhookChequebookThreshold string
+	Restricted                 bool
+	AdminPasswordHash          string
+	RateLimitWindow            time.Duration
+	RateLimitIPRequests        int64
+	RateLimitIPUpload          int64
+	RateLimitIPDownload        int64
+	RateLimitTokenRequests     int64
+	RateLimitTokenUpload       int64
+	RateLimitTokenDownload     int64
+	RateLimitTrustedProxies    []string
+	AccessStatsSampleRate      int
 }
 
 const (
@@ -233,6 +260,22 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 		}
 	}
 
+	var authenticator *auth.Authenticator
+	if o.Restricted {
+		authenticator, err = auth.New(o.AdminPasswordHash, stateStore)
+		if err != nil {
+			return nil, fmt.Errorf("authenticator: %w", err)
+		}
+		logger.Info("api access restricted")
+	}
+
+	var accessStats *accessstats.Service
+	if o.AccessStatsSampleRate > 0 {
+		accessStats = accessstats.New(accessstats.Options{
+			SampleRate: o.AccessStatsSampleRate,
+		})
+	}
+
 	var debugAPIService *debugapi.Service
 	if o.DebugAPIAddr != "" {
 		overlayEthAddress, err := signer.EthereumAddress()
@@ -240,7 +283,7 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 			return nil, fmt.Errorf("eth address: %w", err)
 		}
 		// set up basic debug api endpoints for debugging and /health endpoint
-		debugAPIService = debugapi.New(*publicKey, pssPrivateKey.PublicKey, overlayEthAddress, logger, tracer, o.CORSAllowedOrigins, transactionService)
+		debugAPIService = debugapi.New(*publicKey, pssPrivateKey.PublicKey, overlayEthAddress, logger, tracer, o.CORSAllowedOrigins, authenticator, stateStore, accessStats, transactionService)
 
 		debugAPIListener, err := net.Listen("tcp", o.DebugAPIAddr)
 		if err != nil {
@@ -353,6 +396,21 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 		return nil, err
 	}
 
+	var webhookService *webhook.Service
+	if len(o.WebhookEndpoints) > 0 {
+		webhookService, err = webhook.New(stateStore, signer, swarmAddress, logger, webhook.Options{
+			Endpoints: o.WebhookEndpoints,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("webhook: %w", err)
+		}
+		b.webhookCloser = webhookService
+
+		if chequeStore != nil {
+			chequeStore = webhookService.ChequeStore(chequeStore)
+		}
+	}
+
 	lightNodes := lightnode.NewContainer(swarmAddress)
 
 	senderMatcher := transaction.NewMatcher(swapBackend, types.NewEIP155Signer(big.NewInt(chainID)), stateStore)
@@ -372,6 +430,9 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 	}
 	b.p2pService = p2ps
 	b.p2pHalter = p2ps
+	if webhookService != nil {
+		p2ps.SetBlocklistHandler(webhookService.PeerBlocklisted)
+	}
 
 	var unreserveFn func([]byte, uint8) (uint64, error)
 	var evictFn = func(b []byte) error {
@@ -399,6 +460,8 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 		BlockCacheCapacity:     o.DBBlockCacheCapacity,
 		WriteBufferSize:        o.DBWriteBufferSize,
 		DisableSeeksCompaction: o.DBDisableSeeksCompaction,
+		GCPolicy:               o.CacheGCPolicy,
+		GCProtect:              o.CacheGCProtect,
 	}
 
 	storer, err := localstore.New(path, swarmAddress.Bytes(), stateStore, lo, logger)
@@ -414,6 +477,7 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 		return nil, fmt.Errorf("postage service load: %w", err)
 	}
 	b.postageServiceCloser = post
+	batchStore.SetBatchExpiryHandler(post)
 
 	var (
 		postageContractService postagecontract.Interface
@@ -515,7 +579,11 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 	b.topologyHalter = kad
 	hive.SetAddPeersHandler(kad.AddPeers)
 	p2ps.SetPickyNotifier(kad)
-	batchStore.SetRadiusSetter(kad)
+	if webhookService != nil {
+		batchStore.SetRadiusSetter(webhookService.RadiusSetter(kad))
+	} else {
+		batchStore.SetRadiusSetter(kad)
+	}
 
 	if batchSvc != nil {
 		syncedChan, err := batchSvc.Start(postageSyncStart)
@@ -623,12 +691,49 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 	pricing.SetPaymentThresholdObserver(acc)
 
 	retrieve := retrieval.New(swarmAddress, storer, p2ps, kad, logger, acc, pricer, tracer)
+	retrieve.SetAccessStats(accessStats)
 	tagService := tags.NewTags(stateStore, logger)
 	b.tagsCloser = tagService
 
-	pssService := pss.New(pssPrivateKey, logger)
+	if webhookService != nil {
+		tagService.SetSyncedHandler(webhookService.TagSynced)
+
+		var chequebookThreshold *big.Int
+		if o.WebhookChequebookThreshold != "" {
+			chequebookThreshold, ok = new(big.Int).SetString(o.WebhookChequebookThreshold, 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid webhook chequebook threshold: %s", o.WebhookChequebookThreshold)
+			}
+		}
+		webhookService.StartMonitor(webhook.MonitorOptions{
+			Post:                post,
+			BatchStore:          batchStore,
+			BlockTime:           time.Duration(o.BlockTime) * time.Second,
+			BatchTTLThreshold:   o.WebhookBatchTTLThreshold,
+			Chequebook:          chequebookService,
+			ChequebookThreshold: chequebookThreshold,
+		})
+	}
+
+	var pssMailbox *pss.Mailbox
+	if o.PssMailboxEnabled {
+		pssMailbox, err = pss.NewMailbox(stateStore, pss.DefaultMailboxCapacity, pss.DefaultMailboxTTL)
+		if err != nil {
+			return nil, fmt.Errorf("pss mailbox: %w", err)
+		}
+	}
+
+	pssService := pss.New(pssPrivateKey, swarmAddress, pssMailbox, logger)
 	b.pssCloser = pssService
 
+	// the mailboxes of the neighbourhood peers are queried for the messages
+	// received while the node was offline even if it keeps no mailbox itself
+	mailboxProtocol := mailboxprotocol.New(p2ps, pssMailbox, kad, logger)
+	if err = p2ps.AddProtocol(mailboxProtocol.Protocol()); err != nil {
+		return nil, fmt.Errorf("mailbox service: %w", err)
+	}
+	pssService.SetMailboxFetcher(mailboxProtocol)
+
 	var ns storage.Storer
 	if o.GlobalPinningEnabled {
 		// create recovery callback for content repair
@@ -642,7 +747,7 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 
 	pinningService := pinning.NewService(storer, stateStore, traversalService)
 
-	pushSyncProtocol := pushsync.New(swarmAddress, blockHash, p2ps, storer, kad, tagService, o.FullNodeMode, pssService.TryUnwrap, validStamp, logger, acc, pricer, signer, tracer, warmupTime)
+	pushSyncProtocol := pushsync.New(swarmAddress, blockHash, p2ps, storer, kad, tagService, o.FullNodeMode, pssService, validStamp, logger, acc, pricer, signer, tracer, warmupTime)
 
 	// set the pushSyncer in the PSS
 	pssService.SetPushSyncer(pushSyncProtocol)
@@ -699,12 +804,36 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 	var apiService api.Service
 	if o.APIAddr != "" {
 		// API server
+		trustedProxies, err := api.ParseTrustedProxies(o.RateLimitTrustedProxies)
+		if err != nil {
+			return nil, fmt.Errorf("rate limit trusted proxies: %w", err)
+		}
 		feedFactory := factory.New(ns)
-		steward := steward.New(storer, traversalService, pushSyncProtocol)
-		apiService = api.New(tagService, ns, multiResolver, pssService, traversalService, pinningService, feedFactory, post, postageContractService, steward, signer, logger, tracer, api.Options{
-			CORSAllowedOrigins: o.CORSAllowedOrigins,
-			GatewayMode:        o.GatewayMode,
-			WsPingPeriod:       60 * time.Second,
+		steward, err := steward.New(stateStore, tagService, storer, traversalService, retrieve, pushSyncProtocol, logger)
+		if err != nil {
+			return nil, fmt.Errorf("steward: %w", err)
+		}
+		b.stewardCloser = steward
+		apiService = api.New(tagService, ns, multiResolver, pssService, traversalService, pinningService, feedFactory, post, batchStore, postageContractService, steward, act.New(pssPrivateKey), accessStats, authenticator, signer, logger, tracer, api.Options{
+			CORSAllowedOrigins:      o.CORSAllowedOrigins,
+			GatewayMode:             o.GatewayMode,
+			GatewayMaxUploadSize:    o.GatewayMaxUploadSize,
+			GatewayContentTypes:     o.GatewayContentTypes,
+			GatewayMaxManifestFiles: o.GatewayMaxManifestFiles,
+			WsPingPeriod:            60 * time.Second,
+			BlockTime:               time.Duration(o.BlockTime) * time.Second,
+			RateLimitWindow:         o.RateLimitWindow,
+			RateLimitIP: api.RateLimits{
+				Requests:      o.RateLimitIPRequests,
+				UploadBytes:   o.RateLimitIPUpload,
+				DownloadBytes: o.RateLimitIPDownload,
+			},
+			RateLimitToken: api.RateLimits{
+				Requests:      o.RateLimitTokenRequests,
+				UploadBytes:   o.RateLimitTokenUpload,
+				DownloadBytes: o.RateLimitTokenDownload,
+			},
+			RateLimitTrustedProxies: trustedProxies,
 		})
 		apiListener, err := net.Listen("tcp", o.APIAddr)
 		if err != nil {
@@ -778,7 +907,7 @@ func NewBee(addr string, publicKey *ecdsa.PublicKey, signer crypto.Signer, netwo
 		}
 
 		// inject dependencies and configure full debug api http path routes
-		debugAPIService.Configure(swarmAddress, p2ps, pingPong, kad, lightNodes, storer, tagService, acc, pseudosettleService, o.SwapEnable, swapService, chequebookService, batchStore, post, postageContractService)
+		debugAPIService.Configure(swarmAddress, p2ps, pingPong, kad, lightNodes, storer, tagService, acc, pseudosettleService, o.SwapEnable, swapService, chequebookService, batchStore, post, postageContractService, time.Duration(o.BlockTime)*time.Second)
 	}
 
 	if err := kad.Start(p2pCtx); err != nil {
@@ -843,6 +972,8 @@ func (b *Bee) Shutdown(ctx context.Context) error {
 		mErr = multierror.Append(mErr, err)
 	}
 
+	tryClose(b.stewardCloser, "steward")
+
 	if b.recoveryHandleCleanup != nil {
 		b.recoveryHandleCleanup()
 	}
@@ -873,6 +1004,7 @@ func (b *Bee) Shutdown(ctx context.Context) error {
 
 	wg.Wait()
 
+	tryClose(b.webhookCloser, "webhook")
 	tryClose(b.p2pService, "p2p server")
 	tryClose(b.priceOracleCloser, "price oracle service")
 
@@ -66,6 +66,7 @@ type Service struct {
 	blocklist         *blocklist.Blocklist
 	protocols         []p2p.ProtocolSpec
 	notifier          p2p.PickyNotifier
+	blocklistHandler  func(swarm.Address, time.Duration)
 	logger            logging.Logger
 	tracer            *tracing.Tracer
 	ready             chan struct{}
@@ -414,6 +415,11 @@ func (s *Service) SetPickyNotifier(n p2p.PickyNotifier) {
 	s.notifier = n
 }
 
+// SetBlocklistHandler sets the function called when a peer is blocklisted.
+func (s *Service) SetBlocklistHandler(f func(overlay swarm.Address, duration time.Duration)) {
+	s.blocklistHandler = f
+}
+
 func (s *Service) AddProtocol(p p2p.ProtocolSpec) (err error) {
 	for _, ss := range p.StreamSpecs {
 		ss := ss
@@ -527,6 +533,9 @@ func (s *Service) Blocklist(overlay swarm.Address, duration time.Duration) error
 		return fmt.Errorf("blocklist peer %s: %v", overlay, err)
 	}
 	s.metrics.BlocklistedPeerCount.Inc()
+	if s.blocklistHandler != nil {
+		s.blocklistHandler(overlay, duration)
+	}
 
 	_ = s.Disconnect(overlay)
 	return nil
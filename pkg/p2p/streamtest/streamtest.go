@@ -314,7 +314,8 @@ func (r *record) Read(p []byte) (n int, err error) {
 	}
 	n = copy(p, r.b[r.c:end])
 	r.c += n
-	if r.Closed() {
+	if r.c == len(r.b) && r.Closed() {
+		// the data written before the record was closed is read first
 		err = io.EOF
 	}
 
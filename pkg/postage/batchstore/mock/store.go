@@ -146,6 +146,7 @@ func (bs *BatchStore) Unreserve(_ postage.UnreserveIteratorFn) error {
 func (bs *BatchStore) SetRadiusSetter(r postage.RadiusSetter) {
 	panic("not implemented")
 }
+func (bs *BatchStore) SetBatchExpiryHandler(_ postage.BatchExpiryHandler) {}
 
 func (bs *BatchStore) Reset() error {
 	bs.resetCallCount++
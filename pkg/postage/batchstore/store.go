@@ -42,7 +42,8 @@ type store struct {
 	metrics     metrics       // metrics
 	logger      logging.Logger
 
-	radiusSetter postage.RadiusSetter // setter for radius notifications
+	radiusSetter  postage.RadiusSetter       // setter for radius notifications
+	expiryHandler postage.BatchExpiryHandler // handler of removed batches
 }
 
 // New constructs a new postage batch store.
@@ -164,6 +165,9 @@ func (s *store) delete(ids ...[]byte) error {
 		if err != nil {
 			return err
 		}
+		if s.expiryHandler != nil {
+			s.expiryHandler.HandleStampExpiry(id)
+		}
 	}
 	return nil
 }
@@ -198,6 +202,11 @@ func (s *store) SetRadiusSetter(r postage.RadiusSetter) {
 	s.radiusSetter = r
 }
 
+// SetBatchExpiryHandler sets the handler notified of the removed batches.
+func (s *store) SetBatchExpiryHandler(h postage.BatchExpiryHandler) {
+	s.expiryHandler = h
+}
+
 func (s *store) Reset() error {
 	prefix := "batchstore_"
 	if err := s.store.Iterate(prefix, func(k, _ []byte) (bool, error) {
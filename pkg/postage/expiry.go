@@ -0,0 +1,45 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postage
+
+import (
+	"math"
+	"math/big"
+	"time"
+)
+
+// ExpiryBlock estimates the block number at which a batch with the given
+// normalised balance expires if the price of the chain state does not
+// change. The batch is expired if the returned block number is not greater
+// than the chain state block. The estimate is not available, indicated by
+// ok being false, while the price is not known.
+func ExpiryBlock(cs *ChainState, normalisedBalance *big.Int) (block uint64, ok bool) {
+	if cs == nil || cs.CurrentPrice == nil || cs.CurrentPrice.Sign() <= 0 || normalisedBalance == nil {
+		return 0, false
+	}
+	total := cs.TotalAmount
+	if total == nil {
+		total = big.NewInt(0)
+	}
+	remaining := new(big.Int).Sub(normalisedBalance, total)
+	if remaining.Sign() <= 0 {
+		return cs.Block, true
+	}
+	blocks := remaining.Div(remaining, cs.CurrentPrice)
+	if !blocks.IsUint64() || blocks.Uint64() > math.MaxUint64-cs.Block {
+		return math.MaxUint64, true
+	}
+	return cs.Block + blocks.Uint64(), true
+}
+
+// TTL returns the number of seconds the given number of blocks last for,
+// capped to math.MaxInt64.
+func TTL(blocks uint64, blockTime time.Duration) int64 {
+	seconds := uint64(blockTime / time.Second)
+	if seconds != 0 && blocks > math.MaxInt64/seconds {
+		return math.MaxInt64
+	}
+	return int64(blocks * seconds)
+}
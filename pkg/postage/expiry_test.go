@@ -0,0 +1,63 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postage_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/postage"
+)
+
+func TestExpiryBlock(t *testing.T) {
+	for _, tc := range []struct {
+		name              string
+		cs                *postage.ChainState
+		normalisedBalance *big.Int
+		wantBlock         uint64
+		wantOk            bool
+	}{
+		{
+			name:              "no price",
+			cs:                &postage.ChainState{Block: 10, TotalAmount: big.NewInt(100)},
+			normalisedBalance: big.NewInt(200),
+		},
+		{
+			name:              "alive",
+			cs:                &postage.ChainState{Block: 10, TotalAmount: big.NewInt(100), CurrentPrice: big.NewInt(4)},
+			normalisedBalance: big.NewInt(202),
+			wantBlock:         35,
+			wantOk:            true,
+		},
+		{
+			name:              "expired",
+			cs:                &postage.ChainState{Block: 10, TotalAmount: big.NewInt(300), CurrentPrice: big.NewInt(4)},
+			normalisedBalance: big.NewInt(200),
+			wantBlock:         10,
+			wantOk:            true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			block, ok := postage.ExpiryBlock(tc.cs, tc.normalisedBalance)
+			if ok != tc.wantOk {
+				t.Fatalf("got ok %t, want %t", ok, tc.wantOk)
+			}
+			if block != tc.wantBlock {
+				t.Fatalf("got block %d, want %d", block, tc.wantBlock)
+			}
+		})
+	}
+}
+
+func TestTTL(t *testing.T) {
+	if got := postage.TTL(10, 5*time.Second); got != 50 {
+		t.Fatalf("got ttl %d, want %d", got, 50)
+	}
+	if got := postage.TTL(math.MaxUint64, 5*time.Second); got != math.MaxInt64 {
+		t.Fatalf("got ttl %d, want %d", got, int64(math.MaxInt64))
+	}
+}
@@ -34,6 +34,7 @@ type Storer interface {
 	GetChainState() *ChainState
 	GetReserveState() *ReserveState
 	SetRadiusSetter(RadiusSetter)
+	SetBatchExpiryHandler(BatchExpiryHandler)
 	Unreserve(UnreserveIteratorFn) error
 
 	Reset() error
@@ -52,3 +53,8 @@ type Listener interface {
 type BatchCreationListener interface {
 	Handle(*Batch)
 }
+
+// BatchExpiryHandler is notified of the batches removed from the batch store.
+type BatchExpiryHandler interface {
+	HandleStampExpiry([]byte)
+}
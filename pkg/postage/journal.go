@@ -0,0 +1,104 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postage
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+const (
+	bucketJournalPrefix = "postagebuckets"
+
+	// bucketJournalStepRatio is the part of the bucket upper bound the
+	// journal reserves ahead at once, so that the bucket count is only
+	// journalled every step and not for every stamped chunk. At most a
+	// step of indexes per bucket is skipped after an unclean shutdown.
+	bucketJournalStepRatio = 16
+)
+
+// bucketJournal persists the collision bucket counts of a stamp issuer to the
+// state store ahead of their use. Stamp issuers themselves are only saved
+// when they change or the service is closed, the journal is used to recover
+// the counts that were issued after that in case the node did not shut down
+// cleanly. The journal is cleared whenever the issuer is saved.
+type bucketJournal struct {
+	store    storage.StateStorer
+	prefix   string
+	reserved map[uint32]uint32 // bucket counts journalled since the last clear
+}
+
+// newBucketJournal constructs a journal for the batch with the given ID.
+// The journal key prefix must not be a prefix of the issuer keys, see
+// service.key.
+func newBucketJournal(store storage.StateStorer, chainID int64, batchID []byte) *bucketJournal {
+	return &bucketJournal{
+		store:    store,
+		prefix:   fmt.Sprintf("%s%d_%x_", bucketJournalPrefix, chainID, batchID),
+		reserved: make(map[uint32]uint32),
+	}
+}
+
+// reserve makes sure that the count of the bucket is journalled before it
+// is used. If the count is beyond the one already journalled, the count a
+// step ahead, but not beyond the upper bound, is journalled.
+func (j *bucketJournal) reserve(bucket, count, upperBound uint32) error {
+	if count <= j.reserved[bucket] {
+		return nil
+	}
+	step := upperBound / bucketJournalStepRatio
+	if step == 0 {
+		step = 1
+	}
+	reserved := count - 1 + step
+	if reserved > upperBound || reserved < count {
+		reserved = upperBound
+	}
+	if err := j.store.Put(j.prefix+strconv.FormatUint(uint64(bucket), 10), reserved); err != nil {
+		return fmt.Errorf("journal bucket %d: %w", bucket, err)
+	}
+	j.reserved[bucket] = reserved
+	return nil
+}
+
+// clear deletes the journalled bucket counts, it must only be called once
+// the counts are saved with the issuer.
+func (j *bucketJournal) clear() error {
+	var keys []string
+	if err := j.store.Iterate(j.prefix, func(key, _ []byte) (bool, error) {
+		keys = append(keys, string(key))
+		return false, nil
+	}); err != nil {
+		return fmt.Errorf("iterate journal: %w", err)
+	}
+	for _, k := range keys {
+		if err := j.store.Delete(k); err != nil {
+			return fmt.Errorf("delete journal entry %q: %w", k, err)
+		}
+	}
+	j.reserved = make(map[uint32]uint32)
+	return nil
+}
+
+// iterate calls f with every journalled bucket count.
+func (j *bucketJournal) iterate(f func(bucket, count uint32)) error {
+	return j.store.Iterate(j.prefix, func(key, value []byte) (bool, error) {
+		k := string(key)
+		bucket, err := strconv.ParseUint(strings.TrimPrefix(k, j.prefix), 10, 32)
+		if err != nil {
+			return true, fmt.Errorf("parse journal key %q: %w", k, err)
+		}
+		var count uint32
+		if err := json.Unmarshal(value, &count); err != nil {
+			return true, fmt.Errorf("unmarshal journal entry %q: %w", k, err)
+		}
+		f(uint32(bucket), count)
+		return false, nil
+	})
+}
@@ -45,11 +45,19 @@ type mockPostage struct {
 	acceptAll bool
 }
 
-func (m *mockPostage) Add(s *postage.StampIssuer) {
+func (m *mockPostage) Add(s *postage.StampIssuer) error {
 	m.i = s
+	return nil
+}
+
+func (m *mockPostage) Save(_ *postage.StampIssuer) error {
+	return nil
 }
 
 func (m *mockPostage) StampIssuers() []*postage.StampIssuer {
+	if m.i == nil {
+		return nil
+	}
 	return []*postage.StampIssuer{m.i}
 }
 
@@ -71,6 +79,8 @@ func (m *mockPostage) IssuerUsable(_ *postage.StampIssuer) bool {
 
 func (m *mockPostage) Handle(_ *postage.Batch) {}
 
+func (m *mockPostage) HandleStampExpiry(_ []byte) {}
+
 func (m *mockPostage) Close() error {
 	return nil
 }
@@ -5,6 +5,7 @@
 package postagecontract
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"errors"
@@ -28,14 +29,21 @@ var (
 	postageStampABI   = parseABI(postageabi.PostageStampABIv0_3_0)
 	erc20ABI          = parseABI(sw3abi.ERC20ABIv0_3_1)
 	batchCreatedTopic = postageStampABI.Events["BatchCreated"].ID
+	batchTopUpTopic   = postageStampABI.Events["BatchTopUp"].ID
+	batchDiluteTopic  = postageStampABI.Events["BatchDepthIncrease"].ID
 
 	ErrBatchCreate       = errors.New("batch creation failed")
+	ErrBatchTopUp        = errors.New("batch topup failed")
+	ErrBatchDilute       = errors.New("batch dilute failed")
+	ErrNotFound          = errors.New("batch not found")
 	ErrInsufficientFunds = errors.New("insufficient token balance")
 	ErrInvalidDepth      = errors.New("invalid depth")
 )
 
 type Interface interface {
 	CreateBatch(ctx context.Context, initialBalance *big.Int, depth uint8, immutable bool, label string) ([]byte, error)
+	TopUpBatch(ctx context.Context, batchID []byte, topupBalance *big.Int) error
+	DiluteBatch(ctx context.Context, batchID []byte, newDepth uint8) error
 }
 
 type postageContract struct {
@@ -123,6 +131,70 @@ func (c *postageContract) sendCreateBatchTransaction(ctx context.Context, owner
 	return receipt, nil
 }
 
+func (c *postageContract) sendTopUpBatchTransaction(ctx context.Context, batchID []byte, topupBalance *big.Int) (*types.Receipt, error) {
+
+	callData, err := postageStampABI.Pack("topUp", common.BytesToHash(batchID), topupBalance)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &transaction.TxRequest{
+		To:       &c.postageContractAddress,
+		Data:     callData,
+		GasPrice: sctx.GetGasPrice(ctx),
+		GasLimit: 100000,
+		Value:    big.NewInt(0),
+	}
+
+	txHash, err := c.transactionService.Send(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("send: topup amount %d: %w", topupBalance, err)
+	}
+
+	receipt, err := c.transactionService.WaitForReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if receipt.Status == 0 {
+		return nil, transaction.ErrTransactionReverted
+	}
+
+	return receipt, nil
+}
+
+func (c *postageContract) sendDiluteTransaction(ctx context.Context, batchID []byte, newDepth uint8) (*types.Receipt, error) {
+
+	callData, err := postageStampABI.Pack("increaseDepth", common.BytesToHash(batchID), newDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &transaction.TxRequest{
+		To:       &c.postageContractAddress,
+		Data:     callData,
+		GasPrice: sctx.GetGasPrice(ctx),
+		GasLimit: 100000,
+		Value:    big.NewInt(0),
+	}
+
+	txHash, err := c.transactionService.Send(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("send: depth %d: %w", newDepth, err)
+	}
+
+	receipt, err := c.transactionService.WaitForReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if receipt.Status == 0 {
+		return nil, transaction.ErrTransactionReverted
+	}
+
+	return receipt, nil
+}
+
 func (c *postageContract) getBalance(ctx context.Context) (*big.Int, error) {
 	callData, err := erc20ABI.Pack("balanceOf", c.owner)
 	if err != nil {
@@ -186,7 +258,7 @@ func (c *postageContract) CreateBatch(ctx context.Context, initialBalance *big.I
 
 			batchID := createdEvent.BatchId[:]
 
-			c.postageService.Add(postage.NewStampIssuer(
+			err = c.postageService.Add(postage.NewStampIssuer(
 				label,
 				c.owner.Hex(),
 				batchID,
@@ -196,6 +268,9 @@ func (c *postageContract) CreateBatch(ctx context.Context, initialBalance *big.I
 				ev.BlockNumber,
 				createdEvent.ImmutableFlag,
 			))
+			if err != nil {
+				return nil, err
+			}
 
 			return createdEvent.BatchId[:], nil
 		}
@@ -204,6 +279,92 @@ func (c *postageContract) CreateBatch(ctx context.Context, initialBalance *big.I
 	return nil, ErrBatchCreate
 }
 
+// stampIssuer returns the local stamp issuer of the batch regardless of
+// whether it is usable yet.
+func (c *postageContract) stampIssuer(batchID []byte) (*postage.StampIssuer, error) {
+	for _, si := range c.postageService.StampIssuers() {
+		if si != nil && bytes.Equal(si.ID(), batchID) {
+			return si, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (c *postageContract) TopUpBatch(ctx context.Context, batchID []byte, topupBalance *big.Int) error {
+
+	si, err := c.stampIssuer(batchID)
+	if err != nil {
+		return err
+	}
+
+	totalAmount := big.NewInt(0).Mul(topupBalance, big.NewInt(int64(1<<si.Depth())))
+	balance, err := c.getBalance(ctx)
+	if err != nil {
+		return err
+	}
+
+	if balance.Cmp(totalAmount) < 0 {
+		return ErrInsufficientFunds
+	}
+
+	_, err = c.sendApproveTransaction(ctx, totalAmount)
+	if err != nil {
+		return err
+	}
+
+	receipt, err := c.sendTopUpBatchTransaction(ctx, batchID, topupBalance)
+	if err != nil {
+		return err
+	}
+
+	for _, ev := range receipt.Logs {
+		if ev.Address == c.postageContractAddress && ev.Topics[0] == batchTopUpTopic {
+			si.TopUp(topupBalance)
+			if err := c.postageService.Save(si); err != nil {
+				return fmt.Errorf("save stamp issuer: %w", err)
+			}
+			return nil
+		}
+	}
+
+	return ErrBatchTopUp
+}
+
+func (c *postageContract) DiluteBatch(ctx context.Context, batchID []byte, newDepth uint8) error {
+
+	si, err := c.stampIssuer(batchID)
+	if err != nil {
+		return err
+	}
+
+	if newDepth <= si.Depth() {
+		return ErrInvalidDepth
+	}
+
+	receipt, err := c.sendDiluteTransaction(ctx, batchID, newDepth)
+	if err != nil {
+		return err
+	}
+
+	for _, ev := range receipt.Logs {
+		if ev.Address == c.postageContractAddress && ev.Topics[0] == batchDiluteTopic {
+			var diluteEvent batchDiluteEvent
+			err = transaction.ParseEvent(&postageStampABI, "BatchDepthIncrease", &diluteEvent, *ev)
+			if err != nil {
+				return err
+			}
+
+			si.Dilute(diluteEvent.NewDepth)
+			if err := c.postageService.Save(si); err != nil {
+				return fmt.Errorf("save stamp issuer: %w", err)
+			}
+			return nil
+		}
+	}
+
+	return ErrBatchDilute
+}
+
 type batchCreatedEvent struct {
 	BatchId           [32]byte
 	TotalAmount       *big.Int
@@ -214,6 +375,12 @@ type batchCreatedEvent struct {
 	ImmutableFlag     bool
 }
 
+type batchDiluteEvent struct {
+	BatchId           [32]byte
+	NewDepth          uint8
+	NormalisedBalance *big.Int
+}
+
 func parseABI(json string) abi.ABI {
 	cabi, err := abi.JSON(strings.NewReader(json))
 	if err != nil {
@@ -14,8 +14,12 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/pkg/postage"
+	pstoremock "github.com/ethersphere/bee/pkg/postage/batchstore/mock"
 	postageMock "github.com/ethersphere/bee/pkg/postage/mock"
 	"github.com/ethersphere/bee/pkg/postage/postagecontract"
+	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/transaction"
 	transactionMock "github.com/ethersphere/bee/pkg/transaction/mock"
 )
@@ -168,6 +172,261 @@ func newCreateEvent(postageContractAddress common.Address, batchId common.Hash)
 	}
 }
 
+func TestTopUpBatch(t *testing.T) {
+	owner := common.HexToAddress("abcd")
+	postageStampAddress := common.HexToAddress("ffff")
+	bzzTokenAddress := common.HexToAddress("eeee")
+	ctx := context.Background()
+	topupBalance := big.NewInt(100)
+
+	t.Run("ok", func(t *testing.T) {
+
+		totalAmount := big.NewInt(102400)
+		txHashApprove := common.HexToHash("abb0")
+		txHashTopup := common.HexToHash("c3a7")
+		batchID := common.HexToHash("dddd")
+		si := postage.NewStampIssuer("label", owner.Hex(), batchID[:], big.NewInt(3), 10, 9, 0, false)
+		store := storemock.NewStateStore()
+		postageService := newPostageService(t, store, si)
+
+		expectedCallData, err := postagecontract.PostageStampABI.Pack("topUp", batchID, topupBalance)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		contract := postagecontract.New(
+			owner,
+			postageStampAddress,
+			bzzTokenAddress,
+			transactionMock.New(
+				transactionMock.WithSendFunc(func(ctx context.Context, request *transaction.TxRequest) (txHash common.Hash, err error) {
+					if *request.To == bzzTokenAddress {
+						return txHashApprove, nil
+					} else if *request.To == postageStampAddress {
+						if !bytes.Equal(expectedCallData, request.Data) {
+							return common.Hash{}, fmt.Errorf("got wrong call data. wanted %x, got %x", expectedCallData, request.Data)
+						}
+						return txHashTopup, nil
+					}
+					return common.Hash{}, errors.New("sent to wrong contract")
+				}),
+				transactionMock.WithWaitForReceiptFunc(func(ctx context.Context, txHash common.Hash) (receipt *types.Receipt, err error) {
+					if txHash == txHashApprove {
+						return &types.Receipt{
+							Status: 1,
+						}, nil
+					} else if txHash == txHashTopup {
+						return &types.Receipt{
+							Logs: []*types.Log{
+								newTopUpEvent(postageStampAddress, batchID),
+							},
+							Status: 1,
+						}, nil
+					}
+					return nil, errors.New("unknown tx hash")
+				}),
+				transactionMock.WithCallFunc(func(ctx context.Context, request *transaction.TxRequest) (result []byte, err error) {
+					if *request.To == bzzTokenAddress {
+						return totalAmount.FillBytes(make([]byte, 32)), nil
+					}
+					return nil, errors.New("unexpected call")
+				}),
+			),
+			postageService,
+		)
+
+		err = contract.TopUpBatch(ctx, batchID[:], topupBalance)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if si.Amount().Cmp(big.NewInt(103)) != 0 {
+			t.Fatalf("got wrong stamp issuer amount. wanted %d, got %d", 103, si.Amount())
+		}
+
+		// the issuer is saved without closing the service
+		saved := newPostageService(t, store).StampIssuers()[0]
+		if saved.Amount().Cmp(big.NewInt(103)) != 0 {
+			t.Fatalf("got wrong saved stamp issuer amount. wanted %d, got %d", 103, saved.Amount())
+		}
+	})
+
+	t.Run("batch not found", func(t *testing.T) {
+		contract := postagecontract.New(
+			owner,
+			postageStampAddress,
+			bzzTokenAddress,
+			transactionMock.New(),
+			postageMock.New(),
+		)
+
+		err := contract.TopUpBatch(ctx, common.HexToHash("dddd").Bytes(), topupBalance)
+		if !errors.Is(err, postagecontract.ErrNotFound) {
+			t.Fatalf("expected error %v. got %v", postagecontract.ErrNotFound, err)
+		}
+	})
+
+	t.Run("insufficient funds", func(t *testing.T) {
+		totalAmount := big.NewInt(102399)
+		batchID := common.HexToHash("dddd")
+		si := postage.NewStampIssuer("label", owner.Hex(), batchID[:], big.NewInt(3), 10, 9, 0, false)
+
+		contract := postagecontract.New(
+			owner,
+			postageStampAddress,
+			bzzTokenAddress,
+			transactionMock.New(
+				transactionMock.WithCallFunc(func(ctx context.Context, request *transaction.TxRequest) (result []byte, err error) {
+					if *request.To == bzzTokenAddress {
+						return totalAmount.FillBytes(make([]byte, 32)), nil
+					}
+					return nil, errors.New("unexpected call")
+				}),
+			),
+			postageMock.New(postageMock.WithIssuer(si)),
+		)
+
+		err := contract.TopUpBatch(ctx, batchID[:], topupBalance)
+		if !errors.Is(err, postagecontract.ErrInsufficientFunds) {
+			t.Fatalf("expected error %v. got %v", postagecontract.ErrInsufficientFunds, err)
+		}
+	})
+}
+
+func newTopUpEvent(postageContractAddress common.Address, batchId common.Hash) *types.Log {
+	b, err := postagecontract.PostageStampABI.Events["BatchTopUp"].Inputs.NonIndexed().Pack(
+		big.NewInt(0),
+		big.NewInt(0),
+	)
+	if err != nil {
+		panic(err)
+	}
+	return &types.Log{
+		Address: postageContractAddress,
+		Data:    b,
+		Topics:  []common.Hash{postagecontract.BatchTopUpTopic, batchId},
+	}
+}
+
+func TestDiluteBatch(t *testing.T) {
+	owner := common.HexToAddress("abcd")
+	postageStampAddress := common.HexToAddress("ffff")
+	bzzTokenAddress := common.HexToAddress("eeee")
+	ctx := context.Background()
+
+	t.Run("ok", func(t *testing.T) {
+
+		txHashDilute := common.HexToHash("c3a7")
+		batchID := common.HexToHash("dddd")
+		newDepth := uint8(12)
+		si := postage.NewStampIssuer("label", owner.Hex(), batchID[:], big.NewInt(100), 10, 9, 0, false)
+		store := storemock.NewStateStore()
+		postageService := newPostageService(t, store, si)
+
+		expectedCallData, err := postagecontract.PostageStampABI.Pack("increaseDepth", batchID, newDepth)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		contract := postagecontract.New(
+			owner,
+			postageStampAddress,
+			bzzTokenAddress,
+			transactionMock.New(
+				transactionMock.WithSendFunc(func(ctx context.Context, request *transaction.TxRequest) (txHash common.Hash, err error) {
+					if *request.To == postageStampAddress {
+						if !bytes.Equal(expectedCallData, request.Data) {
+							return common.Hash{}, fmt.Errorf("got wrong call data. wanted %x, got %x", expectedCallData, request.Data)
+						}
+						return txHashDilute, nil
+					}
+					return common.Hash{}, errors.New("sent to wrong contract")
+				}),
+				transactionMock.WithWaitForReceiptFunc(func(ctx context.Context, txHash common.Hash) (receipt *types.Receipt, err error) {
+					if txHash == txHashDilute {
+						return &types.Receipt{
+							Logs: []*types.Log{
+								newDiluteEvent(postageStampAddress, batchID, newDepth),
+							},
+							Status: 1,
+						}, nil
+					}
+					return nil, errors.New("unknown tx hash")
+				}),
+			),
+			postageService,
+		)
+
+		err = contract.DiluteBatch(ctx, batchID[:], newDepth)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if si.Depth() != newDepth {
+			t.Fatalf("got wrong stamp issuer depth. wanted %d, got %d", newDepth, si.Depth())
+		}
+		if si.Amount().Cmp(big.NewInt(25)) != 0 {
+			t.Fatalf("got wrong stamp issuer amount. wanted %d, got %d", 25, si.Amount())
+		}
+
+		// the issuer is saved without closing the service
+		saved := newPostageService(t, store).StampIssuers()[0]
+		if saved.Depth() != newDepth {
+			t.Fatalf("got wrong saved stamp issuer depth. wanted %d, got %d", newDepth, saved.Depth())
+		}
+	})
+
+	t.Run("invalid depth", func(t *testing.T) {
+		batchID := common.HexToHash("dddd")
+		si := postage.NewStampIssuer("label", owner.Hex(), batchID[:], big.NewInt(100), 10, 9, 0, false)
+
+		contract := postagecontract.New(
+			owner,
+			postageStampAddress,
+			bzzTokenAddress,
+			transactionMock.New(),
+			postageMock.New(postageMock.WithIssuer(si)),
+		)
+
+		err := contract.DiluteBatch(ctx, batchID[:], 10)
+		if !errors.Is(err, postagecontract.ErrInvalidDepth) {
+			t.Fatalf("expected error %v. got %v", postagecontract.ErrInvalidDepth, err)
+		}
+	})
+}
+
+// newPostageService constructs a postage service on the state store with the
+// stamp issuers added.
+func newPostageService(t *testing.T, store storage.StateStorer, issuers ...*postage.StampIssuer) postage.Service {
+	t.Helper()
+
+	ps, err := postage.NewService(store, pstoremock.New(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, si := range issuers {
+		if err := ps.Add(si); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ps
+}
+
+func newDiluteEvent(postageContractAddress common.Address, batchId common.Hash, newDepth uint8) *types.Log {
+	b, err := postagecontract.PostageStampABI.Events["BatchDepthIncrease"].Inputs.NonIndexed().Pack(
+		newDepth,
+		big.NewInt(0),
+	)
+	if err != nil {
+		panic(err)
+	}
+	return &types.Log{
+		Address: postageContractAddress,
+		Data:    b,
+		Topics:  []common.Hash{postagecontract.BatchDiluteTopic, batchId},
+	}
+}
+
 func TestLookupERC20Address(t *testing.T) {
 	postageStampAddress := common.HexToAddress("ffff")
 	erc20Address := common.HexToAddress("ffff")
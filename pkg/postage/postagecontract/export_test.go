@@ -7,4 +7,6 @@ package postagecontract
 var (
 	PostageStampABI   = postageStampABI
 	BatchCreatedTopic = batchCreatedTopic
+	BatchTopUpTopic   = batchTopUpTopic
+	BatchDiluteTopic  = batchDiluteTopic
 )
@@ -30,11 +30,13 @@ var (
 
 // Service is the postage service interface.
 type Service interface {
-	Add(*StampIssuer)
+	Add(*StampIssuer) error
+	Save(*StampIssuer) error
 	StampIssuers() []*StampIssuer
 	GetStampIssuer([]byte) (*StampIssuer, error)
 	IssuerUsable(*StampIssuer) bool
 	BatchCreationListener
+	BatchExpiryHandler
 	io.Closer
 }
 
@@ -69,29 +71,59 @@ func NewService(store storage.StateStorer, postageStore Storer, chainID int64) (
 		if err != nil {
 			return nil, err
 		}
-		s.Add(st)
+		if err := s.Add(st); err != nil {
+			return nil, err
+		}
 	}
 	return s, nil
 }
 
-// Add adds a stamp issuer to the active issuers.
-func (ps *service) Add(st *StampIssuer) {
+// Add adds a stamp issuer to the active issuers. The collision bucket counts
+// journalled since the issuer was last saved are recovered and the issuer is
+// saved, so that it is not lost if the node does not shut down cleanly.
+// The journal is cleared once the issuer is saved.
+func (ps *service) Add(st *StampIssuer) error {
 	ps.lock.Lock()
 	defer ps.lock.Unlock()
 
 	for _, v := range ps.issuers {
 		if bytes.Equal(st.data.BatchID, v.data.BatchID) {
-			return
+			return nil
 		}
 	}
+
+	st.journal = newBucketJournal(ps.store, ps.chainID, st.data.BatchID)
+	if err := st.recoverBuckets(); err != nil {
+		return fmt.Errorf("recover buckets: %w", err)
+	}
+
 	ps.issuers = append(ps.issuers, st)
+	if err := st.save(ps.store, ps.keyForIndex(len(ps.issuers)-1)); err != nil {
+		return fmt.Errorf("save issuer: %w", err)
+	}
+	return nil
+}
+
+// Save saves an active stamp issuer, so that the changes of the batch
+// parameters are not lost if the node does not shut down cleanly.
+func (ps *service) Save(st *StampIssuer) error {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	for i, v := range ps.issuers {
+		if bytes.Equal(st.data.BatchID, v.data.BatchID) {
+			return st.save(ps.store, ps.keyForIndex(i))
+		}
+	}
+	return ErrNotFound
 }
 
 // Handle implements the BatchCreationListener interface. This is fired on receiving
 // a batch creation event from the blockchain listener to ensure that if a stamp
 // issuer was not created initially, we will create it here.
 func (ps *service) Handle(b *Batch) {
-	ps.Add(NewStampIssuer(
+	// an issuer that could not be saved is kept in memory and saved on Close
+	_ = ps.Add(NewStampIssuer(
 		"recovered",
 		string(b.Owner),
 		b.ID,
@@ -103,6 +135,24 @@ func (ps *service) Handle(b *Batch) {
 	))
 }
 
+// HandleStampExpiry implements the BatchExpiryHandler interface. It clears
+// the bucket journal of the removed batch, as its counts are of no use
+// anymore.
+func (ps *service) HandleStampExpiry(id []byte) {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	for _, st := range ps.issuers {
+		if bytes.Equal(id, st.data.BatchID) {
+			// the entries that could not be deleted are deleted again
+			// when the issuer is saved
+			_ = st.clearJournal()
+			return
+		}
+	}
+	_ = newBucketJournal(ps.store, ps.chainID, id).clear()
+}
+
 // StampIssuers returns the currently active stamp issuers.
 func (ps *service) StampIssuers() []*StampIssuer {
 	ps.lock.Lock()
@@ -138,10 +188,13 @@ func (ps *service) GetStampIssuer(batchID []byte) (*StampIssuer, error) {
 	return nil, ErrNotFound
 }
 
-// Close saves all the active stamp issuers to statestore.
+// Close saves all the active stamp issuers to statestore and clears their
+// journals.
 func (ps *service) Close() error {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
 	for i, st := range ps.issuers {
-		if err := ps.store.Put(ps.keyForIndex(i), st); err != nil {
+		if err := st.save(ps.store, ps.keyForIndex(i)); err != nil {
 			return err
 		}
 	}
@@ -5,16 +5,22 @@
 package postage_test
 
 import (
+	"bytes"
 	crand "crypto/rand"
+	"errors"
 	"io"
 	"math/big"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/postage"
 	pstoremock "github.com/ethersphere/bee/pkg/postage/batchstore/mock"
 	postagetesting "github.com/ethersphere/bee/pkg/postage/testing"
 	storemock "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
 )
 
 // TestSaveLoad tests the idempotence of saving and loading the postage.Service
@@ -28,7 +34,9 @@ func TestSaveLoad(t *testing.T) {
 			t.Fatal(err)
 		}
 		for i := 0; i < 16; i++ {
-			ps.Add(newTestStampIssuer(t, 1000))
+			if err := ps.Add(newTestStampIssuer(t, 1000)); err != nil {
+				t.Fatal(err)
+			}
 		}
 		if err := ps.Close(); err != nil {
 			t.Fatal(err)
@@ -81,7 +89,9 @@ func TestGetStampIssuer(t *testing.T) {
 		if i > 3 {
 			shift = uint64(i)
 		}
-		ps.Add(postage.NewStampIssuer(string(id), "", id, big.NewInt(3), 16, 8, validBlockNumber+shift, true))
+		if err := ps.Add(postage.NewStampIssuer(string(id), "", id, big.NewInt(3), 16, 8, validBlockNumber+shift, true)); err != nil {
+			t.Fatal(err)
+		}
 	}
 	b := postagetesting.MustNewBatch()
 	b.Start = validBlockNumber
@@ -121,3 +131,180 @@ func TestGetStampIssuer(t *testing.T) {
 		}
 	})
 }
+
+// TestRecoverBuckets tests that the collision bucket counts of the stamp
+// issuers survive a crash of the node, i.e. the service not being closed.
+func TestRecoverBuckets(t *testing.T) {
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(privKey)
+	store := &failingStateStore{StateStorer: storemock.NewStateStore()}
+	pstore := pstoremock.New()
+
+	// all the chunk addresses fall into the first collision bucket
+	addr := func(i byte) swarm.Address {
+		b := make([]byte, 32)
+		b[31] = i
+		return swarm.NewAddress(b)
+	}
+	stamp := func(t *testing.T, st *postage.StampIssuer, i byte) (uint32, error) {
+		t.Helper()
+		stamp, err := postage.NewStamper(st, signer).Stamp(addr(i))
+		if err != nil {
+			return 0, err
+		}
+		_, index := postage.BytesToIndex(stamp.Index())
+		return index, nil
+	}
+
+	ps, err := postage.NewService(store, pstore, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := newTestStampIssuer(t, 1000)
+	if err := ps.Add(st); err != nil {
+		t.Fatal(err)
+	}
+
+	// the bucket upper bound of the test issuer is 256, the journal
+	// reserves the counts in steps of 16
+	for i := byte(0); i < 16; i++ {
+		if _, err := stamp(t, st, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := store.journalPuts; got != 1 {
+		t.Fatalf("got %d journal writes, want %d", got, 1)
+	}
+
+	// crash between stamping and persisting the bucket count
+	store.fail = true
+	if _, err := stamp(t, st, 16); !errors.Is(err, errStateStore) {
+		t.Fatalf("got error %v, want %v", err, errStateStore)
+	}
+	if got := st.Utilization(); got != 16 {
+		t.Fatalf("got utilization %d, want %d", got, 16)
+	}
+	store.fail = false
+
+	// the reserved counts are not reused
+	if _, err := stamp(t, st, 16); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.journalPuts; got != 2 {
+		t.Fatalf("got %d journal writes, want %d", got, 2)
+	}
+
+	// restart without the service being closed
+	ps, err = postage.NewService(store, pstore, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuers := ps.StampIssuers()
+	if len(issuers) != 1 {
+		t.Fatalf("got %d issuers, want %d", len(issuers), 1)
+	}
+	recovered := issuers[0]
+	if !bytes.Equal(recovered.ID(), st.ID()) {
+		t.Fatalf("got issuer %x, want %x", recovered.ID(), st.ID())
+	}
+	if got := recovered.Utilization(); got != 32 {
+		t.Fatalf("got utilization %d, want %d", got, 32)
+	}
+
+	// the issued and reserved indexes are not reused
+	index, err := stamp(t, recovered, 17)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index != 32 {
+		t.Fatalf("got index %d, want %d", index, 32)
+	}
+}
+
+// TestClearJournal tests that the bucket journal is cleared when the stamp
+// issuer is saved or its batch is removed.
+func TestClearJournal(t *testing.T) {
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(privKey)
+	store := storemock.NewStateStore()
+	pstore := pstoremock.New()
+
+	ps, err := postage.NewService(store, pstore, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := newTestStampIssuer(t, 1000)
+	if err := ps.Add(st); err != nil {
+		t.Fatal(err)
+	}
+	stamp := func(t *testing.T) {
+		t.Helper()
+		if _, err := postage.NewStamper(st, signer).Stamp(swarm.NewAddress(make([]byte, 32))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	journalled := func(t *testing.T, want int) {
+		t.Helper()
+		n := 0
+		if err := store.Iterate("postagebuckets", func(_, _ []byte) (bool, error) {
+			n++
+			return false, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if n != want {
+			t.Fatalf("got %d journal entries, want %d", n, want)
+		}
+	}
+
+	t.Run("save", func(t *testing.T) {
+		stamp(t)
+		journalled(t, 1)
+		if err := ps.Save(st); err != nil {
+			t.Fatal(err)
+		}
+		journalled(t, 0)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		stamp(t)
+		journalled(t, 1)
+		if err := ps.Close(); err != nil {
+			t.Fatal(err)
+		}
+		journalled(t, 0)
+	})
+
+	t.Run("batch removed", func(t *testing.T) {
+		stamp(t)
+		journalled(t, 1)
+		ps.HandleStampExpiry(st.ID())
+		journalled(t, 0)
+	})
+}
+
+var errStateStore = errors.New("state store failure")
+
+// failingStateStore is a state store which fails to save values when set to.
+// It counts the bucket journal writes.
+type failingStateStore struct {
+	storage.StateStorer
+	fail        bool
+	journalPuts int
+}
+
+func (s *failingStateStore) Put(key string, i interface{}) error {
+	if s.fail {
+		return errStateStore
+	}
+	if strings.HasPrefix(key, "postagebuckets") {
+		s.journalPuts++
+	}
+	return s.StateStorer.Put(key, i)
+}
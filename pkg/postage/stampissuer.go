@@ -9,10 +9,20 @@ import (
 	"math/big"
 	"sync"
 
+	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/vmihailenco/msgpack/v5"
 )
 
+const (
+	// BucketHistogramBins is the number of fill levels of the collision
+	// bucket histogram.
+	BucketHistogramBins = 10
+	// NearFullRatio is the fill ratio of the fullest collision bucket above
+	// which a batch is considered nearly full.
+	NearFullRatio = 0.9
+)
+
 // stampIssuerData groups related StampIssuer data.
 // The data are factored out in order to make
 // serialization/deserialization easier and at the same
@@ -36,6 +46,7 @@ type stampIssuerData struct {
 type StampIssuer struct {
 	bucketMu sync.Mutex
 	data     stampIssuerData
+	journal  *bucketJournal // set once the issuer is added to the service
 }
 
 // NewStampIssuer constructs a StampIssuer as an extension of a batch for local
@@ -59,15 +70,23 @@ func NewStampIssuer(label, keyID string, batchID []byte, batchAmount *big.Int, b
 }
 
 // inc increments the count in the correct collision bucket for a newly stamped
-// chunk with address addr.
+// chunk with address addr. If the issuer has a journal, the new count is
+// reserved in it before the index is handed out, so that the index is never
+// issued again even if the node crashes before the issuer is saved.
 func (si *StampIssuer) inc(addr swarm.Address) ([]byte, error) {
 	si.bucketMu.Lock()
 	defer si.bucketMu.Unlock()
 	b := toBucket(si.BucketDepth(), addr)
 	bucketCount := si.data.Buckets[b]
-	if bucketCount == 1<<(si.Depth()-si.BucketDepth()) {
+	upperBound := uint32(1) << (si.Depth() - si.BucketDepth())
+	if bucketCount == upperBound {
 		return nil, ErrBucketFull
 	}
+	if si.journal != nil {
+		if err := si.journal.reserve(b, bucketCount+1, upperBound); err != nil {
+			return nil, err
+		}
+	}
 	si.data.Buckets[b]++
 	if si.data.Buckets[b] > si.data.MaxBucketCount {
 		si.data.MaxBucketCount = si.data.Buckets[b]
@@ -75,6 +94,81 @@ func (si *StampIssuer) inc(addr swarm.Address) ([]byte, error) {
 	return indexToBytes(b, bucketCount), nil
 }
 
+// recoverBuckets restores the collision bucket counts from the journal that
+// are higher than the ones the issuer was saved with.
+func (si *StampIssuer) recoverBuckets() error {
+	si.bucketMu.Lock()
+	defer si.bucketMu.Unlock()
+	return si.journal.iterate(func(bucket, count uint32) {
+		if int(bucket) >= len(si.data.Buckets) || count <= si.data.Buckets[bucket] {
+			return
+		}
+		si.data.Buckets[bucket] = count
+		if count > si.data.MaxBucketCount {
+			si.data.MaxBucketCount = count
+		}
+	})
+}
+
+// save saves the issuer to the state store under the key and clears its
+// journal. The issuer is locked meanwhile, so that no count is journalled
+// between the two.
+func (si *StampIssuer) save(store storage.StateStorer, key string) error {
+	si.bucketMu.Lock()
+	defer si.bucketMu.Unlock()
+	data, err := msgpack.Marshal(si.data)
+	if err != nil {
+		return err
+	}
+	if err := store.Put(key, encodedIssuer(data)); err != nil {
+		return err
+	}
+	if si.journal != nil {
+		return si.journal.clear()
+	}
+	return nil
+}
+
+// clearJournal clears the journal of the issuer.
+func (si *StampIssuer) clearJournal() error {
+	si.bucketMu.Lock()
+	defer si.bucketMu.Unlock()
+	if si.journal == nil {
+		return nil
+	}
+	return si.journal.clear()
+}
+
+// encodedIssuer is a stamp issuer already encoded for the state store.
+type encodedIssuer []byte
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (e encodedIssuer) MarshalBinary() ([]byte, error) {
+	return e, nil
+}
+
+// TopUp increases the per chunk amount paid for the batch by the given
+// amount.
+func (si *StampIssuer) TopUp(amount *big.Int) {
+	si.bucketMu.Lock()
+	defer si.bucketMu.Unlock()
+	si.data.BatchAmount = new(big.Int).Add(si.data.BatchAmount, amount)
+}
+
+// Dilute increases the depth of the batch. The collision buckets are kept
+// as the bucket depth does not change, only their capacity grows. The per
+// chunk amount is divided among the newly available chunks.
+func (si *StampIssuer) Dilute(depth uint8) {
+	si.bucketMu.Lock()
+	defer si.bucketMu.Unlock()
+	if depth <= si.data.BatchDepth {
+		return
+	}
+	diff := depth - si.data.BatchDepth
+	si.data.BatchAmount = new(big.Int).Rsh(si.data.BatchAmount, uint(diff))
+	si.data.BatchDepth = depth
+}
+
 // toBucket calculates the index of the collision bucket for a swarm address
 // bucket index := collision bucket depth number of bits as bigendian uint32
 func toBucket(depth uint8, addr swarm.Address) uint32 {
@@ -106,6 +200,8 @@ func (si *StampIssuer) Label() string {
 
 // MarshalBinary implements the encoding.BinaryMarshaler interface.
 func (si *StampIssuer) MarshalBinary() ([]byte, error) {
+	si.bucketMu.Lock()
+	defer si.bucketMu.Unlock()
 	return msgpack.Marshal(si.data)
 }
 
@@ -123,6 +219,43 @@ func (si *StampIssuer) Utilization() uint32 {
 	return si.data.MaxBucketCount
 }
 
+// BucketUpperBound returns the maximum number of chunks that can be stamped
+// in a collision bucket.
+func (si *StampIssuer) BucketUpperBound() uint32 {
+	si.bucketMu.Lock()
+	defer si.bucketMu.Unlock()
+	return 1 << (si.data.BatchDepth - si.data.BucketDepth)
+}
+
+// BucketHistogram returns the number of collision buckets per fill level.
+// The fill levels are BucketHistogramBins equal parts of the bucket upper
+// bound, bin i counts the buckets filled to at least i/BucketHistogramBins
+// of the upper bound; the last bin also counts the full buckets.
+func (si *StampIssuer) BucketHistogram() []uint32 {
+	si.bucketMu.Lock()
+	defer si.bucketMu.Unlock()
+	upperBound := uint64(1) << (si.data.BatchDepth - si.data.BucketDepth)
+	histogram := make([]uint32, BucketHistogramBins)
+	for _, count := range si.data.Buckets {
+		bin := uint64(count) * BucketHistogramBins / upperBound
+		if bin >= BucketHistogramBins {
+			bin = BucketHistogramBins - 1
+		}
+		histogram[bin]++
+	}
+	return histogram
+}
+
+// NearFull reports whether the fullest collision bucket is filled beyond
+// NearFullRatio of its upper bound, in which case the uploads will soon fail
+// with ErrBucketFull.
+func (si *StampIssuer) NearFull() bool {
+	si.bucketMu.Lock()
+	defer si.bucketMu.Unlock()
+	upperBound := float64(uint64(1) << (si.data.BatchDepth - si.data.BucketDepth))
+	return float64(si.data.MaxBucketCount) >= upperBound*NearFullRatio
+}
+
 // ID returns the BatchID for this batch.
 func (si *StampIssuer) ID() []byte {
 	id := make([]byte, len(si.data.BatchID))
@@ -11,7 +11,9 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/swarm"
 )
 
 // TestStampIssuerMarshalling tests the idempotence  of binary marshal/unmarshal.
@@ -40,3 +42,67 @@ func newTestStampIssuer(t *testing.T, block uint64) *postage.StampIssuer {
 	}
 	return postage.NewStampIssuer("label", "keyID", id, big.NewInt(3), 16, 8, block, true)
 }
+
+func TestStampIssuerTopUpDilute(t *testing.T) {
+	st := newTestStampIssuer(t, 1000)
+
+	st.TopUp(big.NewInt(5))
+	if got := st.Amount(); got.Cmp(big.NewInt(8)) != 0 {
+		t.Fatalf("got amount %v, want %v", got, 8)
+	}
+
+	st.Dilute(18)
+	if got := st.Depth(); got != 18 {
+		t.Fatalf("got depth %d, want %d", got, 18)
+	}
+	if got := st.Amount(); got.Cmp(big.NewInt(2)) != 0 {
+		t.Fatalf("got amount %v, want %v", got, 2)
+	}
+	if got := st.BucketDepth(); got != 8 {
+		t.Fatalf("got bucket depth %d, want %d", got, 8)
+	}
+
+	// depth can not be decreased
+	st.Dilute(17)
+	if got := st.Depth(); got != 18 {
+		t.Fatalf("got depth %d, want %d", got, 18)
+	}
+}
+
+func TestStampIssuerBucketHistogram(t *testing.T) {
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 16 buckets of 4 chunks
+	st := postage.NewStampIssuer("label", "keyID", make([]byte, 32), big.NewInt(3), 6, 4, 1000, true)
+	stamper := postage.NewStamper(st, crypto.NewDefaultSigner(privKey))
+	stamp := func(bucket byte, n int) {
+		for i := 0; i < n; i++ {
+			addr := make([]byte, 32)
+			addr[0] = bucket << 4
+			addr[31] = byte(i)
+			if _, err := stamper.Stamp(swarm.NewAddress(addr)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	stamp(0, 1)
+	stamp(1, 2)
+	if st.NearFull() {
+		t.Fatal("expected batch not to be nearly full")
+	}
+	stamp(2, 4)
+	if !st.NearFull() {
+		t.Fatal("expected batch to be nearly full")
+	}
+
+	if got := st.BucketUpperBound(); got != 4 {
+		t.Fatalf("got bucket upper bound %d, want %d", got, 4)
+	}
+	want := []uint32{13, 0, 1, 0, 0, 1, 0, 0, 0, 1}
+	if got := st.BucketHistogram(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got histogram %v, want %v", got, want)
+	}
+}
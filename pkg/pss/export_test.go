@@ -4,6 +4,23 @@
 
 package pss
 
+import (
+	"time"
+
+	"github.com/ethersphere/bee/pkg/ratelimit"
+)
+
 var (
 	Contains = contains
 )
+
+// SetMailboxTime sets the clock of the mailbox.
+func SetMailboxTime(m *Mailbox, now func() time.Time) {
+	m.now = now
+}
+
+// SetRequestLimit sets the limit of the requests answered for a single
+// sender key.
+func SetRequestLimit(p Interface, r time.Duration, burst int) {
+	p.(*pss).requestLimiter = ratelimit.New(r, burst)
+}
@@ -0,0 +1,299 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pss
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	// DefaultMailboxCapacity is the default maximal number of
+	// messages kept in the mailbox.
+	DefaultMailboxCapacity = 10000
+	// DefaultMailboxTTL is the default time the messages are kept
+	// in the mailbox for.
+	DefaultMailboxTTL = 7 * 24 * time.Hour
+
+	// mailboxPrefixLength is the length of the overlay address prefix
+	// of the chunks queried from the mailboxes, the shortest target
+	// length, so that the messages sent to any target of the node
+	// are found.
+	mailboxPrefixLength = 1
+	// mailboxPullOverlap is the time the pulls from the mailboxes
+	// overlap to tolerate the clock differences of the peers.
+	mailboxPullOverlap = time.Minute
+
+	mailboxKeyPrefix = "pss_mailbox_"
+)
+
+// ErrMailboxDisabled is returned when querying the mailbox of a node
+// that does not keep undeliverable messages.
+var ErrMailboxDisabled = errors.New("mailbox disabled")
+
+// MailboxMessage is a message found in the mailbox.
+type MailboxMessage struct {
+	// Received is the time the trojan chunk of the message was received.
+	Received time.Time
+	Payload  []byte
+}
+
+// MailboxFetcher retrieves the chunks kept in the mailboxes of the
+// neighbourhood peers.
+type MailboxFetcher interface {
+	// FetchMailbox calls the function with the chunks with the address
+	// prefix received by the peers since the given time.
+	FetchMailbox(ctx context.Context, prefix []byte, since time.Time, f func(time.Time, swarm.Chunk) error) error
+}
+
+// Mailbox keeps the trojan chunks that could not be delivered to any
+// handler, so that the messages can be queried later by the recipient
+// from the nodes of its neighbourhood.
+type Mailbox struct {
+	store    storage.StateStorer
+	capacity int
+	ttl      time.Duration
+	mu       sync.Mutex
+	entries  []mailboxEntry // sorted by the time received
+	kept     map[string]struct{}
+	now      func() time.Time
+}
+
+type mailboxEntry struct {
+	key      string
+	addr     string
+	received time.Time
+}
+
+// mailboxChunk is the persisted trojan chunk.
+type mailboxChunk struct {
+	swarm.Chunk
+}
+
+func (c *mailboxChunk) MarshalBinary() ([]byte, error) {
+	return append(append([]byte{}, c.Address().Bytes()...), c.Data()...), nil
+}
+
+func (c *mailboxChunk) UnmarshalBinary(b []byte) error {
+	if len(b) < swarm.HashSize {
+		return errors.New("invalid mailbox chunk")
+	}
+	c.Chunk = swarm.NewChunk(swarm.NewAddress(b[:swarm.HashSize]), b[swarm.HashSize:])
+	return nil
+}
+
+// NewMailbox constructs a Mailbox persisting at most capacity
+// chunks for the ttl duration in the state store.
+func NewMailbox(store storage.StateStorer, capacity int, ttl time.Duration) (*Mailbox, error) {
+	m := &Mailbox{
+		store:    store,
+		capacity: capacity,
+		ttl:      ttl,
+		kept:     make(map[string]struct{}),
+		now:      time.Now,
+	}
+	err := store.Iterate(mailboxKeyPrefix, func(key, _ []byte) (bool, error) {
+		received, addr, err := parseMailboxKey(string(key))
+		if err != nil {
+			return true, err
+		}
+		m.entries = append(m.entries, mailboxEntry{key: string(key), addr: addr, received: received})
+		m.kept[addr] = struct{}{}
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load mailbox: %w", err)
+	}
+	sort.Slice(m.entries, func(i, j int) bool {
+		return m.entries[i].received.Before(m.entries[j].received)
+	})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.evict(); err != nil {
+		return nil, fmt.Errorf("evict mailbox: %w", err)
+	}
+	return m, nil
+}
+
+// Put stores the chunk in the mailbox evicting the expired
+// chunks and the oldest ones if the mailbox is full. Chunks
+// already in the mailbox are not stored again.
+func (m *Mailbox) Put(ch swarm.Chunk) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	addr := ch.Address().String()
+	if _, ok := m.kept[addr]; ok {
+		return nil
+	}
+
+	received := m.now()
+	if n := len(m.entries); n > 0 && received.Before(m.entries[n-1].received) {
+		// keep the entries sorted if the clock goes backwards
+		received = m.entries[n-1].received
+	}
+	key := mailboxKey(received, ch.Address())
+	if err := m.store.Put(key, &mailboxChunk{ch}); err != nil {
+		return err
+	}
+	m.entries = append(m.entries, mailboxEntry{key: key, addr: addr, received: received})
+	m.kept[addr] = struct{}{}
+	return m.evict()
+}
+
+// Iterate calls the function with the chunks with the address prefix
+// received since the given time in the order they were received until
+// it returns true or an error.
+func (m *Mailbox) Iterate(since time.Time, prefix []byte, f func(time.Time, swarm.Chunk) (bool, error)) error {
+	m.mu.Lock()
+	i := sort.Search(len(m.entries), func(i int) bool {
+		return !m.entries[i].received.Before(since)
+	})
+	entries := append([]mailboxEntry{}, m.entries[i:]...)
+	m.mu.Unlock()
+
+	hexPrefix := hex.EncodeToString(prefix)
+	for _, e := range entries {
+		if !strings.HasPrefix(e.addr, hexPrefix) {
+			continue
+		}
+		var ch mailboxChunk
+		if err := m.store.Get(e.key, &ch); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				continue // evicted in the meantime
+			}
+			return err
+		}
+		stop, err := f(e.received, ch.Chunk)
+		if err != nil {
+			return err
+		}
+		if stop {
+			return nil
+		}
+	}
+	return nil
+}
+
+// evict removes the expired entries and the oldest ones exceeding
+// the capacity. It must be called with the mu lock held.
+func (m *Mailbox) evict() error {
+	expired := m.now().Add(-m.ttl)
+	n := 0
+	for n < len(m.entries) && (len(m.entries)-n > m.capacity || !m.entries[n].received.After(expired)) {
+		if err := m.store.Delete(m.entries[n].key); err != nil {
+			m.entries = m.entries[n:]
+			return err
+		}
+		delete(m.kept, m.entries[n].addr)
+		n++
+	}
+	m.entries = m.entries[n:]
+	return nil
+}
+
+func mailboxKey(received time.Time, addr swarm.Address) string {
+	return fmt.Sprintf("%s%020d_%s", mailboxKeyPrefix, received.UnixNano(), addr)
+}
+
+func parseMailboxKey(key string) (received time.Time, addr string, err error) {
+	v := strings.SplitN(strings.TrimPrefix(key, mailboxKeyPrefix), "_", 2)
+	if len(v) != 2 {
+		return time.Time{}, "", fmt.Errorf("invalid mailbox key %q", key)
+	}
+	ns, err := strconv.ParseInt(v[0], 10, 64)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid mailbox key %q: %w", key, err)
+	}
+	return time.Unix(0, ns), v[1], nil
+}
+
+// inbox holds the messages with a topic pulled from the mailboxes, so
+// that the chunks are unwrapped only once and only the chunks received
+// after the previous pull are queried again.
+type inbox struct {
+	from     time.Time      // start of the pulled time range
+	pulled   time.Time      // time of the last pull
+	messages []inboxMessage // sorted by the time received
+	kept     map[string]struct{}
+}
+
+type inboxMessage struct {
+	addr string
+	MailboxMessage
+}
+
+func newInbox() *inbox {
+	return &inbox{kept: make(map[string]struct{})}
+}
+
+// window returns the start of the time range to pull for the messages
+// received since the given time.
+func (in *inbox) window(since time.Time) time.Time {
+	if in.pulled.IsZero() || since.Before(in.from) {
+		return since
+	}
+	if from := in.pulled.Add(-mailboxPullOverlap); from.After(since) {
+		return from
+	}
+	return since
+}
+
+// add indexes the message of the chunk with the address unless it is
+// already in the inbox.
+func (in *inbox) add(addr swarm.Address, msg MailboxMessage) {
+	key := addr.ByteString()
+	if _, ok := in.kept[key]; ok {
+		return
+	}
+	in.kept[key] = struct{}{}
+	i := sort.Search(len(in.messages), func(i int) bool {
+		return in.messages[i].Received.After(msg.Received)
+	})
+	in.messages = append(in.messages, inboxMessage{})
+	copy(in.messages[i+1:], in.messages[i:])
+	in.messages[i] = inboxMessage{addr: key, MailboxMessage: msg}
+}
+
+// has reports whether the message of the chunk with the address is in
+// the inbox.
+func (in *inbox) has(addr swarm.Address) bool {
+	_, ok := in.kept[addr.ByteString()]
+	return ok
+}
+
+// expire removes the messages received before the given time.
+func (in *inbox) expire(before time.Time) {
+	i := sort.Search(len(in.messages), func(i int) bool {
+		return !in.messages[i].Received.Before(before)
+	})
+	for _, m := range in.messages[:i] {
+		delete(in.kept, m.addr)
+	}
+	in.messages = append(in.messages[:0], in.messages[i:]...)
+}
+
+// since returns the messages received since the given time.
+func (in *inbox) since(t time.Time) []MailboxMessage {
+	i := sort.Search(len(in.messages), func(i int) bool {
+		return !in.messages[i].Received.Before(t)
+	})
+	msgs := make([]MailboxMessage, 0, len(in.messages)-i)
+	for _, m := range in.messages[i:] {
+		msgs = append(msgs, m.MailboxMessage)
+	}
+	return msgs
+}
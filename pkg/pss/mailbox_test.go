@@ -0,0 +1,243 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pss_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/pss"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestMailbox(t *testing.T) {
+	var (
+		store = statestore.NewStateStore()
+		now   = time.Unix(1000, 0)
+		clock = func() time.Time { return now }
+		chs   = make([]swarm.Chunk, 4)
+	)
+	for i := range chs {
+		chs[i] = trojanChunk(t, pss.NewTopic("topic"), []byte{byte(i)})
+	}
+
+	m, err := pss.NewMailbox(store, 3, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pss.SetMailboxTime(m, clock)
+
+	for i, ch := range chs[:3] {
+		now = time.Unix(1000+int64(i), 0)
+		if err := m.Put(ch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// chunks already in the mailbox are ignored
+	if err := m.Put(chs[0]); err != nil {
+		t.Fatal(err)
+	}
+	expectMailbox(t, m, time.Unix(0, 0), chs[0], chs[1], chs[2])
+	expectMailbox(t, m, time.Unix(1001, 0), chs[1], chs[2])
+
+	// the oldest chunk is evicted when the mailbox is full
+	now = time.Unix(1003, 0)
+	if err := m.Put(chs[3]); err != nil {
+		t.Fatal(err)
+	}
+	expectMailbox(t, m, time.Unix(0, 0), chs[1], chs[2], chs[3])
+
+	// only the chunks with the address prefix are iterated
+	err = m.Iterate(time.Unix(0, 0), []byte{0}, func(_ time.Time, ch swarm.Chunk) (bool, error) {
+		t.Fatalf("got chunk %s outside of the prefix", ch.Address())
+		return true, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the mailbox is loaded from the state store with the expired chunks evicted
+	m, err = pss.NewMailbox(store, 3, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectMailbox(t, m, time.Unix(0, 0))
+}
+
+func TestMailboxMessages(t *testing.T) {
+	privkey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := pss.NewMailbox(statestore.NewStateStore(), pss.DefaultMailboxCapacity, pss.DefaultMailboxTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		overlay = swarm.MustParseHexAddress("0100000000000000000000000000000000000000000000000000000000000000")
+		p       = pss.New(privkey, overlay, m, logging.New(ioutil.Discard, 0))
+		topic   = pss.NewTopic("offline")
+		other   = pss.NewTopic("other")
+		targets = pss.Targets{pss.Target{1}}
+		since   = time.Now().Add(-time.Minute)
+	)
+
+	for topic, payload := range map[pss.Topic]string{topic: "hello", other: "hello other"} {
+		ch, err := pss.Wrap(context.Background(), topic, []byte(payload), &privkey.PublicKey, targets)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// no handler is registered, the message is undeliverable
+		p.TryUnwrapOrKeep(ch)
+		// the chunks not flagged to be kept are not
+		p.TryUnwrap(ch)
+	}
+
+	msgs, err := p.MailboxMessages(context.Background(), topic, since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("got %d messages, want 1", len(msgs))
+	}
+	if want := []byte("hello"); !bytes.Equal(msgs[0].Payload, want) {
+		t.Fatalf("got message %q, want %q", msgs[0].Payload, want)
+	}
+	if msgs[0].Received.Before(since) {
+		t.Fatalf("got received time %v before %v", msgs[0].Received, since)
+	}
+
+	msgs, err = p.MailboxMessages(context.Background(), topic, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 0 {
+		t.Fatalf("got %d messages, want none", len(msgs))
+	}
+
+	p = pss.New(privkey, overlay, nil, logging.New(ioutil.Discard, 0))
+	if _, err := p.MailboxMessages(context.Background(), topic, since); err != pss.ErrMailboxDisabled {
+		t.Fatalf("got error %v, want %v", err, pss.ErrMailboxDisabled)
+	}
+}
+
+func TestMailboxFetch(t *testing.T) {
+	privkey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		overlay  = swarm.MustParseHexAddress("0100000000000000000000000000000000000000000000000000000000000000")
+		p        = pss.New(privkey, overlay, nil, logging.New(ioutil.Discard, 0))
+		topic    = pss.NewTopic("offline")
+		received = time.Now().Add(-time.Hour)
+		fetcher  = &mailboxFetcher{}
+	)
+	for _, payload := range []string{"hello", "again"} {
+		ch, err := pss.Wrap(context.Background(), topic, []byte(payload), &privkey.PublicKey, pss.Targets{pss.Target{1}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		fetcher.chunks = append(fetcher.chunks, ch)
+	}
+	// the chunk of another recipient is not a message with the topic
+	fetcher.chunks = append(fetcher.chunks, trojanChunk(t, topic, []byte("other")))
+	fetcher.received = received
+	p.SetMailboxFetcher(fetcher)
+
+	expectMessages := func(since time.Time, want ...string) {
+		t.Helper()
+
+		msgs, err := p.MailboxMessages(context.Background(), topic, since)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(msgs) != len(want) {
+			t.Fatalf("got %d messages, want %d", len(msgs), len(want))
+		}
+		for i := range want {
+			if !bytes.Equal(msgs[i].Payload, []byte(want[i])) {
+				t.Fatalf("message %d: got %q, want %q", i, msgs[i].Payload, want[i])
+			}
+		}
+	}
+
+	expectMessages(time.Unix(0, 0), "hello", "again")
+	if !bytes.Equal(fetcher.prefix, overlay.Bytes()[:1]) {
+		t.Fatalf("got prefix %x, want %x", fetcher.prefix, overlay.Bytes()[:1])
+	}
+
+	// only the chunks received after the previous pull are fetched again
+	expectMessages(time.Unix(0, 0), "hello", "again")
+	if !fetcher.since.After(received) {
+		t.Fatalf("got fetched since %v, want after %v", fetcher.since, received)
+	}
+
+	expectMessages(received.Add(time.Second))
+}
+
+// mailboxFetcher is a MailboxFetcher holding chunks all received
+// at the same time.
+type mailboxFetcher struct {
+	chunks   []swarm.Chunk
+	received time.Time
+	prefix   []byte
+	since    time.Time
+}
+
+func (m *mailboxFetcher) FetchMailbox(_ context.Context, prefix []byte, since time.Time, f func(time.Time, swarm.Chunk) error) error {
+	m.prefix = prefix
+	m.since = since
+	if m.received.Before(since) {
+		return nil
+	}
+	for _, ch := range m.chunks {
+		if err := f(m.received, ch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func expectMailbox(t *testing.T, m *pss.Mailbox, since time.Time, want ...swarm.Chunk) {
+	t.Helper()
+
+	var got []swarm.Chunk
+	err := m.Iterate(since, nil, func(_ time.Time, ch swarm.Chunk) (bool, error) {
+		got = append(got, ch)
+		return false, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d chunks, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Fatalf("chunk %d: got %s, want %s", i, got[i].Address(), want[i].Address())
+		}
+	}
+}
+
+func trojanChunk(t *testing.T, topic pss.Topic, payload []byte) swarm.Chunk {
+	t.Helper()
+
+	privkey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, err := pss.Wrap(context.Background(), topic, payload, &privkey.PublicKey, pss.Targets{pss.Target{1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ch
+}
@@ -0,0 +1,178 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package mailboxprotocol exposes the protocol retrieving the pss
+// messages kept in the mailboxes of the neighbourhood peers, so that
+// a node receives the messages sent to it while it was offline.
+package mailboxprotocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/cac"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/p2p/protobuf"
+	"github.com/ethersphere/bee/pkg/pss"
+	"github.com/ethersphere/bee/pkg/pss/mailboxprotocol/pb"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/topology"
+)
+
+const (
+	protocolName    = "mailbox"
+	protocolVersion = "1.0.0"
+	streamName      = "mailbox"
+
+	// peerTimeout is the time a peer is given to deliver the
+	// chunks kept in its mailbox.
+	peerTimeout = time.Minute
+)
+
+var (
+	_ pss.MailboxFetcher = (*Service)(nil)
+
+	// ErrInvalidChunk is returned when a peer delivers a chunk
+	// which is not content addressed.
+	ErrInvalidChunk = errors.New("invalid chunk")
+)
+
+// Service is the mailbox protocol serving the chunks of the mailbox
+// of the node and fetching the ones of its neighbourhood peers.
+type Service struct {
+	streamer p2p.Streamer
+	mailbox  *pss.Mailbox
+	topology topology.EachNeighbor
+	logger   logging.Logger
+}
+
+// New constructs the mailbox protocol. The node serves the chunks of
+// its mailbox unless it is nil.
+func New(streamer p2p.Streamer, mailbox *pss.Mailbox, topology topology.EachNeighbor, logger logging.Logger) *Service {
+	return &Service{
+		streamer: streamer,
+		mailbox:  mailbox,
+		topology: topology,
+		logger:   logger,
+	}
+}
+
+func (s *Service) Protocol() p2p.ProtocolSpec {
+	return p2p.ProtocolSpec{
+		Name:    protocolName,
+		Version: protocolVersion,
+		StreamSpecs: []p2p.StreamSpec{
+			{
+				Name:    streamName,
+				Handler: s.handler,
+			},
+		},
+	}
+}
+
+// FetchMailbox queries the mailboxes of the neighbourhood peers for the
+// chunks with the address prefix received since the given time. The
+// errors of the peers are logged and the remaining peers are queried.
+func (s *Service) FetchMailbox(ctx context.Context, prefix []byte, since time.Time, f func(time.Time, swarm.Chunk) error) error {
+	var peers []swarm.Address
+	err := s.topology.EachNeighbor(func(addr swarm.Address, _ uint8) (bool, bool, error) {
+		peers = append(peers, addr)
+		return false, false, nil
+	})
+	if err != nil {
+		return fmt.Errorf("neighbours: %w", err)
+	}
+
+	for _, peer := range peers {
+		if err := s.fetch(ctx, peer, prefix, since, f); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			s.logger.Debugf("mailbox: fetch from peer %s: %v", peer, err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) fetch(ctx context.Context, peer swarm.Address, prefix []byte, since time.Time, f func(time.Time, swarm.Chunk) error) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, peerTimeout)
+	defer cancel()
+
+	stream, err := s.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, streamName)
+	if err != nil {
+		return fmt.Errorf("new stream: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			go stream.FullClose()
+		}
+	}()
+
+	w, r := protobuf.NewWriterAndReader(stream)
+	if err := w.WriteMsgWithContext(ctx, &pb.Query{
+		Prefix: prefix,
+		Since:  since.UnixNano(),
+	}); err != nil {
+		return fmt.Errorf("write query: %w", err)
+	}
+
+	for {
+		var d pb.Delivery
+		if err := r.ReadMsgWithContext(ctx, &d); err != nil {
+			return fmt.Errorf("read delivery: %w", err)
+		}
+		if len(d.Address) == 0 {
+			return nil // end of the mailbox
+		}
+		ch := swarm.NewChunk(swarm.NewAddress(d.Address), d.Data)
+		if !cac.Valid(ch) {
+			return ErrInvalidChunk
+		}
+		if err := f(time.Unix(0, d.Received), ch); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Service) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (err error) {
+	w, r := protobuf.NewWriterAndReader(stream)
+	defer func() {
+		if err != nil {
+			_ = stream.Reset()
+		} else {
+			_ = stream.FullClose()
+		}
+	}()
+
+	var q pb.Query
+	if err := r.ReadMsgWithContext(ctx, &q); err != nil {
+		return fmt.Errorf("read query: %w", err)
+	}
+
+	if s.mailbox != nil {
+		err := s.mailbox.Iterate(time.Unix(0, q.Since), q.Prefix, func(received time.Time, ch swarm.Chunk) (bool, error) {
+			if err := w.WriteMsgWithContext(ctx, &pb.Delivery{
+				Address:  ch.Address().Bytes(),
+				Data:     ch.Data(),
+				Received: received.UnixNano(),
+			}); err != nil {
+				return true, fmt.Errorf("write delivery: %w", err)
+			}
+			return false, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := w.WriteMsgWithContext(ctx, &pb.Delivery{}); err != nil {
+		return fmt.Errorf("write end of mailbox: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mailboxprotocol_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p/streamtest"
+	"github.com/ethersphere/bee/pkg/pss"
+	"github.com/ethersphere/bee/pkg/pss/mailboxprotocol"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/topology/mock"
+)
+
+func TestFetchMailbox(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+
+	mailbox, err := pss.NewMailbox(statestore.NewStateStore(), pss.DefaultMailboxCapacity, pss.DefaultMailboxTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		chunks []swarm.Chunk
+		since  time.Time
+	)
+	for i, target := range []pss.Target{{1}, {1}, {2}} {
+		if i == 1 {
+			time.Sleep(10 * time.Millisecond)
+			since = time.Now()
+		}
+		ch := trojanChunk(t, target)
+		if err := mailbox.Put(ch); err != nil {
+			t.Fatal(err)
+		}
+		chunks = append(chunks, ch)
+	}
+
+	// the peer serves the chunks kept in its mailbox
+	server := mailboxprotocol.New(nil, mailbox, nil, logger)
+	recorder := streamtest.New(streamtest.WithProtocols(server.Protocol()))
+
+	// the peer without a mailbox ends the response to the query
+	noMailbox := mailboxprotocol.New(nil, nil, nil, logger)
+	peer := swarm.MustParseHexAddress("0100000000000000000000000000000000000000000000000000000000000000")
+	client := mailboxprotocol.New(recorder, nil, mock.NewTopologyDriver(mock.WithPeers(peer)), logger)
+
+	expectFetched := func(since time.Time, want ...swarm.Chunk) {
+		t.Helper()
+
+		var got []swarm.Chunk
+		err := client.FetchMailbox(context.Background(), []byte{1}, since, func(received time.Time, ch swarm.Chunk) error {
+			if received.Before(since) {
+				t.Fatalf("got chunk %s received at %v before %v", ch.Address(), received, since)
+			}
+			got = append(got, ch)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("got %d chunks, want %d", len(got), len(want))
+		}
+		for i := range want {
+			if !got[i].Equal(want[i]) {
+				t.Fatalf("chunk %d: got %s, want %s", i, got[i].Address(), want[i].Address())
+			}
+		}
+	}
+
+	expectFetched(time.Unix(0, 0), chunks[0], chunks[1])
+	expectFetched(since, chunks[1])
+	expectFetched(time.Now().Add(time.Minute))
+
+	recorder.SetProtocols(noMailbox.Protocol())
+	expectFetched(time.Unix(0, 0))
+}
+
+func trojanChunk(t *testing.T, target pss.Target) swarm.Chunk {
+	t.Helper()
+
+	privkey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, err := pss.Wrap(context.Background(), pss.NewTopic("topic"), []byte("hello"), &privkey.PublicKey, pss.Targets{target})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ch
+}
@@ -10,8 +10,13 @@ import (
 )
 
 type metrics struct {
-	TotalMessagesSentCounter prometheus.Counter
-	MessageMiningDuration    prometheus.Gauge
+	TotalMessagesSentCounter    prometheus.Counter
+	MessageMiningDuration       prometheus.Gauge
+	TotalRequestsSentCounter    prometheus.Counter
+	TotalRepliesSentCounter     prometheus.Counter
+	TotalRequestsLimitedCounter prometheus.Counter
+
+	TotalMailboxMessagesCounter prometheus.Counter
 }
 
 func newMetrics() metrics {
@@ -30,6 +35,30 @@ func newMetrics() metrics {
 			Name:      "mining_duration",
 			Help:      "Time duration to mine a message.",
 		}),
+		TotalRequestsSentCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "total_requests_sent",
+			Help:      "Total requests sent.",
+		}),
+		TotalRepliesSentCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "total_replies_sent",
+			Help:      "Total replies to requests sent.",
+		}),
+		TotalRequestsLimitedCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "total_requests_limited",
+			Help:      "Total requests dropped as their senders exceeded the request limit.",
+		}),
+		TotalMailboxMessagesCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "total_mailbox_messages",
+			Help:      "Total undeliverable messages kept in the mailbox.",
+		}),
 	}
 }
 
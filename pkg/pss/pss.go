@@ -20,6 +20,7 @@ import (
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/postage"
 	"github.com/ethersphere/bee/pkg/pushsync"
+	"github.com/ethersphere/bee/pkg/ratelimit"
 	"github.com/ethersphere/bee/pkg/swarm"
 )
 
@@ -35,33 +36,62 @@ type Sender interface {
 
 type Interface interface {
 	Sender
+	Requester
 	// Register a Handler for a given Topic.
 	Register(Topic, Handler) func()
+	// MailboxMessages returns the undelivered messages with the given Topic
+	// received since the given time.
+	MailboxMessages(context.Context, Topic, time.Time) ([]MailboxMessage, error)
+	// SetMailboxFetcher sets the retrieval of the messages kept in the
+	// mailboxes of the neighbourhood peers.
+	SetMailboxFetcher(MailboxFetcher)
 	// TryUnwrap tries to unwrap a wrapped trojan message.
 	TryUnwrap(swarm.Chunk)
+	// TryUnwrapOrKeep tries to unwrap a wrapped trojan message and keeps
+	// it in the mailbox if it can not be delivered.
+	TryUnwrapOrKeep(swarm.Chunk)
 
 	SetPushSyncer(pushSyncer pushsync.PushSyncer)
 	io.Closer
 }
 
 type pss struct {
-	key        *ecdsa.PrivateKey
-	pusher     pushsync.PushSyncer
-	handlers   map[Topic][]*Handler
-	handlersMu sync.Mutex
-	metrics    metrics
-	logger     logging.Logger
-	quit       chan struct{}
+	key             *ecdsa.PrivateKey
+	overlay         swarm.Address
+	mailbox         *Mailbox
+	fetcher         MailboxFetcher
+	inboxes         map[Topic]*inbox
+	inboxesMu       sync.Mutex
+	pusher          pushsync.PushSyncer
+	handlers        map[Topic][]*Handler
+	requestHandlers map[Topic][]*requestHandler
+	handlersMu      sync.Mutex
+	metrics         metrics
+	logger          logging.Logger
+	quit            chan struct{}
+
+	requestLimiter      *ratelimit.Limiter // requests answered per sender
+	totalRequestLimiter *ratelimit.Limiter // requests answered for all senders
 }
 
-// New returns a new pss service.
-func New(key *ecdsa.PrivateKey, logger logging.Logger) Interface {
+// New returns a new pss service. The overlay address is used
+// as the destination of the replies to the requests sent. The
+// undeliverable messages flagged by their senders to be kept are
+// kept in the mailbox unless it is nil.
+func New(key *ecdsa.PrivateKey, overlay swarm.Address, mailbox *Mailbox, logger logging.Logger) Interface {
 	return &pss{
-		key:      key,
-		logger:   logger,
-		handlers: make(map[Topic][]*Handler),
-		metrics:  newMetrics(),
-		quit:     make(chan struct{}),
+		key:             key,
+		overlay:         overlay,
+		mailbox:         mailbox,
+		inboxes:         make(map[Topic]*inbox),
+		logger:          logger,
+		handlers:        make(map[Topic][]*Handler),
+		requestHandlers: make(map[Topic][]*requestHandler),
+		metrics:         newMetrics(),
+		quit:            make(chan struct{}),
+
+		requestLimiter:      ratelimit.New(requestLimitRate, requestLimitBurst),
+		totalRequestLimiter: ratelimit.New(totalRequestLimitRate, totalRequestLimitBurst),
 	}
 }
 
@@ -71,6 +101,7 @@ func (ps *pss) Close() error {
 	defer ps.handlersMu.Unlock()
 
 	ps.handlers = make(map[Topic][]*Handler) //unset handlers on shutdown
+	ps.requestHandlers = make(map[Topic][]*requestHandler)
 
 	return nil
 }
@@ -79,6 +110,19 @@ func (ps *pss) SetPushSyncer(pushSyncer pushsync.PushSyncer) {
 	ps.pusher = pushSyncer
 }
 
+func (ps *pss) SetMailboxFetcher(fetcher MailboxFetcher) {
+	ps.inboxesMu.Lock()
+	defer ps.inboxesMu.Unlock()
+
+	ps.fetcher = fetcher
+}
+
+// WithMailbox returns the context of sending a message to be kept in the
+// mailboxes of the recipient's neighbourhood if it can not be delivered.
+func WithMailbox(ctx context.Context) context.Context {
+	return pushsync.WithMailbox(ctx)
+}
+
 // Handler defines code to be executed upon reception of a trojan message.
 type Handler func(context.Context, []byte)
 
@@ -136,16 +180,33 @@ func (p *pss) topics() []Topic {
 	p.handlersMu.Lock()
 	defer p.handlersMu.Unlock()
 
-	ts := make([]Topic, 0, len(p.handlers))
+	ts := make([]Topic, 0, len(p.handlers)+len(p.requestHandlers))
 	for t := range p.handlers {
 		ts = append(ts, t)
 	}
+	for t := range p.requestHandlers {
+		if _, ok := p.handlers[t]; !ok {
+			ts = append(ts, t)
+		}
+	}
 
 	return ts
 }
 
 // TryUnwrap allows unwrapping a chunk as a trojan message and calling its handlers based on the topic.
 func (p *pss) TryUnwrap(c swarm.Chunk) {
+	p.tryUnwrap(c, false)
+}
+
+// TryUnwrapOrKeep unwraps the chunk like TryUnwrap and keeps it in the
+// mailbox if no handler is registered for its topic. It is called only with
+// the chunks flagged by their senders to be kept, as any content chunk may
+// be taken for a trojan message of an unknown topic.
+func (p *pss) TryUnwrapOrKeep(c swarm.Chunk) {
+	p.tryUnwrap(c, true)
+}
+
+func (p *pss) tryUnwrap(c swarm.Chunk, keep bool) {
 	if len(c.Data()) < swarm.ChunkWithSpanSize {
 		return // chunk not full
 	}
@@ -154,11 +215,37 @@ func (p *pss) TryUnwrap(c swarm.Chunk) {
 	if err != nil {
 		return // cannot unwrap
 	}
-	h := p.getHandlers(topic)
-	if h == nil {
+	if msg == nil {
+		if keep {
+			p.keep(c) // no topic matched
+		}
+		return
+	}
+	h, rh := p.getHandlers(topic)
+	if h == nil && rh == nil {
+		if keep {
+			p.keep(c)
+		}
 		return // no handler
 	}
 
+	// the messages decoded as requests are delivered to the request
+	// handlers only, the rest to the handlers only
+	var req *request
+	if rh != nil {
+		req = new(request)
+		if err := req.UnmarshalBinary(msg); err != nil {
+			req = nil
+		}
+	}
+	if req != nil {
+		h = nil
+		if !p.allowRequest(req) {
+			p.metrics.TotalRequestsLimitedCounter.Inc()
+			return
+		}
+	}
+
 	ctx, cancel := context.WithCancel(ctx)
 	done := make(chan struct{})
 	var wg sync.WaitGroup
@@ -176,15 +263,106 @@ func (p *pss) TryUnwrap(c swarm.Chunk) {
 			hh(ctx, msg)
 		}(*hh)
 	}
+	if req != nil {
+		for _, hh := range rh {
+			wg.Add(1)
+			go func(hh *requestHandler) {
+				defer wg.Done()
+				p.handleRequest(ctx, hh, req)
+			}(hh)
+		}
+	}
 	go func() {
 		wg.Wait()
 		close(done)
 	}()
 }
 
-func (p *pss) getHandlers(topic Topic) []*Handler {
+// keep stores the undeliverable chunk in the mailbox.
+func (p *pss) keep(c swarm.Chunk) {
+	if p.mailbox == nil {
+		return
+	}
+	if err := p.mailbox.Put(c); err != nil {
+		p.logger.Debugf("pss: mailbox put %s: %v", c.Address(), err)
+		return
+	}
+	p.metrics.TotalMailboxMessagesCounter.Inc()
+}
+
+// MailboxMessages returns the messages with the given topic received since
+// the given time. The chunks received after the previous query are pulled
+// from the mailbox of the node and the ones of its neighbourhood peers, and
+// the messages with the topic are indexed in the inbox of the topic.
+func (p *pss) MailboxMessages(ctx context.Context, topic Topic, since time.Time) ([]MailboxMessage, error) {
+	p.inboxesMu.Lock()
+	defer p.inboxesMu.Unlock()
+
+	if p.mailbox == nil && p.fetcher == nil {
+		return nil, ErrMailboxDisabled
+	}
+
+	in, ok := p.inboxes[topic]
+	if !ok {
+		in = newInbox()
+		p.inboxes[topic] = in
+	}
+	var (
+		now       = time.Now()
+		expired   = now.Add(-DefaultMailboxTTL)
+		pullSince = since
+	)
+	in.expire(expired)
+	if pullSince.Before(expired) {
+		pullSince = expired // the messages received before are not kept
+	}
+
+	from := in.window(pullSince)
+	var (
+		prefix = p.overlay.Bytes()[:mailboxPrefixLength]
+		seen   = make(map[string]struct{})
+	)
+	add := func(received time.Time, c swarm.Chunk) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, ok := seen[c.Address().ByteString()]; ok || in.has(c.Address()) {
+			return nil
+		}
+		seen[c.Address().ByteString()] = struct{}{}
+		_, msg, err := Unwrap(ctx, p.key, c, []Topic{topic})
+		if err != nil || msg == nil {
+			return nil // not a message with the topic
+		}
+		in.add(c.Address(), MailboxMessage{Received: received, Payload: msg})
+		return nil
+	}
+
+	if p.mailbox != nil {
+		err := p.mailbox.Iterate(from, prefix, func(received time.Time, c swarm.Chunk) (bool, error) {
+			return false, add(received, c)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if p.fetcher != nil {
+		if err := p.fetcher.FetchMailbox(ctx, prefix, from, add); err != nil {
+			return nil, err
+		}
+	}
+
+	if in.pulled.IsZero() || pullSince.Before(in.from) {
+		in.from = pullSince
+	}
+	in.pulled = now
+
+	return in.since(since), nil
+}
+
+func (p *pss) getHandlers(topic Topic) ([]*Handler, []*requestHandler) {
 	p.handlersMu.Lock()
 	defer p.handlersMu.Unlock()
 
-	return p.handlers[topic]
+	return p.handlers[topic], p.requestHandlers[topic]
 }
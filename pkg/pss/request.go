@@ -0,0 +1,200 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pss
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/ethersphere/bee/pkg/postage"
+)
+
+const (
+	// replyTargetLength is the length of the prefix of the sender overlay
+	// address used as the target of the reply.
+	replyTargetLength = 2
+	// requestHeaderSize is the size of the request header, the compressed
+	// public key of the sender, the reply topic and the reply target.
+	requestHeaderSize = btcec.PubKeyBytesLenCompressed + len(Topic{}) + replyTargetLength
+
+	// requestLimitRate and requestLimitBurst limit the requests answered
+	// for a single sender key, as every reply is paid with the postage of
+	// the responder.
+	requestLimitRate  = time.Second
+	requestLimitBurst = 10
+	// totalRequestLimitRate and totalRequestLimitBurst limit the requests
+	// answered for all the senders, as the sender keys are not
+	// authenticated and so can be changed at will.
+	totalRequestLimitRate  = 100 * time.Millisecond
+	totalRequestLimitBurst = 100
+	totalRequestLimitKey   = ""
+)
+
+var (
+	// ErrInvalidRequest is returned when a message can not be decoded as a request.
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrClosed is returned when waiting for a reply while pss is shutting down.
+	ErrClosed = errors.New("pss closed")
+
+	errNoOverlay = errors.New("no overlay address to receive the reply")
+)
+
+type Requester interface {
+	// Request sends the payload with the given topic to targets and waits for
+	// the reply of the recipient until the context is done.
+	Request(context.Context, Topic, []byte, postage.Stamper, *ecdsa.PublicKey, Targets) ([]byte, error)
+	// RegisterRequestHandler registers a RequestHandler for a given Topic.
+	// The replies are stamped with the given stamper. The requests beyond
+	// the request limits are dropped, and the requests of the Topic are
+	// not delivered to the handlers registered with Register.
+	RegisterRequestHandler(Topic, postage.Stamper, RequestHandler) func()
+}
+
+// RequestHandler defines code to be executed upon reception of a request.
+// The returned payload is sent back to the sender of the request, no reply
+// is sent if an error is returned.
+type RequestHandler func(context.Context, []byte) ([]byte, error)
+
+type requestHandler struct {
+	handler RequestHandler
+	stamper postage.Stamper
+}
+
+// request is a message asking the recipient for a reply.
+type request struct {
+	sender      *ecdsa.PublicKey
+	replyTopic  Topic
+	replyTarget Target
+	payload     []byte
+}
+
+// MarshalBinary serialises the request as the compressed public key of the
+// sender, the reply topic and the reply target followed by the payload.
+func (r *request) MarshalBinary() ([]byte, error) {
+	if len(r.replyTarget) != replyTargetLength {
+		return nil, ErrInvalidRequest
+	}
+	b := make([]byte, 0, requestHeaderSize+len(r.payload))
+	b = append(b, (*btcec.PublicKey)(r.sender).SerializeCompressed()...)
+	b = append(b, r.replyTopic[:]...)
+	b = append(b, r.replyTarget...)
+	return append(b, r.payload...), nil
+}
+
+// UnmarshalBinary deserialises a request.
+func (r *request) UnmarshalBinary(b []byte) error {
+	if len(b) < requestHeaderSize {
+		return ErrInvalidRequest
+	}
+	sender, err := btcec.ParsePubKey(b[:btcec.PubKeyBytesLenCompressed], btcec.S256())
+	if err != nil {
+		return ErrInvalidRequest
+	}
+	b = b[btcec.PubKeyBytesLenCompressed:]
+	r.sender = (*ecdsa.PublicKey)(sender)
+	copy(r.replyTopic[:], b)
+	b = b[len(r.replyTopic):]
+	r.replyTarget = append(Target{}, b[:replyTargetLength]...)
+	r.payload = b[replyTargetLength:]
+	return nil
+}
+
+// Request sends a request with an ephemeral reply topic and waits for the
+// reply. The reply is encrypted for the pss key of the node and sent to the
+// neighbourhood of its overlay address.
+func (p *pss) Request(ctx context.Context, topic Topic, payload []byte, stamper postage.Stamper, recipient *ecdsa.PublicKey, targets Targets) ([]byte, error) {
+	if len(p.overlay.Bytes()) < replyTargetLength {
+		return nil, errNoOverlay
+	}
+
+	var replyTopic Topic
+	if _, err := rand.Read(replyTopic[:]); err != nil {
+		return nil, err
+	}
+
+	replyC := make(chan []byte, 1)
+	cleanup := p.Register(replyTopic, func(_ context.Context, m []byte) {
+		select {
+		case replyC <- m:
+		default:
+		}
+	})
+	defer cleanup()
+
+	req := &request{
+		sender:      &p.key.PublicKey,
+		replyTopic:  replyTopic,
+		replyTarget: Target(p.overlay.Bytes()[:replyTargetLength]),
+		payload:     payload,
+	}
+	msg, err := req.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.Send(ctx, topic, msg, stamper, recipient, targets); err != nil {
+		return nil, err
+	}
+	p.metrics.TotalRequestsSentCounter.Inc()
+
+	select {
+	case reply := <-replyC:
+		return reply, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-p.quit:
+		return nil, ErrClosed
+	}
+}
+
+// RegisterRequestHandler allows the definition of a RequestHandler func for
+// a specific topic on the pss struct.
+func (p *pss) RegisterRequestHandler(topic Topic, stamper postage.Stamper, handler RequestHandler) (cleanup func()) {
+	p.handlersMu.Lock()
+	defer p.handlersMu.Unlock()
+
+	h := &requestHandler{handler: handler, stamper: stamper}
+	p.requestHandlers[topic] = append(p.requestHandlers[topic], h)
+
+	return func() {
+		p.handlersMu.Lock()
+		defer p.handlersMu.Unlock()
+
+		hs := p.requestHandlers[topic]
+		for i := 0; i < len(hs); i++ {
+			if hs[i] == h {
+				p.requestHandlers[topic] = append(hs[:i], hs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// allowRequest reports whether the request is within the limits of the
+// requests answered for its sender and for all the senders.
+func (p *pss) allowRequest(req *request) bool {
+	key := hex.EncodeToString((*btcec.PublicKey)(req.sender).SerializeCompressed())
+	return p.requestLimiter.Allow(key, 1) && p.totalRequestLimiter.Allow(totalRequestLimitKey, 1)
+}
+
+// handleRequest calls the request handler and sends its reply back
+// to the sender of the request.
+func (p *pss) handleRequest(ctx context.Context, h *requestHandler, req *request) {
+	reply, err := h.handler(ctx, req.payload)
+	if err != nil {
+		p.logger.Debugf("pss: request handler: %v", err)
+		return
+	}
+	err = p.Send(ctx, req.replyTopic, reply, h.stamper, req.sender, Targets{req.replyTarget})
+	if err != nil {
+		p.logger.Debugf("pss: send reply: %v", err)
+		return
+	}
+	p.metrics.TotalRepliesSentCounter.Inc()
+}
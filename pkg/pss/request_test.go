@@ -0,0 +1,161 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pss_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/pss"
+	"github.com/ethersphere/bee/pkg/pushsync"
+	pushsyncmock "github.com/ethersphere/bee/pkg/pushsync/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestRequest sends a request from one pss service to another and
+// verifies that the reply of the request handler is delivered back
+// to the neighbourhood of the sender.
+func TestRequest(t *testing.T) {
+	senderKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipientKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		logger         = logging.New(ioutil.Discard, 0)
+		topic          = pss.NewTopic("rpc")
+		target         = pss.Target([]byte{1})
+		senderOverlay  = swarm.MustParseHexAddress("abcd000000000000000000000000000000000000000000000000000000000000")
+		sender         = pss.New(senderKey, senderOverlay, nil, logger)
+		recipient      = pss.New(recipientKey, swarm.ZeroAddress, nil, logger)
+		replyAddresses = make(chan swarm.Address, 1)
+	)
+
+	// the pushed chunks are delivered to the other service
+	sender.SetPushSyncer(pushsyncmock.New(func(_ context.Context, ch swarm.Chunk) (*pushsync.Receipt, error) {
+		go recipient.TryUnwrap(ch)
+		return nil, nil
+	}))
+	recipient.SetPushSyncer(pushsyncmock.New(func(_ context.Context, ch swarm.Chunk) (*pushsync.Receipt, error) {
+		replyAddresses <- ch.Address()
+		go sender.TryUnwrap(ch)
+		return nil, nil
+	}))
+
+	recipient.RegisterRequestHandler(topic, &stamper{}, func(_ context.Context, m []byte) ([]byte, error) {
+		if string(m) == "fail" {
+			return nil, errors.New("handler failed")
+		}
+		return append([]byte("re: "), m...), nil
+	})
+	plainC := make(chan []byte, 1)
+	recipient.Register(topic, func(_ context.Context, m []byte) {
+		plainC <- m
+	})
+
+	t.Run("reply", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		reply, err := sender.Request(ctx, topic, []byte("ping"), &stamper{}, &recipientKey.PublicKey, pss.Targets{target})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []byte("re: ping"); !bytes.Equal(reply, want) {
+			t.Fatalf("got reply %q, want %q", reply, want)
+		}
+		if addr := <-replyAddresses; !bytes.HasPrefix(addr.Bytes(), senderOverlay.Bytes()[:2]) {
+			t.Fatalf("reply address %s not in the neighbourhood of %s", addr, senderOverlay)
+		}
+		select {
+		case m := <-plainC:
+			t.Fatalf("request %q delivered to the handler", m)
+		default:
+		}
+	})
+
+	t.Run("no reply", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		_, err := sender.Request(ctx, topic, []byte("fail"), &stamper{}, &recipientKey.PublicKey, pss.Targets{target})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+}
+
+// TestRequestLimit tests that the requests of a sender are not handled
+// once the sender exceeded the request limit, while the requests of the
+// other senders are.
+func TestRequestLimit(t *testing.T) {
+	recipientKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		logger    = logging.New(ioutil.Discard, 0)
+		topic     = pss.NewTopic("rpc")
+		target    = pss.Target([]byte{1})
+		recipient = pss.New(recipientKey, swarm.ZeroAddress, nil, logger)
+		requestC  = make(chan []byte, 10)
+	)
+	pss.SetRequestLimit(recipient, time.Hour, 1)
+
+	// no reply is sent, the handled requests are recorded
+	recipient.RegisterRequestHandler(topic, &stamper{}, func(_ context.Context, m []byte) ([]byte, error) {
+		requestC <- m
+		return nil, errors.New("no reply")
+	})
+
+	newSender := func(t *testing.T) pss.Interface {
+		t.Helper()
+		key, err := crypto.GenerateSecp256k1Key()
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := pss.New(key, swarm.MustParseHexAddress("abcd000000000000000000000000000000000000000000000000000000000000"), nil, logger)
+		s.SetPushSyncer(pushsyncmock.New(func(_ context.Context, ch swarm.Chunk) (*pushsync.Receipt, error) {
+			recipient.TryUnwrap(ch)
+			return nil, nil
+		}))
+		return s
+	}
+	request := func(t *testing.T, s pss.Interface, payload string, handled bool) {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if _, err := s.Request(ctx, topic, []byte(payload), &stamper{}, &recipientKey.PublicKey, pss.Targets{target}); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+		}
+		select {
+		case m := <-requestC:
+			if !handled {
+				t.Fatalf("request %q handled", m)
+			}
+			if string(m) != payload {
+				t.Fatalf("got request %q, want %q", m, payload)
+			}
+		default:
+			if handled {
+				t.Fatalf("request %q not handled", payload)
+			}
+		}
+	}
+
+	sender := newSender(t)
+	request(t, sender, "first", true)
+	request(t, sender, "second", false)
+	request(t, newSender(t), "other", true)
+}
@@ -26,6 +26,7 @@ type Delivery struct {
 	Address []byte `protobuf:"bytes,1,opt,name=Address,proto3" json:"Address,omitempty"`
 	Data    []byte `protobuf:"bytes,2,opt,name=Data,proto3" json:"Data,omitempty"`
 	Stamp   []byte `protobuf:"bytes,3,opt,name=Stamp,proto3" json:"Stamp,omitempty"`
+	Mailbox bool   `protobuf:"varint,4,opt,name=Mailbox,proto3" json:"Mailbox,omitempty"`
 }
 
 func (m *Delivery) Reset()         { *m = Delivery{} }
@@ -82,6 +83,13 @@ func (m *Delivery) GetStamp() []byte {
 	return nil
 }
 
+func (m *Delivery) GetMailbox() bool {
+	if m != nil {
+		return m.Mailbox
+	}
+	return false
+}
+
 type Receipt struct {
 	Address   []byte `protobuf:"bytes,1,opt,name=Address,proto3" json:"Address,omitempty"`
 	Signature []byte `protobuf:"bytes,2,opt,name=Signature,proto3" json:"Signature,omitempty"`
@@ -150,19 +158,20 @@ func init() {
 func init() { proto.RegisterFile("pushsync.proto", fileDescriptor_723cf31bfc02bfd6) }
 
 var fileDescriptor_723cf31bfc02bfd6 = []byte{
-	// 185 bytes of a gzipped FileDescriptorProto
+	// 200 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x2b, 0x28, 0x2d, 0xce,
-	0x28, 0xae, 0xcc, 0x4b, 0xd6, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2, 0x80, 0xf1, 0x95, 0xfc,
+	0x28, 0xae, 0xcc, 0x4b, 0xd6, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2, 0x80, 0xf1, 0x95, 0x32,
 	0xb8, 0x38, 0x5c, 0x52, 0x73, 0x32, 0xcb, 0x52, 0x8b, 0x2a, 0x85, 0x24, 0xb8, 0xd8, 0x1d, 0x53,
 	0x52, 0x8a, 0x52, 0x8b, 0x8b, 0x25, 0x18, 0x15, 0x18, 0x35, 0x78, 0x82, 0x60, 0x5c, 0x21, 0x21,
 	0x2e, 0x16, 0x97, 0xc4, 0x92, 0x44, 0x09, 0x26, 0xb0, 0x30, 0x98, 0x2d, 0x24, 0xc2, 0xc5, 0x1a,
-	0x5c, 0x92, 0x98, 0x5b, 0x20, 0xc1, 0x0c, 0x16, 0x84, 0x70, 0x94, 0xe2, 0xb9, 0xd8, 0x83, 0x52,
-	0x93, 0x53, 0x33, 0x0b, 0x4a, 0xf0, 0x18, 0x27, 0xc3, 0xc5, 0x19, 0x9c, 0x99, 0x9e, 0x97, 0x58,
-	0x52, 0x5a, 0x94, 0x0a, 0x35, 0x13, 0x21, 0x00, 0x92, 0x75, 0xca, 0xc9, 0x4f, 0xce, 0xf6, 0x48,
-	0x2c, 0xce, 0x80, 0x1a, 0x8e, 0x10, 0x70, 0x92, 0x39, 0xf1, 0x48, 0x8e, 0xf1, 0xc2, 0x23, 0x39,
-	0xc6, 0x07, 0x8f, 0xe4, 0x18, 0x27, 0x3c, 0x96, 0x63, 0xb8, 0xf0, 0x58, 0x8e, 0xe1, 0xc6, 0x63,
-	0x39, 0x86, 0x28, 0xa6, 0x82, 0xa4, 0x24, 0x36, 0xb0, 0xff, 0x8c, 0x01, 0x01, 0x00, 0x00, 0xff,
-	0xff, 0xd8, 0x59, 0x25, 0xda, 0xf1, 0x00, 0x00, 0x00,
+	0x5c, 0x92, 0x98, 0x5b, 0x20, 0xc1, 0x0c, 0x16, 0x84, 0x70, 0x40, 0x66, 0xf8, 0x26, 0x66, 0xe6,
+	0x24, 0xe5, 0x57, 0x48, 0xb0, 0x28, 0x30, 0x6a, 0x70, 0x04, 0xc1, 0xb8, 0x4a, 0xf1, 0x5c, 0xec,
+	0x41, 0xa9, 0xc9, 0xa9, 0x99, 0x05, 0x25, 0x78, 0x2c, 0x92, 0xe1, 0xe2, 0x0c, 0xce, 0x4c, 0xcf,
+	0x4b, 0x2c, 0x29, 0x2d, 0x4a, 0x85, 0xda, 0x86, 0x10, 0x00, 0xc9, 0x3a, 0xe5, 0xe4, 0x27, 0x67,
+	0x7b, 0x24, 0x16, 0x67, 0x40, 0xad, 0x45, 0x08, 0x38, 0xc9, 0x9c, 0x78, 0x24, 0xc7, 0x78, 0xe1,
+	0x91, 0x1c, 0xe3, 0x83, 0x47, 0x72, 0x8c, 0x13, 0x1e, 0xcb, 0x31, 0x5c, 0x78, 0x2c, 0xc7, 0x70,
+	0xe3, 0xb1, 0x1c, 0x43, 0x14, 0x53, 0x41, 0x52, 0x12, 0x1b, 0xd8, 0xe7, 0xc6, 0x80, 0x01, 0x00,
+	0xe6, 0x59, 0x71, 0xf3, 0x0b, 0x01, 0x00, 0x00,
 }
 
 func (m *Delivery) Marshal() (dAtA []byte, err error) {
@@ -185,6 +194,16 @@ func (m *Delivery) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Mailbox {
+		i--
+		if m.Mailbox {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x20
+	}
 	if len(m.Stamp) > 0 {
 		i -= len(m.Stamp)
 		copy(dAtA[i:], m.Stamp)
@@ -282,6 +301,9 @@ func (m *Delivery) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovPushsync(uint64(l))
 	}
+	if m.Mailbox {
+		n += 2
+	}
 	return n
 }
 
@@ -443,6 +465,26 @@ func (m *Delivery) Unmarshal(dAtA []byte) error {
 				m.Stamp = []byte{}
 			}
 			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Mailbox", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPushsync
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Mailbox = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipPushsync(dAtA[iNdEx:])
@@ -53,6 +53,31 @@ type PushSyncer interface {
 	PushChunkToClosest(ctx context.Context, ch swarm.Chunk) (*Receipt, error)
 }
 
+// Unwrapper unwraps the trojan messages of the delivered chunks.
+type Unwrapper interface {
+	// TryUnwrap tries to unwrap a trojan message.
+	TryUnwrap(swarm.Chunk)
+	// TryUnwrapOrKeep tries to unwrap a trojan message and keeps it
+	// for the offline recipient if it can not be delivered.
+	TryUnwrapOrKeep(swarm.Chunk)
+}
+
+type mailboxKey struct{}
+
+// WithMailbox returns the context of pushing a trojan chunk flagged by the
+// sender to be kept by the neighbourhood of its address if the message can
+// not be delivered. The flag is seen by the forwarding peers.
+func WithMailbox(ctx context.Context) context.Context {
+	return context.WithValue(ctx, mailboxKey{}, true)
+}
+
+// isMailbox reports whether the chunk pushed with the context is flagged to
+// be kept by the neighbourhood.
+func isMailbox(ctx context.Context) bool {
+	v, _ := ctx.Value(mailboxKey{}).(bool)
+	return v
+}
+
 type Receipt struct {
 	Address   swarm.Address
 	Signature []byte
@@ -66,7 +91,7 @@ type PushSync struct {
 	storer         storage.Putter
 	topologyDriver topology.Driver
 	tagger         *tags.Tags
-	unwrap         func(swarm.Chunk)
+	unwrap         Unwrapper
 	logger         logging.Logger
 	accounting     accounting.Interface
 	pricer         pricer.Interface
@@ -83,7 +108,7 @@ var defaultTTL = 20 * time.Second                     // request time to live
 var timeToWaitForPushsyncToNeighbor = 3 * time.Second // time to wait to get a receipt for a chunk
 var nPeersToPushsync = 3                              // number of peers to replicate to as receipt is sent upstream
 
-func New(address swarm.Address, blockHash []byte, streamer p2p.StreamerDisconnecter, storer storage.Putter, topology topology.Driver, tagger *tags.Tags, isFullNode bool, unwrap func(swarm.Chunk), validStamp func(swarm.Chunk, []byte) (swarm.Chunk, error), logger logging.Logger, accounting accounting.Interface, pricer pricer.Interface, signer crypto.Signer, tracer *tracing.Tracer, warmupTime time.Duration) *PushSync {
+func New(address swarm.Address, blockHash []byte, streamer p2p.StreamerDisconnecter, storer storage.Putter, topology topology.Driver, tagger *tags.Tags, isFullNode bool, unwrap Unwrapper, validStamp func(swarm.Chunk, []byte) (swarm.Chunk, error), logger logging.Logger, accounting accounting.Interface, pricer pricer.Interface, signer crypto.Signer, tracer *tracing.Tracer, warmupTime time.Duration) *PushSync {
 	ps := &PushSync{
 		address:        address,
 		blockHash:      blockHash,
@@ -138,6 +163,9 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 		return fmt.Errorf("pushsync read delivery: %w", err)
 	}
 	ps.metrics.TotalReceived.Inc()
+	if ch.Mailbox {
+		ctx = WithMailbox(ctx)
+	}
 
 	chunk := swarm.NewChunk(swarm.NewAddress(ch.Address), ch.Data)
 	chunkAddress := chunk.Address()
@@ -150,8 +178,13 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 	chunk.WithStamp(stamp)
 
 	if cac.Valid(chunk) {
-		if ps.unwrap != nil {
-			go ps.unwrap(chunk)
+		switch {
+		case ps.unwrap == nil:
+		case ch.Mailbox && ps.topologyDriver.IsWithinDepth(chunkAddress):
+			// only the flagged chunks stored by the node are kept
+			go ps.unwrap.TryUnwrapOrKeep(chunk)
+		default:
+			go ps.unwrap.TryUnwrap(chunk)
 		}
 	} else if !soc.Valid(chunk) {
 		return swarm.ErrInvalidChunk
@@ -345,7 +378,7 @@ func (ps *PushSync) pushToClosest(ctx context.Context, ch swarm.Chunk, retryAllo
 						return true, false, nil
 					}
 					count++
-					go ps.pushToNeighbour(peer, ch, retryAllowed)
+					go ps.pushToNeighbour(peer, ch, retryAllowed, isMailbox(ctx))
 					return false, false, nil
 				})
 				return nil, err
@@ -438,6 +471,7 @@ func (ps *PushSync) pushPeer(ctx context.Context, peer swarm.Address, ch swarm.C
 		Address: ch.Address().Bytes(),
 		Data:    ch.Data(),
 		Stamp:   stamp,
+		Mailbox: isMailbox(ctx),
 	}); err != nil {
 		_ = streamer.Reset()
 		return nil, false, fmt.Errorf("chunk %s deliver to peer %s: %w", ch.Address(), peer, err)
@@ -474,7 +508,7 @@ func (ps *PushSync) pushPeer(ctx context.Context, peer swarm.Address, ch swarm.C
 }
 
 // pushToNeighbour handles in-neighborhood replication for a single peer.
-func (ps *PushSync) pushToNeighbour(peer swarm.Address, ch swarm.Chunk, origin bool) {
+func (ps *PushSync) pushToNeighbour(peer swarm.Address, ch swarm.Chunk, origin, mailbox bool) {
 	var err error
 	defer func() {
 		if err != nil {
@@ -522,6 +556,7 @@ func (ps *PushSync) pushToNeighbour(peer swarm.Address, ch swarm.Chunk, origin b
 		Address: ch.Address().Bytes(),
 		Data:    ch.Data(),
 		Stamp:   stamp,
+		Mailbox: mailbox,
 	})
 	if err != nil {
 		return
@@ -112,6 +112,61 @@ func TestPushClosest(t *testing.T) {
 	}
 }
 
+// TestPushMailbox tests that the chunks pushed with the mailbox flag are kept
+// by the storer if undeliverable and the other chunks are only unwrapped.
+func TestPushMailbox(t *testing.T) {
+	pivotNode := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
+	closestPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
+
+	type unwrapped struct {
+		addr swarm.Address
+		keep bool
+	}
+	unwrappedC := make(chan unwrapped, 2)
+	unwrap := unwrapFunc(func(ch swarm.Chunk, keep bool) {
+		unwrappedC <- unwrapped{ch.Address(), keep}
+	})
+
+	psPeer, storerPeer, _, _ := createPushSyncNode(t, closestPeer, defaultPrices, nil, unwrap, defaultSigner, mock.WithClosestPeerErr(topology.ErrWantSelf), WithinDepthMock)
+	defer storerPeer.Close()
+
+	recorder := streamtest.New(streamtest.WithProtocols(psPeer.Protocol()), streamtest.WithBaseAddr(pivotNode))
+
+	psPivot, storerPivot, _, _ := createPushSyncNode(t, pivotNode, defaultPrices, recorder, nil, defaultSigner, mock.WithClosestPeer(closestPeer))
+	defer storerPivot.Close()
+
+	for _, tc := range []struct {
+		name    string
+		chunk   swarm.Chunk
+		mailbox bool
+	}{
+		{name: "not flagged", chunk: testingc.FixtureChunk("7000")},
+		{name: "flagged", chunk: testingc.FixtureChunk("0033"), mailbox: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.mailbox {
+				ctx = pushsync.WithMailbox(ctx)
+			}
+			if _, err := psPivot.PushChunkToClosest(ctx, tc.chunk); err != nil {
+				t.Fatal(err)
+			}
+
+			select {
+			case u := <-unwrappedC:
+				if !u.addr.Equal(tc.chunk.Address()) {
+					t.Fatalf("got unwrapped chunk %s, want %s", u.addr, tc.chunk.Address())
+				}
+				if u.keep != tc.mailbox {
+					t.Fatalf("got keep %v, want %v", u.keep, tc.mailbox)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("chunk not unwrapped")
+			}
+		})
+	}
+}
+
 // TestReplicateBeforeReceipt tests that a chunk is pushed and a receipt is received.
 // Also the storer node initiates a pushsync to N closest nodes of the chunk as it's sending back the receipt.
 // The second storer should only store it and not forward it. The balance of all nodes is tested.
@@ -899,14 +954,14 @@ func TestPushChunkToClosestSkipFailed(t *testing.T) {
 	}
 }
 
-func createPushSyncNode(t *testing.T, addr swarm.Address, prices pricerParameters, recorder *streamtest.Recorder, unwrap func(swarm.Chunk), signer crypto.Signer, mockOpts ...mock.Option) (*pushsync.PushSync, *mocks.MockStorer, *tags.Tags, accounting.Interface) {
+func createPushSyncNode(t *testing.T, addr swarm.Address, prices pricerParameters, recorder *streamtest.Recorder, unwrap pushsync.Unwrapper, signer crypto.Signer, mockOpts ...mock.Option) (*pushsync.PushSync, *mocks.MockStorer, *tags.Tags, accounting.Interface) {
 	t.Helper()
 	mockAccounting := accountingmock.NewAccounting()
 	ps, mstorer, ts := createPushSyncNodeWithAccounting(t, addr, prices, recorder, unwrap, signer, mockAccounting, mockOpts...)
 	return ps, mstorer, ts, mockAccounting
 }
 
-func createPushSyncNodeWithAccounting(t *testing.T, addr swarm.Address, prices pricerParameters, recorder *streamtest.Recorder, unwrap func(swarm.Chunk), signer crypto.Signer, acct accounting.Interface, mockOpts ...mock.Option) (*pushsync.PushSync, *mocks.MockStorer, *tags.Tags) {
+func createPushSyncNodeWithAccounting(t *testing.T, addr swarm.Address, prices pricerParameters, recorder *streamtest.Recorder, unwrap pushsync.Unwrapper, signer crypto.Signer, acct accounting.Interface, mockOpts ...mock.Option) (*pushsync.PushSync, *mocks.MockStorer, *tags.Tags) {
 	t.Helper()
 	logger := logging.New(ioutil.Discard, 0)
 	storer := mocks.NewStorer()
@@ -919,7 +974,7 @@ func createPushSyncNodeWithAccounting(t *testing.T, addr swarm.Address, prices p
 
 	recorderDisconnecter := streamtest.NewRecorderDisconnecter(recorder)
 	if unwrap == nil {
-		unwrap = func(swarm.Chunk) {}
+		unwrap = unwrapFunc(func(swarm.Chunk, bool) {})
 	}
 
 	validStamp := func(ch swarm.Chunk, stamp []byte) (swarm.Chunk, error) {
@@ -929,6 +984,14 @@ func createPushSyncNodeWithAccounting(t *testing.T, addr swarm.Address, prices p
 	return pushsync.New(addr, blockHash.Bytes(), recorderDisconnecter, storer, mockTopology, mtag, true, unwrap, validStamp, logger, acct, mockPricer, signer, nil, -1), storer, mtag
 }
 
+// unwrapFunc is a pushsync.Unwrapper calling the function with whether the
+// chunk is to be kept.
+type unwrapFunc func(ch swarm.Chunk, keep bool)
+
+func (f unwrapFunc) TryUnwrap(ch swarm.Chunk) { f(ch, false) }
+
+func (f unwrapFunc) TryUnwrapOrKeep(ch swarm.Chunk) { f(ch, true) }
+
 func waitOnRecordAndTest(t *testing.T, peer swarm.Address, recorder *streamtest.Recorder, add swarm.Address, data []byte) {
 	t.Helper()
 	records := recorder.WaitRecords(t, peer, pushsync.ProtocolName, pushsync.ProtocolVersion, pushsync.StreamName, 1, 5)
@@ -979,8 +1042,8 @@ func waitOnRecordAndTest(t *testing.T, peer swarm.Address, recorder *streamtest.
 	}
 }
 
-func chanFunc(c chan<- struct{}) func(swarm.Chunk) {
-	return func(_ swarm.Chunk) {
+func chanFunc(c chan<- struct{}) pushsync.Unwrapper {
+	return unwrapFunc(func(swarm.Chunk, bool) {
 		c <- struct{}{}
-	}
+	})
 }
@@ -53,3 +53,75 @@ func (l *Limiter) Clear(key string) {
 
 	delete(l.limiter, key)
 }
+
+// Quota limits the amount used by 'key' within consecutive fixed time
+// windows. Unlike the Limiter, the usage is accounted for after the fact,
+// so it fits the amounts that are not known upfront, like the bytes
+// transferred by a request.
+type Quota struct {
+	mtx     sync.Mutex
+	usage   map[string]*usage
+	window  time.Duration
+	limit   int64
+	now     func() time.Time
+	pruneAt time.Time
+}
+
+type usage struct {
+	start  time.Time
+	amount int64
+}
+
+// NewQuota returns a new Quota object allowing the limit amount per window.
+func NewQuota(window time.Duration, limit int64) *Quota {
+	return &Quota{
+		usage:  make(map[string]*usage),
+		window: window,
+		limit:  limit,
+		now:    time.Now,
+	}
+}
+
+// Wait returns zero if 'key' has not exceeded the quota in the current
+// window, otherwise the duration until the next window starts.
+func (q *Quota) Wait(key string) time.Duration {
+
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	now := q.now()
+	u := q.current(key, now)
+	if u.amount < q.limit {
+		return 0
+	}
+	return u.start.Add(q.window).Sub(now)
+}
+
+// Use adds the amount to the usage of 'key' in the current window.
+func (q *Quota) Use(key string, amount int64) {
+
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	q.current(key, q.now()).amount += amount
+}
+
+// current returns the usage of the window that is current at the time now,
+// the usages of the past windows are removed once per window.
+func (q *Quota) current(key string, now time.Time) *usage {
+	if !now.Before(q.pruneAt) {
+		for k, u := range q.usage {
+			if !now.Before(u.start.Add(q.window)) {
+				delete(q.usage, k)
+			}
+		}
+		q.pruneAt = now.Add(q.window)
+	}
+
+	u, ok := q.usage[key]
+	if !ok || !now.Before(u.start.Add(q.window)) {
+		u = &usage{start: now}
+		q.usage[key] = u
+	}
+	return u
+}
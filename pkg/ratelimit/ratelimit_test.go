@@ -40,3 +40,41 @@ func TestRateLimit(t *testing.T) {
 		t.Fatal("want allowed")
 	}
 }
+
+func TestQuota(t *testing.T) {
+
+	var (
+		key1   = "test1"
+		key2   = "test2"
+		window = time.Minute
+		now    = time.Unix(1000, 0)
+	)
+
+	quota := ratelimit.NewQuota(window, 100)
+	ratelimit.SetQuotaTime(quota, func() time.Time { return now })
+
+	if d := quota.Wait(key1); d != 0 {
+		t.Fatalf("got wait %v, want none", d)
+	}
+
+	quota.Use(key1, 60)
+	if d := quota.Wait(key1); d != 0 {
+		t.Fatalf("got wait %v, want none", d)
+	}
+
+	// the usage is allowed to overshoot the limit
+	quota.Use(key1, 60)
+	now = now.Add(10 * time.Second)
+	if d := quota.Wait(key1); d != 50*time.Second {
+		t.Fatalf("got wait %v, want %v", d, 50*time.Second)
+	}
+
+	if d := quota.Wait(key2); d != 0 {
+		t.Fatalf("got wait %v, want none", d)
+	}
+
+	now = now.Add(50 * time.Second)
+	if d := quota.Wait(key1); d != 0 {
+		t.Fatalf("got wait %v in the next window, want none", d)
+	}
+}
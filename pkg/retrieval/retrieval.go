@@ -15,6 +15,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/accessstats"
 	"github.com/ethersphere/bee/pkg/accounting"
 	"github.com/ethersphere/bee/pkg/cac"
 	"github.com/ethersphere/bee/pkg/logging"
@@ -64,6 +65,7 @@ type Service struct {
 	metrics       metrics
 	pricer        pricer.Interface
 	tracer        *tracing.Tracer
+	accessStats   *accessstats.Service
 }
 
 func New(addr swarm.Address, storer storage.Storer, streamer p2p.Streamer, chunkPeerer topology.EachPeerer, logger logging.Logger, accounting accounting.Interface, pricer pricer.Interface, tracer *tracing.Tracer) *Service {
@@ -80,6 +82,12 @@ func New(addr swarm.Address, storer storage.Storer, streamer p2p.Streamer, chunk
 	}
 }
 
+// SetAccessStats sets the service counting the chunks served from the
+// local store.
+func (s *Service) SetAccessStats(accessStats *accessstats.Service) {
+	s.accessStats = accessStats
+}
+
 func (s *Service) Protocol() p2p.ProtocolSpec {
 	return p2p.ProtocolSpec{
 		Name:    protocolName,
@@ -427,6 +435,8 @@ func (s *Service) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (e
 		} else {
 			return fmt.Errorf("get from store: %w", err)
 		}
+	} else {
+		s.accessStats.RecordChunk(addr)
 	}
 
 	stamp, err := chunk.Stamp().MarshalBinary()
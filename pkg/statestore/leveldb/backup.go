@@ -0,0 +1,305 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leveldb
+
+import (
+	"archive/tar"
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+const (
+	// filename in tar archive that holds the backup format version
+	backupVersionFilename = ".swarm-state-version"
+	// filename in tar archive that holds the entries of the state store
+	backupStateFilename = "state"
+	// filename in tar archive that holds the backup manifest,
+	// it is written after the entries
+	backupManifestFilename = ".swarm-state-manifest"
+	// current backup format version
+	currentBackupVersion = "1"
+)
+
+var (
+	// ErrUnsupportedBackup is returned when restoring a backup of an
+	// unsupported version or from a newer state store schema.
+	ErrUnsupportedBackup = errors.New("unsupported state store backup")
+	// ErrInvalidBackup is returned when restoring an incomplete backup or
+	// one not matching its manifest.
+	ErrInvalidBackup = errors.New("invalid state store backup")
+	// ErrNotEmpty is returned when restoring to a non empty directory.
+	ErrNotEmpty = errors.New("state store directory not empty")
+)
+
+// BackupManifest describes the content of a backup, it is included in
+// the archive.
+type BackupManifest struct {
+	// Schema is the name of the state store schema.
+	Schema string `json:"schema"`
+	// Entries is the number of the key value pairs.
+	Entries int64 `json:"entries"`
+	// Checksum is the sha256 checksum of the entries file.
+	Checksum string `json:"checksum"`
+}
+
+// Backup writes a tar structured data to the writer of all the entries of
+// the state store, taken from a consistent snapshot of the database, so it
+// is safe to use while the state store is in use. It returns the number of
+// entries written.
+func Backup(db *leveldb.DB, w io.Writer) (count int64, err error) {
+	snap, err := db.GetSnapshot()
+	if err != nil {
+		return 0, fmt.Errorf("snapshot: %w", err)
+	}
+	defer snap.Release()
+
+	schema, err := snap.Get([]byte(dbSchemaKey), nil)
+	if err != nil {
+		return 0, fmt.Errorf("get schema name: %w", err)
+	}
+
+	// the size of the entries file needs to be known before writing
+	// it, the snapshot is iterated twice as it does not change
+	var size int64
+	iter := snap.NewIterator(nil, nil)
+	for iter.Next() {
+		size += entrySize(iter.Key(), iter.Value())
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return 0, err
+	}
+
+	tw := tar.NewWriter(w)
+
+	if err := writeBackupFile(tw, backupVersionFilename, []byte(currentBackupVersion)); err != nil {
+		return 0, err
+	}
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name: backupStateFilename,
+		Mode: 0644,
+		Size: size,
+	}); err != nil {
+		return 0, err
+	}
+	h := sha256.New()
+	bw := bufio.NewWriter(io.MultiWriter(tw, h))
+	iter = snap.NewIterator(nil, nil)
+	defer iter.Release()
+	for iter.Next() {
+		if err := writeEntry(bw, iter.Key(), iter.Value()); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := iter.Error(); err != nil {
+		return count, err
+	}
+	if err := bw.Flush(); err != nil {
+		return count, err
+	}
+
+	data, err := json.Marshal(BackupManifest{
+		Schema:   string(schema),
+		Entries:  count,
+		Checksum: hex.EncodeToString(h.Sum(nil)),
+	})
+	if err != nil {
+		return count, err
+	}
+	if err := writeBackupFile(tw, backupManifestFilename, data); err != nil {
+		return count, err
+	}
+	return count, tw.Close()
+}
+
+// Restore reads a tar structured data of a backup from the reader and
+// writes its entries to a new state store at the path, migrating them to
+// the current schema. The backup is verified against its manifest before
+// any entry is written. It returns the number of entries restored.
+func Restore(path string, r io.Reader, logger logging.Logger) (count int64, err error) {
+	if files, err := ioutil.ReadDir(path); err == nil && len(files) > 0 {
+		return 0, fmt.Errorf("%w: %s", ErrNotEmpty, path)
+	} else if err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+
+	var (
+		version  string
+		checksum string
+		m        *BackupManifest
+		batch    = new(leveldb.Batch)
+	)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return 0, err
+		}
+		switch hdr.Name {
+		case backupVersionFilename:
+			data, err := ioutil.ReadAll(tr)
+			if err != nil {
+				return 0, err
+			}
+			version = string(data)
+			if version != currentBackupVersion {
+				return 0, fmt.Errorf("%w: version %q", ErrUnsupportedBackup, version)
+			}
+		case backupStateFilename:
+			h := sha256.New()
+			br := bufio.NewReader(io.TeeReader(tr, h))
+			for {
+				key, value, err := readEntry(br, hdr.Size)
+				if err != nil {
+					if err == io.EOF {
+						break
+					}
+					return 0, fmt.Errorf("%w: read entry: %v", ErrInvalidBackup, err)
+				}
+				batch.Put(key, value)
+				count++
+			}
+			checksum = hex.EncodeToString(h.Sum(nil))
+		case backupManifestFilename:
+			m = new(BackupManifest)
+			if err := json.NewDecoder(tr).Decode(m); err != nil {
+				return 0, fmt.Errorf("%w: decode manifest: %v", ErrInvalidBackup, err)
+			}
+		default:
+			logger.Warningf("statestore restore: ignoring unknown file: %s", hdr.Name)
+		}
+	}
+
+	if version == "" {
+		return 0, fmt.Errorf("%w: version missing", ErrInvalidBackup)
+	}
+	if m == nil {
+		return 0, fmt.Errorf("%w: manifest missing", ErrInvalidBackup)
+	}
+	if m.Entries != count || m.Checksum != checksum {
+		return 0, fmt.Errorf("%w: got %d entries with checksum %s, want %d entries with checksum %s", ErrInvalidBackup, count, checksum, m.Entries, m.Checksum)
+	}
+	if !knownSchema(m.Schema) {
+		return 0, fmt.Errorf("%w: unknown schema %q", ErrUnsupportedBackup, m.Schema)
+	}
+
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return 0, err
+	}
+	if err := db.Write(batch, nil); err != nil {
+		_ = db.Close()
+		return 0, err
+	}
+	if err := db.Close(); err != nil {
+		return 0, err
+	}
+
+	// opening the state store migrates the entries from the
+	// schema of the backup to the current one
+	s, err := NewStateStore(path, logger)
+	if err != nil {
+		return count, fmt.Errorf("open restored state store: %w", err)
+	}
+	logger.Infof("statestore restore: restored %d entries of schema %s", count, m.Schema)
+	return count, s.Close()
+}
+
+// knownSchema reports whether the schema is the current one or
+// one that can be migrated from.
+func knownSchema(name string) bool {
+	for _, m := range schemaMigrations {
+		if m.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func writeBackupFile(tw *tar.Writer, name string, data []byte) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name: name,
+		Mode: 0644,
+		Size: int64(len(data)),
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// entrySize returns the size of the encoded key value pair.
+func entrySize(key, value []byte) int64 {
+	var buf [binary.MaxVarintLen64]byte
+	return int64(binary.PutUvarint(buf[:], uint64(len(key))) + len(key) +
+		binary.PutUvarint(buf[:], uint64(len(value))) + len(value))
+}
+
+// writeEntry writes the key value pair, each prefixed by its length.
+func writeEntry(w io.Writer, key, value []byte) error {
+	var buf [binary.MaxVarintLen64]byte
+	for _, b := range [][]byte{key, value} {
+		n := binary.PutUvarint(buf[:], uint64(len(b)))
+		if _, err := w.Write(buf[:n]); err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readEntry reads the key value pair written by writeEntry, none of
+// them longer than the limit. It returns io.EOF if there are no more
+// entries.
+func readEntry(r *bufio.Reader, limit int64) (key, value []byte, err error) {
+	key, err = readBytes(r, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	value, err = readBytes(r, limit)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, nil, err
+	}
+	return key, value, nil
+}
+
+func readBytes(r *bufio.Reader, limit int64) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(limit) {
+		return nil, fmt.Errorf("length %d exceeds %d", n, limit)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return b, nil
+}
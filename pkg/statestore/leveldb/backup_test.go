@@ -0,0 +1,110 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leveldb_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/statestore/leveldb"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+func TestBackupRestore(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+
+	store, err := leveldb.NewStateStore(t.TempDir(), logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := store.Close(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// the backup is of an older schema, the entries
+	// removed by the migrations must not be restored
+	if err := store.DB().Put([]byte(leveldb.DbSchemaKey), []byte(leveldb.DbSchemaNoStamp), nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"balance_1", "balance_2", "blocklist-1"} {
+		if err := store.Put(key, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	count, err := leveldb.Backup(store.DB(), &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 4 {
+		t.Fatalf("got backup count %d, want 4", count)
+	}
+	backup := buf.Bytes()
+
+	t.Run("restore", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "statestore")
+		count, err := leveldb.Restore(dir, bytes.NewReader(backup), logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 4 {
+			t.Fatalf("got restore count %d, want 4", count)
+		}
+
+		restored, err := leveldb.NewStateStore(dir, logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer restored.Close()
+
+		for _, key := range []string{"balance_1", "balance_2"} {
+			var got string
+			if err := restored.Get(key, &got); err != nil {
+				t.Fatal(err)
+			}
+			if got != key {
+				t.Fatalf("got value %q, want %q", got, key)
+			}
+		}
+		var got string
+		if err := restored.Get("blocklist-1", &got); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+		}
+		n, err := restored.(interface {
+			GetSchemaName() (string, error)
+		}).GetSchemaName()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != leveldb.DbSchemaCurrent {
+			t.Fatalf("got schema %q, want %q", n, leveldb.DbSchemaCurrent)
+		}
+	})
+
+	t.Run("not empty", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := ioutil.WriteFile(filepath.Join(dir, "file"), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := leveldb.Restore(dir, bytes.NewReader(backup), logger); !errors.Is(err, leveldb.ErrNotEmpty) {
+			t.Fatalf("got error %v, want %v", err, leveldb.ErrNotEmpty)
+		}
+	})
+
+	t.Run("corrupted", func(t *testing.T) {
+		corrupted := bytes.Replace(backup, []byte("balance_2"), []byte("balance_3"), 1)
+		dir := filepath.Join(t.TempDir(), "statestore")
+		if _, err := leveldb.Restore(dir, bytes.NewReader(corrupted), logger); !errors.Is(err, leveldb.ErrInvalidBackup) {
+			t.Fatalf("got error %v, want %v", err, leveldb.ErrInvalidBackup)
+		}
+	})
+}
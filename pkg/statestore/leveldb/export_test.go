@@ -1,6 +1,10 @@
 package leveldb
 
-var DbSchemaCurrent = dbSchemaCurrent
+var (
+	DbSchemaCurrent = dbSchemaCurrent
+	DbSchemaKey     = dbSchemaKey
+	DbSchemaNoStamp = dbSchemaNoStamp
+)
 
 func (s *store) GetSchemaName() (string, error) {
 	return s.getSchemaName()
@@ -0,0 +1,426 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package steward
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/ethersphere/bee/pkg/topology"
+)
+
+const (
+	// DefaultConcurrency is the number of parallel push operations of
+	// a reupload job if not specified otherwise.
+	DefaultConcurrency = parallelPush
+	// MaxConcurrency is the maximal number of parallel push operations
+	// of a reupload job.
+	MaxConcurrency = 100
+
+	// how many times the push of a chunk is attempted
+	maxPushAttempts = 5
+
+	jobKeyPrefix    = "steward_job_"
+	pushedKeyPrefix = "steward_pushed_"
+)
+
+// retryBackoff is the time waited before the first retry of a failed push,
+// it is doubled for every subsequent retry.
+var retryBackoff = time.Second
+
+var (
+	// ErrJobNotFound is returned when there is no reupload job for the
+	// given root hash.
+	ErrJobNotFound = errors.New("reupload job not found")
+	// ErrJobRunning is returned when a reupload job for the given root hash
+	// is already running.
+	ErrJobRunning = errors.New("reupload job already running")
+	// ErrJobNotRunning is returned when cancelling a reupload job that is
+	// not running.
+	ErrJobNotRunning = errors.New("reupload job not running")
+	// ErrInvalidConcurrency is returned when the concurrency of a reupload
+	// job is out of range.
+	ErrInvalidConcurrency = errors.New("invalid concurrency")
+)
+
+// JobState is the state of a reupload job.
+type JobState string
+
+const (
+	JobRunning   JobState = "running"
+	JobDone      JobState = "done"
+	JobFailed    JobState = "failed"
+	JobCancelled JobState = "cancelled"
+)
+
+// ReuploadStatus describes the progress of a reupload job.
+type ReuploadStatus struct {
+	Root        swarm.Address
+	State       JobState
+	Concurrency int
+	// Tag is the uid of the tag reporting the per chunk progress
+	// of the latest run of the job.
+	Tag uint32
+	// Pushed is the number of chunks pushed, including the ones pushed
+	// by the previous runs of the job.
+	Pushed uint64
+	// Failed is the number of chunks that could not be pushed.
+	Failed uint64
+	Error  string
+}
+
+// job is a running reupload job.
+type job struct {
+	mu        sync.Mutex
+	status    ReuploadStatus
+	tag       *tags.Tag
+	cancel    context.CancelFunc
+	cancelled bool
+	done      chan struct{}
+}
+
+func (j *job) snapshot() ReuploadStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status
+}
+
+func (j *job) running() bool {
+	select {
+	case <-j.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (j *job) inc(pushed, failed uint64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.status.Pushed += pushed
+	j.status.Failed += failed
+}
+
+// StartReupload starts a job in the background pushing all chunks below the
+// given root hash to the network. The chunks pushed by a previous run of
+// the job that has not finished successfully are skipped.
+func (s *steward) StartReupload(root swarm.Address, concurrency int) (ReuploadStatus, error) {
+	if concurrency < 1 || concurrency > MaxConcurrency {
+		return ReuploadStatus{}, ErrInvalidConcurrency
+	}
+
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+
+	if j, ok := s.jobs[root.ByteString()]; ok && j.running() {
+		return ReuploadStatus{}, ErrJobRunning
+	}
+
+	return s.startJob(ReuploadStatus{
+		Root:        root,
+		State:       JobRunning,
+		Concurrency: concurrency,
+	})
+}
+
+// ReuploadStatus returns the status of the reupload job for the given
+// root hash.
+func (s *steward) ReuploadStatus(root swarm.Address) (ReuploadStatus, error) {
+	s.jobsMu.Lock()
+	j, ok := s.jobs[root.ByteString()]
+	s.jobsMu.Unlock()
+	if ok {
+		return j.snapshot(), nil
+	}
+
+	var status ReuploadStatus
+	err := s.stateStore.Get(jobKey(root), &status)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ReuploadStatus{}, ErrJobNotFound
+		}
+		return ReuploadStatus{}, err
+	}
+	return status, nil
+}
+
+// CancelReupload stops the running reupload job for the given root hash.
+// The progress is kept so that the job is resumed when started again.
+func (s *steward) CancelReupload(root swarm.Address) error {
+	s.jobsMu.Lock()
+	j, ok := s.jobs[root.ByteString()]
+	if !ok {
+		s.jobsMu.Unlock()
+		if _, err := s.ReuploadStatus(root); err != nil {
+			return err
+		}
+		return ErrJobNotRunning
+	}
+	if !j.running() {
+		s.jobsMu.Unlock()
+		return ErrJobNotRunning
+	}
+	j.mu.Lock()
+	j.cancelled = true
+	j.mu.Unlock()
+	j.cancel()
+	s.jobsMu.Unlock()
+
+	<-j.done
+	return nil
+}
+
+// Close stops all running reupload jobs. The jobs are resumed
+// when the steward is constructed again.
+func (s *steward) Close() error {
+	s.cancel()
+
+	s.jobsMu.Lock()
+	var dones []chan struct{}
+	for _, j := range s.jobs {
+		dones = append(dones, j.done)
+	}
+	s.jobsMu.Unlock()
+
+	for _, done := range dones {
+		<-done
+	}
+	return nil
+}
+
+// resumeJobs starts all jobs that were running when the steward
+// was closed.
+func (s *steward) resumeJobs() error {
+	var resume []ReuploadStatus
+	err := s.stateStore.Iterate(jobKeyPrefix, func(_, value []byte) (bool, error) {
+		var status ReuploadStatus
+		if err := json.Unmarshal(value, &status); err != nil {
+			return true, err
+		}
+		if status.State == JobRunning {
+			resume = append(resume, status)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+	for _, status := range resume {
+		if status.Concurrency < 1 || status.Concurrency > MaxConcurrency {
+			status.Concurrency = DefaultConcurrency
+		}
+		if _, err := s.startJob(status); err != nil {
+			return fmt.Errorf("resume reupload of %s: %w", status.Root, err)
+		}
+	}
+	return nil
+}
+
+// startJob creates a new tag for the job, persists the job and runs it in
+// the background. It must be called with the jobsMu lock held.
+func (s *steward) startJob(status ReuploadStatus) (ReuploadStatus, error) {
+	tag, err := s.tags.Create(0)
+	if err != nil {
+		return ReuploadStatus{}, fmt.Errorf("create tag: %w", err)
+	}
+	status.Tag = tag.Uid
+	status.Pushed = 0
+	status.Failed = 0
+	status.Error = ""
+
+	if err := s.stateStore.Put(jobKey(status.Root), status); err != nil {
+		return ReuploadStatus{}, err
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	j := &job{
+		status: status,
+		tag:    tag,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	s.jobs[status.Root.ByteString()] = j
+
+	go func() {
+		defer close(j.done)
+		defer cancel()
+		s.finishJob(j, s.runJob(ctx, j))
+	}()
+	return status, nil
+}
+
+// runJob traverses the root hash of the job and pushes the chunks that
+// have not been pushed yet with the concurrency of the job.
+func (s *steward) runJob(ctx context.Context, j *job) error {
+	root := j.status.Root
+
+	var pushed uint64
+	err := s.stateStore.Iterate(pushedKeyPrefix+root.String()+"_", func(_, _ []byte) (bool, error) {
+		pushed++
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+	j.inc(pushed, 0)
+
+	sem := make(chan struct{}, j.status.Concurrency)
+	var wg sync.WaitGroup
+	fn := func(addr swarm.Address) error {
+		_ = j.tag.Inc(tags.StateSplit)
+		_ = j.tag.Inc(tags.StateStored)
+
+		err := s.stateStore.Get(pushedKey(root, addr), &struct{}{})
+		if err == nil {
+			_ = j.tag.Inc(tags.StateSeen)
+			return nil
+		}
+		if !errors.Is(err, storage.ErrNotFound) {
+			return err
+		}
+
+		c, err := s.getter.Get(ctx, storage.ModeGetSync, addr)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := s.pushWithRetry(ctx, c); err != nil {
+				if ctx.Err() == nil {
+					s.logger.Debugf("steward: reupload %s: push chunk %s: %v", root, addr, err)
+					j.inc(0, 1)
+				}
+				return
+			}
+			_ = j.tag.Inc(tags.StateSent)
+			_ = j.tag.Inc(tags.StateSynced)
+			if err := s.stateStore.Put(pushedKey(root, addr), struct{}{}); err != nil {
+				s.logger.Debugf("steward: reupload %s: record pushed chunk %s: %v", root, addr, err)
+			}
+			j.inc(1, 0)
+		}()
+		return nil
+	}
+
+	err = s.traverser.Traverse(ctx, root, fn)
+	wg.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("traversal of %s failed: %w", root.String(), err)
+	}
+	if _, err := j.tag.DoneSplit(root); err != nil {
+		s.logger.Debugf("steward: reupload %s: done split: %v", root, err)
+	}
+
+	if failed := j.snapshot().Failed; failed > 0 {
+		return fmt.Errorf("push of %d chunks failed", failed)
+	}
+	return nil
+}
+
+// finishJob persists the final state of the job. The progress of
+// a successful job is removed so that it starts from scratch next time.
+func (s *steward) finishJob(j *job, err error) {
+	j.mu.Lock()
+	switch {
+	case j.cancelled:
+		j.status.State = JobCancelled
+	case err == nil:
+		j.status.State = JobDone
+	case s.ctx.Err() != nil:
+		// the steward is closing, leave the job running
+		// so that it is resumed on the next start
+	default:
+		j.status.State = JobFailed
+		j.status.Error = err.Error()
+	}
+	status := j.status
+	j.mu.Unlock()
+
+	root := status.Root
+	if err != nil && status.State != JobRunning {
+		s.logger.Debugf("steward: reupload %s: %v", root, err)
+		s.logger.Errorf("steward: reupload %s %s", root, status.State)
+	}
+
+	if status.State == JobDone {
+		if err := s.deletePushed(root); err != nil {
+			s.logger.Debugf("steward: reupload %s: delete progress: %v", root, err)
+		}
+	}
+	if err := s.stateStore.Put(jobKey(root), status); err != nil {
+		s.logger.Debugf("steward: reupload %s: save job: %v", root, err)
+		s.logger.Errorf("steward: reupload %s: save job", root)
+	}
+}
+
+// pushWithRetry pushes the chunk to the closest node retrying with
+// exponential backoff in case of failure.
+func (s *steward) pushWithRetry(ctx context.Context, c swarm.Chunk) (err error) {
+	backoff := retryBackoff
+	for i := 0; i < maxPushAttempts; i++ {
+		if i > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			backoff *= 2
+		}
+		_, err = s.push.PushChunkToClosest(ctx, c)
+		if err == nil || errors.Is(err, topology.ErrWantSelf) {
+			// swallow the error in case we are the closest node
+			return nil
+		}
+	}
+	return err
+}
+
+// deletePushed removes the records of the pushed chunks below the root hash.
+func (s *steward) deletePushed(root swarm.Address) error {
+	var keys []string
+	err := s.stateStore.Iterate(pushedKeyPrefix+root.String()+"_", func(key, _ []byte) (bool, error) {
+		keys = append(keys, string(key))
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := s.stateStore.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func jobKey(root swarm.Address) string {
+	return jobKeyPrefix + root.String()
+}
+
+func pushedKey(root, addr swarm.Address) string {
+	return pushedKeyPrefix + root.String() + "_" + addr.String()
+}
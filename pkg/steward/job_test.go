@@ -0,0 +1,317 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package steward_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/file/pipeline/builder"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/pushsync"
+	psmock "github.com/ethersphere/bee/pkg/pushsync/mock"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/steward"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/ethersphere/bee/pkg/traversal"
+)
+
+func TestReuploadJob(t *testing.T) {
+	defer func(d time.Duration) { *steward.RetryBackoff = d }(*steward.RetryBackoff)
+	*steward.RetryBackoff = time.Millisecond
+
+	store, addr, addrs := uploadData(t, 100)
+	logger := logging.New(ioutil.Discard, 0)
+	tagg := tags.NewTags(statestore.NewStateStore(), logger)
+
+	var (
+		mu       sync.Mutex
+		attempts = make(map[string]int)
+	)
+	// every chunk is pushed successfully on the second attempt
+	ps := psmock.New(func(_ context.Context, ch swarm.Chunk) (*pushsync.Receipt, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[ch.Address().String()]++
+		if attempts[ch.Address().String()] == 1 {
+			return nil, errors.New("push failed")
+		}
+		return nil, nil
+	})
+
+	s, err := steward.New(statestore.NewStateStore(), tagg, store, traversal.New(store), nil, ps, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	if _, err := s.StartReupload(addr, 0); !errors.Is(err, steward.ErrInvalidConcurrency) {
+		t.Fatalf("got error %v, want %v", err, steward.ErrInvalidConcurrency)
+	}
+
+	started, err := s.StartReupload(addr, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := waitReupload(t, s, addr)
+	if status.State != steward.JobDone {
+		t.Fatalf("got state %s, want %s: %s", status.State, steward.JobDone, status.Error)
+	}
+	if status.Concurrency != 3 {
+		t.Fatalf("got concurrency %d, want 3", status.Concurrency)
+	}
+	if status.Pushed != uint64(len(addrs)) || status.Failed != 0 {
+		t.Fatalf("got %d pushed and %d failed chunks, want %d pushed", status.Pushed, status.Failed, len(addrs))
+	}
+
+	mu.Lock()
+	for _, a := range addrs {
+		if attempts[a.String()] != 2 {
+			t.Fatalf("chunk %s pushed %d times, want 2", a, attempts[a.String()])
+		}
+	}
+	mu.Unlock()
+
+	tag, err := tagg.Get(started.Tag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tag.Done(tags.StateSynced) {
+		t.Fatalf("tag not synced: %d of %d", tag.Get(tags.StateSynced), tag.TotalCounter())
+	}
+}
+
+func TestReuploadJob_Resume(t *testing.T) {
+	defer func(d time.Duration) { *steward.RetryBackoff = d }(*steward.RetryBackoff)
+	*steward.RetryBackoff = time.Millisecond
+
+	store, addr, addrs := uploadData(t, 100)
+	stateStore := statestore.NewStateStore()
+	logger := logging.New(ioutil.Discard, 0)
+	tagg := tags.NewTags(statestore.NewStateStore(), logger)
+	lost := map[string]bool{addrs[0].String(): true, addrs[1].String(): true}
+
+	var (
+		mu     sync.Mutex
+		pushed = make(map[string]int)
+	)
+	push := func(fail bool) pushsync.PushSyncer {
+		return psmock.New(func(_ context.Context, ch swarm.Chunk) (*pushsync.Receipt, error) {
+			if fail && lost[ch.Address().String()] {
+				return nil, errors.New("push failed")
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			pushed[ch.Address().String()]++
+			return nil, nil
+		})
+	}
+
+	s, err := steward.New(stateStore, tagg, store, traversal.New(store), nil, push(true), logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.StartReupload(addr, steward.DefaultConcurrency); err != nil {
+		t.Fatal(err)
+	}
+	status := waitReupload(t, s, addr)
+	if status.State != steward.JobFailed {
+		t.Fatalf("got state %s, want %s", status.State, steward.JobFailed)
+	}
+	if status.Failed != uint64(len(lost)) || status.Pushed != uint64(len(addrs)-len(lost)) {
+		t.Fatalf("got %d pushed and %d failed chunks, want %d and %d", status.Pushed, status.Failed, len(addrs)-len(lost), len(lost))
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the status of the job is kept in the state store
+	s, err = steward.New(stateStore, tagg, store, traversal.New(store), nil, push(false), logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	status, err = s.ReuploadStatus(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != steward.JobFailed {
+		t.Fatalf("got state %s, want %s", status.State, steward.JobFailed)
+	}
+
+	// only the chunks that failed are pushed again
+	if _, err := s.StartReupload(addr, steward.DefaultConcurrency); err != nil {
+		t.Fatal(err)
+	}
+	status = waitReupload(t, s, addr)
+	if status.State != steward.JobDone {
+		t.Fatalf("got state %s, want %s: %s", status.State, steward.JobDone, status.Error)
+	}
+	if status.Pushed != uint64(len(addrs)) || status.Failed != 0 {
+		t.Fatalf("got %d pushed and %d failed chunks, want %d pushed", status.Pushed, status.Failed, len(addrs))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, a := range addrs {
+		if pushed[a.String()] != 1 {
+			t.Fatalf("chunk %s pushed %d times, want once", a, pushed[a.String()])
+		}
+	}
+}
+
+func TestReuploadJob_Restart(t *testing.T) {
+	store, addr, addrs := uploadData(t, 10)
+	stateStore := statestore.NewStateStore()
+	logger := logging.New(ioutil.Discard, 0)
+	tagg := tags.NewTags(statestore.NewStateStore(), logger)
+
+	blocked := make(chan struct{})
+	ps := psmock.New(func(ctx context.Context, _ swarm.Chunk) (*pushsync.Receipt, error) {
+		select {
+		case blocked <- struct{}{}:
+		default:
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	s, err := steward.New(stateStore, tagg, store, traversal.New(store), nil, ps, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.StartReupload(addr, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.StartReupload(addr, 1); !errors.Is(err, steward.ErrJobRunning) {
+		t.Fatalf("got error %v, want %v", err, steward.ErrJobRunning)
+	}
+	<-blocked
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the job is resumed when the steward is constructed again
+	var (
+		mu     sync.Mutex
+		pushed int
+	)
+	ps = psmock.New(func(_ context.Context, _ swarm.Chunk) (*pushsync.Receipt, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		pushed++
+		return nil, nil
+	})
+	s, err = steward.New(stateStore, tagg, store, traversal.New(store), nil, ps, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	status := waitReupload(t, s, addr)
+	if status.State != steward.JobDone {
+		t.Fatalf("got state %s, want %s: %s", status.State, steward.JobDone, status.Error)
+	}
+	if status.Concurrency != 1 {
+		t.Fatalf("got concurrency %d, want 1", status.Concurrency)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if pushed != len(addrs) {
+		t.Fatalf("got %d pushed chunks, want %d", pushed, len(addrs))
+	}
+}
+
+func TestReuploadJob_Cancel(t *testing.T) {
+	store, addr, _ := uploadData(t, 10)
+	logger := logging.New(ioutil.Discard, 0)
+	tagg := tags.NewTags(statestore.NewStateStore(), logger)
+
+	blocked := make(chan struct{})
+	ps := psmock.New(func(ctx context.Context, _ swarm.Chunk) (*pushsync.Receipt, error) {
+		select {
+		case blocked <- struct{}{}:
+		default:
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	s, err := steward.New(statestore.NewStateStore(), tagg, store, traversal.New(store), nil, ps, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	if err := s.CancelReupload(addr); !errors.Is(err, steward.ErrJobNotFound) {
+		t.Fatalf("got error %v, want %v", err, steward.ErrJobNotFound)
+	}
+	if _, err := s.ReuploadStatus(addr); !errors.Is(err, steward.ErrJobNotFound) {
+		t.Fatalf("got error %v, want %v", err, steward.ErrJobNotFound)
+	}
+
+	if _, err := s.StartReupload(addr, 1); err != nil {
+		t.Fatal(err)
+	}
+	<-blocked
+	if err := s.CancelReupload(addr); err != nil {
+		t.Fatal(err)
+	}
+	status, err := s.ReuploadStatus(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != steward.JobCancelled {
+		t.Fatalf("got state %s, want %s", status.State, steward.JobCancelled)
+	}
+	if err := s.CancelReupload(addr); !errors.Is(err, steward.ErrJobNotRunning) {
+		t.Fatalf("got error %v, want %v", err, steward.ErrJobNotRunning)
+	}
+}
+
+// uploadData stores random data of the given number of chunks and returns
+// the root hash together with the addresses of all stored chunks.
+func uploadData(t *testing.T, chunks int) (storage.Storer, swarm.Address, []swarm.Address) {
+	t.Helper()
+
+	ctx := context.Background()
+	data := make([]byte, chunks*4096)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	store := mock.NewStorer()
+	l := &loggingStore{Storer: store}
+	pipe := builder.NewPipelineBuilder(ctx, l, storage.ModePutUpload, false)
+	addr, err := builder.FeedPipeline(ctx, pipe, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store, addr, l.addrs
+}
+
+// waitReupload waits for the reupload job of the root hash to finish
+// and returns its status.
+func waitReupload(t *testing.T, s steward.Interface, root swarm.Address) steward.ReuploadStatus {
+	t.Helper()
+
+	for i := 0; i < 500; i++ {
+		status, err := s.ReuploadStatus(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.State != steward.JobRunning {
+			return status
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("timed out waiting for reupload")
+	return steward.ReuploadStatus{}
+}
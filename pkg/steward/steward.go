@@ -7,20 +7,35 @@
 package steward
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"sort"
+	"sync"
 
+	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/pushsync"
+	"github.com/ethersphere/bee/pkg/retrieval"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
 	"github.com/ethersphere/bee/pkg/topology"
 	"github.com/ethersphere/bee/pkg/traversal"
 	"golang.org/x/sync/errgroup"
 )
 
-// how many parallel push operations
-const parallelPush = 5
+const (
+	// how many parallel push operations
+	parallelPush = 5
+	// how many parallel retrieve operations
+	parallelRetrieve = 5
+)
+
+// errReadOnly is returned when chunks are stored through the getter used to
+// check the retrievability of content.
+var errReadOnly = errors.New("read only")
 
 type Reuploader interface {
 	// Reupload root hash and all of its underlying
@@ -28,14 +43,60 @@ type Reuploader interface {
 	Reupload(context.Context, swarm.Address) error
 }
 
+type Interface interface {
+	Reuploader
+	// IsRetrievable checks whether the root hash and all of its
+	// underlying associated chunks are retrievable from the network
+	// and returns the addresses of the chunks that are not. The error
+	// wraps traversal.ErrIncomplete together with the missing chunks
+	// if the chunks below them could not be checked.
+	IsRetrievable(context.Context, swarm.Address) ([]swarm.Address, error)
+	// StartReupload starts a resumable job reuploading the root hash
+	// and all of its underlying associated chunks in the background.
+	StartReupload(root swarm.Address, concurrency int) (ReuploadStatus, error)
+	// ReuploadStatus returns the progress of the reupload job.
+	ReuploadStatus(root swarm.Address) (ReuploadStatus, error)
+	// CancelReupload stops the running reupload job.
+	CancelReupload(root swarm.Address) error
+	io.Closer
+}
+
 type steward struct {
-	getter    storage.Getter
-	push      pushsync.PushSyncer
-	traverser traversal.Traverser
+	getter     storage.Getter
+	push       pushsync.PushSyncer
+	traverser  traversal.Traverser
+	retrieval  retrieval.Interface
+	stateStore storage.StateStorer
+	tags       *tags.Tags
+	logger     logging.Logger
+
+	jobsMu sync.Mutex
+	jobs   map[string]*job
+	ctx    context.Context    // cancelled on Close
+	cancel context.CancelFunc // cancels ctx
 }
 
-func New(getter storage.Getter, t traversal.Traverser, p pushsync.PushSyncer) Reuploader {
-	return &steward{getter: getter, push: p, traverser: t}
+// New constructs a steward and resumes the reupload jobs that were
+// running when it was closed last time.
+func New(stateStore storage.StateStorer, tagg *tags.Tags, getter storage.Getter, t traversal.Traverser, r retrieval.Interface, p pushsync.PushSyncer, logger logging.Logger) (Interface, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &steward{
+		getter:     getter,
+		push:       p,
+		traverser:  t,
+		retrieval:  r,
+		stateStore: stateStore,
+		tags:       tagg,
+		logger:     logger,
+		jobs:       make(map[string]*job),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+	if err := s.resumeJobs(); err != nil {
+		_ = s.Close()
+		return nil, fmt.Errorf("resume reupload jobs: %w", err)
+	}
+	return s, nil
 }
 
 // Reupload content with the given root hash to the network.
@@ -76,3 +137,106 @@ func (s *steward) Reupload(ctx context.Context, root swarm.Address) error {
 	}
 	return nil
 }
+
+// IsRetrievable traverses the content with the given root hash retrieving
+// all the chunks from the network, bypassing the local store. Traversal
+// continues past missing chunks, but the chunks below a missing
+// intermediate chunk or manifest node can not be discovered, in which case
+// the missing chunks found are returned with an error wrapping
+// traversal.ErrIncomplete.
+func (s *steward) IsRetrievable(ctx context.Context, root swarm.Address) ([]swarm.Address, error) {
+	ng := &netGetter{
+		retrieval: s.retrieval,
+		retrieved: make(map[string]struct{}),
+		missing:   make(map[string]swarm.Address),
+	}
+
+	sem := make(chan struct{}, parallelRetrieve)
+	var wg sync.WaitGroup
+	fn := func(addr swarm.Address) error {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			ng.retrieve(ctx, addr)
+		}()
+		return nil
+	}
+
+	err := traversal.NewTolerant(ng).Traverse(ctx, root, fn)
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	switch {
+	case errors.Is(err, traversal.ErrIncomplete):
+		return ng.missingAddresses(), err
+	case err != nil:
+		return nil, fmt.Errorf("traversal of %s failed: %w", root.String(), err)
+	}
+	return ng.missingAddresses(), nil
+}
+
+// netGetter retrieves chunks from the network and keeps track of the
+// chunks that could not be retrieved.
+type netGetter struct {
+	retrieval retrieval.Interface
+	mu        sync.Mutex
+	retrieved map[string]struct{}
+	missing   map[string]swarm.Address
+}
+
+// Get implements the storage.Getter interface.
+func (g *netGetter) Get(ctx context.Context, _ storage.ModeGet, addr swarm.Address) (swarm.Chunk, error) {
+	ch, err := g.retrieval.RetrieveChunk(ctx, addr, true)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err != nil {
+		if ctx.Err() == nil {
+			g.missing[addr.ByteString()] = addr
+		}
+		return nil, err
+	}
+	g.retrieved[addr.ByteString()] = struct{}{}
+	return ch, nil
+}
+
+// Put implements the storage.Putter interface. Nothing is stored as the
+// content is only read.
+func (g *netGetter) Put(_ context.Context, _ storage.ModePut, _ ...swarm.Chunk) ([]bool, error) {
+	return nil, errReadOnly
+}
+
+// retrieve retrieves the chunk from the network unless it has already been
+// retrieved or found missing.
+func (g *netGetter) retrieve(ctx context.Context, addr swarm.Address) {
+	g.mu.Lock()
+	_, retrieved := g.retrieved[addr.ByteString()]
+	_, missing := g.missing[addr.ByteString()]
+	g.mu.Unlock()
+	if retrieved || missing {
+		return
+	}
+	_, _ = g.Get(ctx, storage.ModeGetRequest, addr)
+}
+
+// missingAddresses returns the addresses of the chunks that could not be
+// retrieved in a stable order.
+func (g *netGetter) missingAddresses() []swarm.Address {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	missing := make([]swarm.Address, 0, len(g.missing))
+	for _, addr := range g.missing {
+		missing = append(missing, addr)
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		return bytes.Compare(missing[i].Bytes(), missing[j].Bytes()) < 0
+	})
+	return missing
+}
@@ -8,16 +8,22 @@ import (
 	"bytes"
 	"context"
 	"crypto/rand"
+	"errors"
+	"io/ioutil"
 	"sync"
 	"testing"
 
 	"github.com/ethersphere/bee/pkg/file/pipeline/builder"
+	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/pushsync"
 	psmock "github.com/ethersphere/bee/pkg/pushsync/mock"
+	"github.com/ethersphere/bee/pkg/retrieval"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
 	"github.com/ethersphere/bee/pkg/steward"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/storage/mock"
 	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
 	"github.com/ethersphere/bee/pkg/topology"
 	"github.com/ethersphere/bee/pkg/traversal"
 )
@@ -38,7 +44,7 @@ func TestSteward(t *testing.T) {
 			return nil, nil
 		}
 		ps = psmock.New(fn)
-		s  = steward.New(store, traverser, ps)
+		s  = newSteward(t, store, traverser, nil, ps)
 	)
 	n, err := rand.Read(data)
 	if n != cap(data) {
@@ -81,7 +87,7 @@ func TestSteward_ErrWantSelf(t *testing.T) {
 			return nil, topology.ErrWantSelf
 		}
 		ps = psmock.New(fn)
-		s  = steward.New(store, traverser, ps)
+		s  = newSteward(t, store, traverser, nil, ps)
 	)
 	n, err := rand.Read(data)
 	if n != cap(data) {
@@ -115,3 +121,117 @@ func (l *loggingStore) Put(ctx context.Context, mode storage.ModePut, chs ...swa
 	}
 	return l.Storer.Put(ctx, mode, chs...)
 }
+
+func TestSteward_IsRetrievable(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		chunks    = 300
+		data      = make([]byte, chunks*4096)
+		store     = mock.NewStorer()
+		traverser = traversal.New(store)
+	)
+	n, err := rand.Read(data)
+	if n != cap(data) {
+		t.Fatal("short read")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := &loggingStore{Storer: store}
+	pipe := builder.NewPipelineBuilder(ctx, l, storage.ModePutUpload, false)
+	addr, err := builder.FeedPipeline(ctx, pipe, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the retriever serves the chunks from the store, except the lost ones
+	newRetriever := func(lost ...swarm.Address) retrieval.Interface {
+		return retrievalFunc(func(ctx context.Context, addr swarm.Address, _ bool) (swarm.Chunk, error) {
+			for _, a := range lost {
+				if a.Equal(addr) {
+					return nil, storage.ErrNotFound
+				}
+			}
+			return store.Get(ctx, storage.ModeGetRequest, addr)
+		})
+	}
+
+	t.Run("retrievable", func(t *testing.T) {
+		s := newSteward(t, store, traverser, newRetriever(), nil)
+		missing, err := s.IsRetrievable(ctx, addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(missing) != 0 {
+			t.Fatalf("got %d missing chunks, want none", len(missing))
+		}
+	})
+
+	t.Run("missing data chunks", func(t *testing.T) {
+		// the first chunks stored by the pipeline are data chunks, the
+		// very first one is kept to tell whether the root is a manifest
+		lost := []swarm.Address{l.addrs[1], l.addrs[2]}
+		s := newSteward(t, store, traverser, newRetriever(lost...), nil)
+		missing, err := s.IsRetrievable(ctx, addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(missing) != len(lost) {
+			t.Fatalf("got %d missing chunks, want %d", len(missing), len(lost))
+		}
+		for _, a := range lost {
+			found := false
+			for _, m := range missing {
+				if m.Equal(a) {
+					found = true
+				}
+			}
+			if !found {
+				t.Fatalf("chunk %s not reported missing", a)
+			}
+		}
+	})
+
+	t.Run("missing intermediate chunk", func(t *testing.T) {
+		// the first intermediate chunk is stored after its data chunks
+		lost := l.addrs[swarm.Branches]
+		s := newSteward(t, store, traverser, newRetriever(lost), nil)
+		missing, err := s.IsRetrievable(ctx, addr)
+		if !errors.Is(err, traversal.ErrIncomplete) {
+			t.Fatalf("got error %v, want %v", err, traversal.ErrIncomplete)
+		}
+		if len(missing) != 1 || !missing[0].Equal(lost) {
+			t.Fatalf("got missing chunks %v, want %v", missing, lost)
+		}
+	})
+
+	t.Run("missing root chunk", func(t *testing.T) {
+		s := newSteward(t, store, traverser, newRetriever(addr), nil)
+		missing, err := s.IsRetrievable(ctx, addr)
+		if !errors.Is(err, traversal.ErrIncomplete) {
+			t.Fatalf("got error %v, want %v", err, traversal.ErrIncomplete)
+		}
+		if len(missing) != 1 || !missing[0].Equal(addr) {
+			t.Fatalf("got missing chunks %v, want %v", missing, addr)
+		}
+	})
+}
+
+func newSteward(t *testing.T, store storage.Getter, traverser traversal.Traverser, r retrieval.Interface, ps pushsync.PushSyncer) steward.Interface {
+	t.Helper()
+
+	logger := logging.New(ioutil.Discard, 0)
+	s, err := steward.New(statestore.NewStateStore(), tags.NewTags(statestore.NewStateStore(), logger), store, traverser, r, ps, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+type retrievalFunc func(ctx context.Context, addr swarm.Address, origin bool) (swarm.Chunk, error)
+
+func (f retrievalFunc) RetrieveChunk(ctx context.Context, addr swarm.Address, origin bool) (swarm.Chunk, error) {
+	return f(ctx, addr, origin)
+}
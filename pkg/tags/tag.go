@@ -69,6 +69,12 @@ type Tag struct {
 	spanOnce   sync.Once           // make sure we close root span only once
 	stateStore storage.StateStorer // to persist the tag
 	logger     logging.Logger      // logger instance for logging
+
+	subsMu sync.Mutex                 // protects subs
+	subs   map[chan struct{}]struct{} // notified when a counter changes
+
+	syncedHandler func(*Tag) // called once all the chunks are synced
+	syncedOnce    sync.Once  // make sure the synced handler is called only once
 }
 
 // NewTag creates a new tag, and returns it
@@ -117,6 +123,7 @@ func (t *Tag) IncN(state State, n int64) error {
 		v = &t.Synced
 	}
 	atomic.AddInt64(v, n)
+	t.notify()
 
 	// check if syncing is over and persist the tag
 	if state == StateSynced {
@@ -125,6 +132,7 @@ func (t *Tag) IncN(state State, n int64) error {
 		synced := atomic.LoadInt64(&t.Synced)
 		totalUnique := total - seen
 		if synced >= totalUnique {
+			t.handleSynced()
 			return t.saveTag()
 		}
 	}
@@ -196,6 +204,8 @@ func (t *Tag) DoneSplit(address swarm.Address) (int64, error) {
 	if !address.Equal(swarm.ZeroAddress) {
 		t.Address = address
 	}
+	t.notify()
+	t.handleSynced()
 
 	// persist the tag
 	err := t.saveTag()
@@ -205,6 +215,49 @@ func (t *Tag) DoneSplit(address swarm.Address) (int64, error) {
 	return total, nil
 }
 
+// Subscribe returns a channel notified whenever a counter of the tag changes
+// and a function to cancel the subscription. Notifications are coalesced, so
+// the counters should be read after receiving from the channel.
+func (t *Tag) Subscribe() (<-chan struct{}, func()) {
+	c := make(chan struct{}, 1)
+
+	t.subsMu.Lock()
+	defer t.subsMu.Unlock()
+	if t.subs == nil {
+		t.subs = make(map[chan struct{}]struct{})
+	}
+	t.subs[c] = struct{}{}
+
+	return c, func() {
+		t.subsMu.Lock()
+		defer t.subsMu.Unlock()
+		delete(t.subs, c)
+	}
+}
+
+// notify signals the subscribers without blocking on the ones
+// that have not yet received the previous notification.
+func (t *Tag) notify() {
+	t.subsMu.Lock()
+	defer t.subsMu.Unlock()
+	for c := range t.subs {
+		select {
+		case c <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// handleSynced calls the synced handler if all the chunks are synced.
+func (t *Tag) handleSynced() {
+	if t.syncedHandler == nil || !t.Done(StateSynced) {
+		return
+	}
+	t.syncedOnce.Do(func() {
+		t.syncedHandler(t)
+	})
+}
+
 // Status returns the value of state and the total count
 func (t *Tag) Status(state State) (int64, int64, error) {
 	count, seen, total := t.Get(state), atomic.LoadInt64(&t.Seen), atomic.LoadInt64(&t.Total)
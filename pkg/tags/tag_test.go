@@ -137,6 +137,55 @@ func TestTagETA(t *testing.T) {
 	}
 }
 
+// TestTagSubscribe tests that subscribers are notified on counter changes
+func TestTagSubscribe(t *testing.T) {
+	tg := &Tag{Total: 10}
+
+	c, cancel := tg.Subscribe()
+
+	select {
+	case <-c:
+		t.Fatal("notified before any change")
+	default:
+	}
+
+	// notifications are coalesced
+	for i := 0; i < 3; i++ {
+		if err := tg.Inc(StateStored); err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case <-c:
+	default:
+		t.Fatal("not notified")
+	}
+	select {
+	case <-c:
+		t.Fatal("notified more than once")
+	default:
+	}
+
+	if _, err := tg.DoneSplit(swarm.ZeroAddress); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-c:
+	default:
+		t.Fatal("not notified on done split")
+	}
+
+	cancel()
+	if err := tg.Inc(StateSynced); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-c:
+		t.Fatal("notified after cancel")
+	default:
+	}
+}
+
 // TestTagConcurrentIncrements tests Inc calls concurrently
 func TestTagConcurrentIncrements(t *testing.T) {
 	mockStatestore := statestore.NewStateStore()
@@ -46,9 +46,10 @@ var (
 
 // Tags hold tag information indexed by a unique random uint32
 type Tags struct {
-	tags       *sync.Map
-	stateStore storage.StateStorer
-	logger     logging.Logger
+	tags          *sync.Map
+	stateStore    storage.StateStorer
+	logger        logging.Logger
+	syncedHandler func(*Tag)
 }
 
 // NewTags creates a tags object
@@ -64,6 +65,7 @@ func NewTags(stateStore storage.StateStorer, logger logging.Logger) *Tags {
 // it returns an error if the tag with this UID already exists
 func (ts *Tags) Create(total int64) (*Tag, error) {
 	t := NewTag(context.Background(), TagUidFunc(), total, nil, ts.stateStore, ts.logger)
+	t.syncedHandler = ts.syncedHandler
 
 	if _, loaded := ts.tags.LoadOrStore(t.Uid, t); loaded {
 		return nil, errExists
@@ -72,6 +74,12 @@ func (ts *Tags) Create(total int64) (*Tag, error) {
 	return t, nil
 }
 
+// SetSyncedHandler sets the function called once all the chunks of a tag
+// created afterwards are synced.
+func (ts *Tags) SetSyncedHandler(f func(*Tag)) {
+	ts.syncedHandler = f
+}
+
 // All returns all existing tags in Tags' sync.Map
 // Note that tags are returned in no particular order
 func (ts *Tags) All() (t []*Tag) {
@@ -229,3 +229,37 @@ func TestPersistence(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestSyncedHandler(t *testing.T) {
+	mockStatestore := statestore.NewStateStore()
+	logger := logging.New(ioutil.Discard, 0)
+	ts := NewTags(mockStatestore, logger)
+
+	var synced []uint32
+	ts.SetSyncedHandler(func(tag *Tag) {
+		synced = append(synced, tag.Uid)
+	})
+
+	tag, err := ts.Create(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, state := range []State{StateStored, StateStored, StateSynced} {
+		if err := tag.Inc(state); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(synced) != 0 {
+		t.Fatalf("handler called before all chunks are synced")
+	}
+
+	// the handler is called only once
+	for i := 0; i < 2; i++ {
+		if err := tag.Inc(StateSynced); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(synced) != 1 || synced[0] != tag.Uid {
+		t.Fatalf("got synced tags %v, want [%d]", synced, tag.Uid)
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package traversal
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/ethersphere/bee/pkg/encryption/store"
+	"github.com/ethersphere/bee/pkg/file/joiner"
+	"github.com/ethersphere/bee/pkg/file/loadsave"
+	"github.com/ethersphere/bee/pkg/manifest"
+	"github.com/ethersphere/bee/pkg/manifest/mantaray"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// ErrIncomplete is returned by the tolerant traversal when parts of the
+// content could not be discovered as the chunks referencing them could
+// not be got.
+var ErrIncomplete = errors.New("traversal: incomplete")
+
+// NewTolerant constructs a Traverser which continues past the chunks it
+// fails to get. The chunk tree of the root is walked directly, and the
+// content referenced by the root is walked as well if the root turns out
+// to be a manifest. The address of every discovered chunk is passed to the
+// iterator function, including the ones that could not be got, and the
+// traversal returns an error wrapping ErrIncomplete if the chunks below
+// them could not be discovered.
+func NewTolerant(store PutGetter) Traverser {
+	return &tolerantService{store: store}
+}
+
+// tolerantService is the implementation of the tolerant Traverser.
+type tolerantService struct {
+	store PutGetter
+}
+
+// Traverse implements Traverser.Traverse method.
+func (s *tolerantService) Traverse(ctx context.Context, addr swarm.Address, iterFn swarm.AddressIterFunc) error {
+	w := &walker{
+		getter: store.New(s.store),
+		iterFn: iterFn,
+		walked: make(map[string]struct{}),
+	}
+
+	complete, err := w.walk(ctx, addr)
+	if err != nil {
+		return err
+	}
+	manifestCandidate, err := isManifestCandidate(ctx, s.store, addr)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// the root can not be read to tell whether it is a manifest
+		return fmt.Errorf("%w: %s: %v", ErrIncomplete, addr, err)
+	}
+	if !manifestCandidate {
+		if !complete {
+			return fmt.Errorf("%w: %s", ErrIncomplete, addr)
+		}
+		return nil
+	}
+
+	ls := loadsave.New(s.store, storage.ModePutRequest, false)
+	mf, err := manifest.NewDefaultManifestReference(addr, ls)
+	if err != nil {
+		if errors.Is(err, manifest.ErrInvalidManifestType) {
+			return nil
+		}
+		return fmt.Errorf("traversal: unable to create manifest reference for %q: %w", addr, err)
+	}
+	err = mf.IterateAddresses(ctx, func(ref swarm.Address) error {
+		ok, err := w.walk(ctx, ref)
+		if err != nil {
+			return err
+		}
+		complete = complete && ok
+		return nil
+	})
+	switch {
+	case errors.Is(err, mantaray.ErrTooShort), errors.Is(err, mantaray.ErrInvalidVersionHash):
+		return nil // not a manifest
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case err != nil:
+		// a node of the manifest could not be loaded
+		return fmt.Errorf("%w: %s: %v", ErrIncomplete, addr, err)
+	case !complete:
+		return fmt.Errorf("%w: %s", ErrIncomplete, addr)
+	}
+	return nil
+}
+
+// isManifestCandidate reads the header of the content with the reference
+// and reports whether it may be a manifest node, without reading the rest
+// of the content.
+func isManifestCandidate(ctx context.Context, getter storage.Getter, addr swarm.Address) (bool, error) {
+	j, _, err := joiner.New(ctx, getter, addr)
+	if err != nil {
+		return false, err
+	}
+	header := make([]byte, mantaray.NodeHeaderSize)
+	n, err := j.ReadAt(header, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	return mantaray.IsNodeHeader(header[:n]), nil
+}
+
+// walker walks the chunk trees of the references passing the addresses
+// of their chunks to the iterator function.
+type walker struct {
+	getter storage.Getter
+	iterFn swarm.AddressIterFunc
+	walked map[string]struct{}
+}
+
+// walk walks the chunk tree of the reference unless it has already been
+// walked. It reports whether all the chunks of the tree were discovered.
+func (w *walker) walk(ctx context.Context, ref swarm.Address) (bool, error) {
+	if _, ok := w.walked[ref.ByteString()]; ok {
+		return true, nil
+	}
+	w.walked[ref.ByteString()] = struct{}{}
+
+	if err := w.iterFn(ref); err != nil {
+		return false, err
+	}
+	ch, err := w.getter.Get(ctx, storage.ModeGetRequest, ref)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		return false, nil
+	}
+	if len(ch.Data()) < swarm.SpanSize {
+		return false, nil
+	}
+	span := int64(binary.LittleEndian.Uint64(ch.Data()[:swarm.SpanSize]))
+	return w.walkIntermediate(ctx, ch.Data()[swarm.SpanSize:], len(ref.Bytes()), span)
+}
+
+// walkIntermediate walks the subtrees of the chunk data with the given span
+// unless it is a data chunk.
+func (w *walker) walkIntermediate(ctx context.Context, data []byte, refLength int, span int64) (bool, error) {
+	if span <= int64(len(data)) {
+		return true, nil // data chunk
+	}
+	if len(data)%refLength != 0 {
+		return false, nil // the intermediate chunk is corrupt
+	}
+
+	complete := true
+	for cursor := 0; cursor < len(data); cursor += refLength {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		ref := swarm.NewAddress(data[cursor : cursor+refLength])
+		if err := w.iterFn(ref); err != nil {
+			return false, err
+		}
+		subtrieSpan := subtrieSection(data, cursor, refLength, span)
+		if subtrieSpan <= swarm.ChunkSize {
+			continue // the data chunk is not needed to discover others
+		}
+
+		ch, err := w.getter.Get(ctx, storage.ModeGetRequest, ref)
+		if err != nil {
+			if ctx.Err() != nil {
+				return false, ctx.Err()
+			}
+			complete = false
+			continue
+		}
+		if len(ch.Data()) < swarm.SpanSize {
+			complete = false
+			continue
+		}
+		span := int64(binary.LittleEndian.Uint64(ch.Data()[:swarm.SpanSize]))
+		ok, err := w.walkIntermediate(ctx, ch.Data()[swarm.SpanSize:], refLength, span)
+		if err != nil {
+			return false, err
+		}
+		complete = complete && ok
+	}
+	return complete, nil
+}
+
+// subtrieSection returns the span of the subtrie referenced at the index
+// of the intermediate chunk data. All the subtries but the last one are of
+// equal size, as the splitter fills the levels from the left.
+func subtrieSection(data []byte, startIdx, refLength int, subtrieSize int64) int64 {
+	var (
+		refs       = int64(len(data) / refLength)
+		branching  = int64(swarm.ChunkSize / refLength)
+		branchSize = int64(swarm.ChunkSize)
+	)
+	for subtrieSize-branchSize*(refs-1) > branchSize && branchSize <= math.MaxInt64/(branching*refs) {
+		branchSize *= branching
+	}
+	if startIdx == int(refs-1)*refLength {
+		return subtrieSize - (refs-1)*branchSize
+	}
+	return branchSize
+}
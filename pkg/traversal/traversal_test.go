@@ -7,6 +7,8 @@ package traversal_test
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
 	"math"
 	"path"
@@ -456,3 +458,132 @@ func TestTraversalManifest(t *testing.T) {
 		})
 	}
 }
+
+func TestTolerantTraversal(t *testing.T) {
+	ctx := context.Background()
+
+	// the chunks are stored by the pipeline in the order data chunks
+	// 0-127, intermediate chunk 0, data chunks 128-255, intermediate
+	// chunk 1, data chunks 256-299, intermediate chunk 2 and the root
+	data := make([]byte, 300*swarm.ChunkSize)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	store := &recordingStore{Storer: mock.NewStorer()}
+	pipe := builder.NewPipelineBuilder(ctx, store, storage.ModePutUpload, false)
+	root, err := builder.FeedPipeline(ctx, pipe, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := append([]swarm.Address{}, store.addrs...)
+
+	ls := loadsave.New(store, storage.ModePutRequest, false)
+	m, err := manifest.NewMantarayManifest(ls, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Add(ctx, "data.bin", manifest.NewEntry(root, nil)); err != nil {
+		t.Fatal(err)
+	}
+	manifestRoot, err := m.Store(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name           string
+		root           swarm.Address
+		lost           []swarm.Address
+		wantIncomplete bool
+		wantUnseen     []swarm.Address
+	}{
+		{
+			name: "intact",
+			root: root,
+		},
+		{
+			name: "missing data chunks",
+			root: root,
+			lost: []swarm.Address{all[1], all[130]},
+		},
+		{
+			// the header of the content tells whether it is a manifest
+			name:           "missing first data chunk",
+			root:           root,
+			lost:           []swarm.Address{all[0]},
+			wantIncomplete: true,
+		},
+		{
+			name:           "missing intermediate chunk",
+			root:           root,
+			lost:           []swarm.Address{all[128]},
+			wantIncomplete: true,
+			wantUnseen:     all[:128],
+		},
+		{
+			name: "manifest with missing data chunks",
+			root: manifestRoot,
+			lost: []swarm.Address{all[0], all[299]},
+		},
+		{
+			name:           "missing manifest root",
+			root:           manifestRoot,
+			lost:           []swarm.Address{manifestRoot},
+			wantIncomplete: true,
+			wantUnseen:     all,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			iter := newAddressIterator(false)
+			err := traversal.NewTolerant(&lossyStore{Storer: store.Storer, lost: tc.lost}).Traverse(ctx, tc.root, iter.Next)
+			if tc.wantIncomplete {
+				if !errors.Is(err, traversal.ErrIncomplete) {
+					t.Fatalf("got error %v, want %v", err, traversal.ErrIncomplete)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			unseen := make(map[string]bool)
+			for _, a := range tc.wantUnseen {
+				unseen[a.String()] = true
+			}
+			for _, a := range all {
+				if iter.seen[a.String()] == unseen[a.String()] {
+					t.Fatalf("chunk %s seen %t, want %t", a, iter.seen[a.String()], !unseen[a.String()])
+				}
+			}
+			if !iter.seen[tc.root.String()] {
+				t.Fatalf("root %s not seen", tc.root)
+			}
+		})
+	}
+}
+
+// recordingStore records the addresses of the stored chunks.
+type recordingStore struct {
+	storage.Storer
+	addrs []swarm.Address
+}
+
+func (s *recordingStore) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk) ([]bool, error) {
+	for _, ch := range chs {
+		s.addrs = append(s.addrs, ch.Address())
+	}
+	return s.Storer.Put(ctx, mode, chs...)
+}
+
+// lossyStore pretends to have lost some chunks.
+type lossyStore struct {
+	storage.Storer
+	lost []swarm.Address
+}
+
+func (s *lossyStore) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address) (swarm.Chunk, error) {
+	for _, a := range s.lost {
+		if a.Equal(addr) {
+			return nil, storage.ErrNotFound
+		}
+	}
+	return s.Storer.Get(ctx, mode, addr)
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+)
+
+type tagSyncedEvent struct {
+	Uid     uint32        `json:"uid"`
+	Address swarm.Address `json:"address"`
+	Total   int64         `json:"total"`
+}
+
+// TagSynced notifies that all the chunks of the tag are synced.
+// It is meant to be set as the synced handler of the tags.
+func (s *Service) TagSynced(t *tags.Tag) {
+	s.Notify(EventTagSynced, tagSyncedEvent{
+		Uid:     t.Uid,
+		Address: t.Address,
+		Total:   t.TotalCounter(),
+	})
+}
+
+type peerBlocklistedEvent struct {
+	Overlay swarm.Address `json:"overlay"`
+	// Duration is the blocklisting duration in seconds,
+	// zero if the peer is blocklisted indefinitely.
+	Duration int64 `json:"duration"`
+}
+
+// PeerBlocklisted notifies that the peer is blocklisted for the duration.
+// It is meant to be set as the blocklist handler of the p2p service.
+func (s *Service) PeerBlocklisted(overlay swarm.Address, duration time.Duration) {
+	s.Notify(EventPeerBlocklisted, peerBlocklistedEvent{
+		Overlay:  overlay,
+		Duration: int64(duration / time.Second),
+	})
+}
+
+type radiusChangedEvent struct {
+	Radius uint8 `json:"radius"`
+}
+
+type radiusSetter struct {
+	postage.RadiusSetter
+	s *Service
+
+	mu     sync.Mutex
+	radius uint8
+	set    bool
+}
+
+// RadiusSetter wraps the radius setter notifying the changes of the
+// reserve radius. The batch store sets the radius whenever it updates
+// the reserve state, only the actual changes are notified.
+func (s *Service) RadiusSetter(r postage.RadiusSetter) postage.RadiusSetter {
+	return &radiusSetter{RadiusSetter: r, s: s}
+}
+
+func (r *radiusSetter) SetRadius(radius uint8) {
+	r.RadiusSetter.SetRadius(radius)
+
+	r.mu.Lock()
+	changed := r.set && r.radius != radius
+	r.radius, r.set = radius, true
+	r.mu.Unlock()
+
+	if changed {
+		r.s.Notify(EventReserveRadiusChange, radiusChangedEvent{Radius: radius})
+	}
+}
+
+type chequeReceivedEvent struct {
+	Chequebook       common.Address `json:"chequebook"`
+	CumulativePayout *bigint.BigInt `json:"cumulativePayout"`
+	Amount           *bigint.BigInt `json:"amount"`
+}
+
+type chequeStore struct {
+	chequebook.ChequeStore
+	s *Service
+}
+
+// ChequeStore wraps the cheque store notifying the received cheques.
+func (s *Service) ChequeStore(c chequebook.ChequeStore) chequebook.ChequeStore {
+	return &chequeStore{ChequeStore: c, s: s}
+}
+
+func (c *chequeStore) ReceiveCheque(ctx context.Context, cheque *chequebook.SignedCheque, exchangeRate, deduction *big.Int) (*big.Int, error) {
+	amount, err := c.ChequeStore.ReceiveCheque(ctx, cheque, exchangeRate, deduction)
+	if err != nil {
+		return nil, err
+	}
+	c.s.Notify(EventChequeReceived, chequeReceivedEvent{
+		Chequebook:       cheque.Chequebook,
+		CumulativePayout: bigint.Wrap(cheque.CumulativePayout),
+		Amount:           bigint.Wrap(amount),
+	})
+	return amount, nil
+}
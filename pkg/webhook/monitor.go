@@ -0,0 +1,143 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"encoding/hex"
+	"math/big"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/bigint"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+)
+
+// DefaultMonitorInterval is the default time between two checks of the
+// postage batches and the chequebook balance.
+const DefaultMonitorInterval = time.Minute
+
+// MonitorOptions configure the checks of the state that changes without
+// an event to hook into.
+type MonitorOptions struct {
+	Interval time.Duration
+
+	// Post holds the batches of the node checked for their expiry.
+	Post       postage.Service
+	BatchStore postage.Storer
+	BlockTime  time.Duration
+	// BatchTTLThreshold is the time to live of a batch below
+	// which it is notified as expiring.
+	BatchTTLThreshold time.Duration
+
+	// Chequebook is checked for its available balance if set.
+	Chequebook chequebook.Service
+	// ChequebookThreshold is the available balance below which the
+	// chequebook is notified, the balance is not checked if nil.
+	ChequebookThreshold *big.Int
+}
+
+type batchExpiringEvent struct {
+	BatchID     string `json:"batchID"`
+	Label       string `json:"label"`
+	BatchTTL    int64  `json:"batchTTL"`
+	ExpiryBlock uint64 `json:"expiryBlock"`
+}
+
+type chequebookBalanceEvent struct {
+	AvailableBalance *bigint.BigInt `json:"availableBalance"`
+	Threshold        *bigint.BigInt `json:"threshold"`
+}
+
+// StartMonitor periodically checks the postage batches of the node and the
+// chequebook balance until the service is closed. Every batch is notified
+// once when its time to live drops below the threshold, and the chequebook
+// once when its available balance drops below the threshold, again after
+// they have been topped up.
+func (s *Service) StartMonitor(o MonitorOptions) {
+	if len(s.endpoints) == 0 {
+		return
+	}
+	if o.Interval <= 0 {
+		o.Interval = DefaultMonitorInterval
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		var (
+			ticker     = time.NewTicker(o.Interval)
+			expiring   = make(map[string]struct{})
+			balanceLow bool
+		)
+		defer ticker.Stop()
+
+		for {
+			if o.Post != nil && o.BatchStore != nil {
+				s.checkBatches(o, expiring)
+			}
+			if o.Chequebook != nil && o.ChequebookThreshold != nil {
+				balanceLow = s.checkChequebook(o, balanceLow)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (s *Service) checkBatches(o MonitorOptions, expiring map[string]struct{}) {
+	cs := o.BatchStore.GetChainState()
+	threshold := int64(o.BatchTTLThreshold / time.Second)
+
+	for _, si := range o.Post.StampIssuers() {
+		id := hex.EncodeToString(si.ID())
+		batch, err := o.BatchStore.Get(si.ID())
+		if err != nil {
+			// the batch is expired or not yet known
+			continue
+		}
+		expiry, ok := postage.ExpiryBlock(cs, batch.Value)
+		if !ok {
+			continue
+		}
+		ttl := postage.TTL(expiry-cs.Block, o.BlockTime)
+		if ttl > threshold {
+			delete(expiring, id)
+			continue
+		}
+		if _, ok := expiring[id]; ok {
+			continue
+		}
+		expiring[id] = struct{}{}
+		s.Notify(EventBatchExpiring, batchExpiringEvent{
+			BatchID:     id,
+			Label:       si.Label(),
+			BatchTTL:    ttl,
+			ExpiryBlock: expiry,
+		})
+	}
+}
+
+// checkChequebook notifies if the available balance dropped below the
+// threshold and returns whether it is below.
+func (s *Service) checkChequebook(o MonitorOptions, wasLow bool) bool {
+	balance, err := o.Chequebook.AvailableBalance(s.ctx)
+	if err != nil {
+		s.logger.Debugf("webhook: chequebook available balance: %v", err)
+		return wasLow
+	}
+	low := balance.Cmp(o.ChequebookThreshold) < 0
+	if low && !wasLow {
+		s.Notify(EventChequebookBalance, chequebookBalanceEvent{
+			AvailableBalance: bigint.Wrap(balance),
+			Threshold:        bigint.Wrap(o.ChequebookThreshold),
+		})
+	}
+	return low
+}
@@ -0,0 +1,337 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package webhook delivers the events of the node to HTTP endpoints.
+// The events are kept in an outbox in the state store until they are
+// delivered, so that they survive restarts of the node.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// Event types.
+const (
+	EventTagSynced           = "tag.synced"
+	EventBatchExpiring       = "postage.batch.expiring"
+	EventChequebookBalance   = "chequebook.balance.low"
+	EventChequeReceived      = "chequebook.cheque.received"
+	EventPeerBlocklisted     = "peer.blocklisted"
+	EventReserveRadiusChange = "reserve.radius.changed"
+)
+
+const (
+	// EventTypeHeader is the header holding the type of the delivered event.
+	EventTypeHeader = "Swarm-Webhook-Event"
+	// SignatureHeader is the header holding the hex encoded signature of the
+	// request body made with the key of the node.
+	SignatureHeader = "Swarm-Webhook-Signature"
+
+	// DefaultMaxAttempts is the default number of delivery attempts.
+	DefaultMaxAttempts = 10
+	// DefaultRetryInterval is the default time waited before the first retry,
+	// it is doubled with every following attempt.
+	DefaultRetryInterval = 5 * time.Second
+
+	maxRetryInterval = time.Hour
+	deliveryTimeout  = 10 * time.Second
+
+	outboxKeyPrefix = "webhook_outbox_"
+	eventIDKey      = "webhook_event_id"
+)
+
+// Event is the JSON body posted to the endpoints.
+type Event struct {
+	ID      uint64        `json:"id"`
+	Type    string        `json:"type"`
+	Time    time.Time     `json:"time"`
+	Overlay swarm.Address `json:"overlay"`
+	Data    interface{}   `json:"data"`
+}
+
+// Options are the webhook delivery options.
+type Options struct {
+	Endpoints     []string
+	MaxAttempts   int
+	RetryInterval time.Duration
+}
+
+// Service persists the events in the outbox and posts them to the endpoints,
+// retrying the failed deliveries with an exponential backoff.
+type Service struct {
+	store     storage.StateStorer
+	signer    crypto.Signer
+	overlay   swarm.Address
+	logger    logging.Logger
+	endpoints []string
+	attempts  int
+	interval  time.Duration
+	client    *http.Client
+	now       func() time.Time
+
+	mu     sync.Mutex // protects lastID and the outbox writes
+	lastID uint64
+
+	wakeC  chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// delivery is the outbox entry of an event to be posted to an endpoint.
+type delivery struct {
+	Endpoint    string    `json:"endpoint"`
+	Type        string    `json:"type"`
+	Body        []byte    `json:"body"`
+	Signature   []byte    `json:"signature"`
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"nextAttempt"`
+}
+
+// New constructs a webhook Service and starts delivering the events
+// found in the outbox.
+func New(store storage.StateStorer, signer crypto.Signer, overlay swarm.Address, logger logging.Logger, o Options) (*Service, error) {
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = DefaultMaxAttempts
+	}
+	if o.RetryInterval <= 0 {
+		o.RetryInterval = DefaultRetryInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Service{
+		store:     store,
+		signer:    signer,
+		overlay:   overlay,
+		logger:    logger,
+		endpoints: o.Endpoints,
+		attempts:  o.MaxAttempts,
+		interval:  o.RetryInterval,
+		client:    &http.Client{Timeout: deliveryTimeout},
+		now:       time.Now,
+		wakeC:     make(chan struct{}, 1),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+
+	if err := store.Get(eventIDKey, &s.lastID); err != nil && err != storage.ErrNotFound {
+		cancel()
+		return nil, fmt.Errorf("load event id: %w", err)
+	}
+
+	s.wg.Add(1)
+	go s.run()
+
+	return s, nil
+}
+
+// Notify stores the event with the given type and data in the outbox for
+// every endpoint. Errors are logged, so that failing to notify does not
+// affect the operation reporting the event.
+func (s *Service) Notify(eventType string, data interface{}) {
+	if len(s.endpoints) == 0 {
+		return
+	}
+	if err := s.notify(eventType, data); err != nil {
+		s.logger.Debugf("webhook: notify %s: %v", eventType, err)
+		s.logger.Errorf("webhook: unable to notify %s", eventType)
+		return
+	}
+
+	select {
+	case s.wakeC <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Service) notify(eventType string, data interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.lastID + 1
+	body, err := json.Marshal(Event{
+		ID:      id,
+		Type:    eventType,
+		Time:    s.now().UTC(),
+		Overlay: s.overlay,
+		Data:    data,
+	})
+	if err != nil {
+		return err
+	}
+	signature, err := s.signer.Sign(body)
+	if err != nil {
+		return fmt.Errorf("sign: %w", err)
+	}
+
+	if err := s.store.Put(eventIDKey, id); err != nil {
+		return err
+	}
+	s.lastID = id
+
+	for i, endpoint := range s.endpoints {
+		err := s.store.Put(outboxKey(id, i), &delivery{
+			Endpoint:  endpoint,
+			Type:      eventType,
+			Body:      body,
+			Signature: signature,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) run() {
+	defer s.wg.Done()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-s.wakeC:
+		case <-timer.C:
+		case <-s.ctx.Done():
+			return
+		}
+
+		next, err := s.deliver()
+		if err != nil {
+			s.logger.Debugf("webhook: deliver: %v", err)
+			s.logger.Error("webhook: unable to deliver events")
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if !next.IsZero() {
+			timer.Reset(next.Sub(s.now()))
+		}
+	}
+}
+
+// deliver posts the due deliveries in the order of the events and returns
+// the time of the earliest pending retry, which is zero if there is none.
+func (s *Service) deliver() (next time.Time, err error) {
+	var keys []string
+	err = s.store.Iterate(outboxKeyPrefix, func(key, _ []byte) (bool, error) {
+		keys = append(keys, string(key))
+		return false, nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		var d delivery
+		if err := s.store.Get(key, &d); err != nil {
+			return time.Time{}, err
+		}
+		if s.now().Before(d.NextAttempt) {
+			if next.IsZero() || d.NextAttempt.Before(next) {
+				next = d.NextAttempt
+			}
+			continue
+		}
+
+		err := s.post(&d)
+		if err == nil {
+			if err := s.store.Delete(key); err != nil {
+				return time.Time{}, err
+			}
+			continue
+		}
+		if s.ctx.Err() != nil {
+			// shutting down
+			return time.Time{}, nil
+		}
+
+		d.Attempts++
+		if d.Attempts >= s.attempts {
+			s.logger.Debugf("webhook: deliver %s to %s: %v", d.Type, d.Endpoint, err)
+			s.logger.Warningf("webhook: dropping %s event after %d failed attempts to deliver it to %s", d.Type, d.Attempts, d.Endpoint)
+			if err := s.store.Delete(key); err != nil {
+				return time.Time{}, err
+			}
+			continue
+		}
+		s.logger.Debugf("webhook: deliver %s to %s, attempt %d: %v", d.Type, d.Endpoint, d.Attempts, err)
+
+		d.NextAttempt = s.now().Add(retryInterval(s.interval, d.Attempts))
+		if err := s.store.Put(key, &d); err != nil {
+			return time.Time{}, err
+		}
+		if next.IsZero() || d.NextAttempt.Before(next) {
+			next = d.NextAttempt
+		}
+	}
+	return next, nil
+}
+
+func (s *Service) post(d *delivery) error {
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, d.Endpoint, bytes.NewReader(d.Body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventTypeHeader, d.Type)
+	req.Header.Set(SignatureHeader, hex.EncodeToString(d.Signature))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return nil
+}
+
+// Close stops the delivery of the events, the undelivered
+// ones are delivered after a restart.
+func (s *Service) Close() error {
+	s.cancel()
+	s.wg.Wait()
+	return nil
+}
+
+// retryInterval returns the time waited before the next attempt after the
+// given number of failed attempts, doubling the interval with every attempt.
+func retryInterval(interval time.Duration, attempts int) time.Duration {
+	for i := 1; i < attempts && interval < maxRetryInterval; i++ {
+		interval *= 2
+	}
+	if interval > maxRetryInterval {
+		return maxRetryInterval
+	}
+	return interval
+}
+
+func outboxKey(id uint64, endpoint int) string {
+	return fmt.Sprintf("%s%020d_%03d", outboxKeyPrefix, id, endpoint)
+}
@@ -0,0 +1,223 @@
+// Copyright 2021 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webhook_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/logging"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/webhook"
+)
+
+type request struct {
+	eventType string
+	signature string
+	body      []byte
+}
+
+// newEndpoint returns the URL of an endpoint responding with the given
+// statuses in turn, and with 200 once they are exhausted.
+func newEndpoint(t *testing.T, statuses ...int) (string, <-chan request) {
+	t.Helper()
+
+	var (
+		mu        sync.Mutex
+		requestsC = make(chan request, 10)
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		requestsC <- request{
+			eventType: r.Header.Get(webhook.EventTypeHeader),
+			signature: r.Header.Get(webhook.SignatureHeader),
+			body:      body,
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if len(statuses) > 0 {
+			w.WriteHeader(statuses[0])
+			statuses = statuses[1:]
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts.URL, requestsC
+}
+
+func newService(t *testing.T, o webhook.Options) (*webhook.Service, crypto.Signer) {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(key)
+	s, err := webhook.New(statestore.NewStateStore(), signer, swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c"), logging.New(ioutil.Discard, 0), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := s.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	return s, signer
+}
+
+func receive(t *testing.T, requestsC <-chan request) request {
+	t.Helper()
+
+	select {
+	case r := <-requestsC:
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the event")
+	}
+	return request{}
+}
+
+func TestDelivery(t *testing.T) {
+	endpoint, requestsC := newEndpoint(t)
+	s, signer := newService(t, webhook.Options{Endpoints: []string{endpoint}})
+
+	s.PeerBlocklisted(swarm.MustParseHexAddress("01"), time.Minute)
+	s.PeerBlocklisted(swarm.MustParseHexAddress("02"), 0)
+
+	for i, overlay := range []string{"01", "02"} {
+		r := receive(t, requestsC)
+		if r.eventType != webhook.EventPeerBlocklisted {
+			t.Fatalf("got event type %q, want %q", r.eventType, webhook.EventPeerBlocklisted)
+		}
+
+		signature, err := hex.DecodeString(r.signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubKey, err := crypto.Recover(signature, r.body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := signer.PublicKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !pubKey.Equal(want) {
+			t.Fatal("signature not made by the node")
+		}
+
+		var e struct {
+			ID   uint64 `json:"id"`
+			Type string `json:"type"`
+			Data struct {
+				Overlay swarm.Address `json:"overlay"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(r.body, &e); err != nil {
+			t.Fatal(err)
+		}
+		if e.ID != uint64(i+1) || e.Type != webhook.EventPeerBlocklisted || e.Data.Overlay.String() != overlay {
+			t.Fatalf("got event %+v, want id %d for overlay %s", e, i+1, overlay)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	endpoint, requestsC := newEndpoint(t, http.StatusInternalServerError, http.StatusBadGateway)
+	s, _ := newService(t, webhook.Options{
+		Endpoints:     []string{endpoint},
+		RetryInterval: 10 * time.Millisecond,
+	})
+
+	s.PeerBlocklisted(swarm.MustParseHexAddress("01"), time.Minute)
+
+	var bodies [][]byte
+	for i := 0; i < 3; i++ {
+		bodies = append(bodies, receive(t, requestsC).body)
+	}
+	for _, b := range bodies[1:] {
+		if string(b) != string(bodies[0]) {
+			t.Fatalf("got retried body %s, want %s", b, bodies[0])
+		}
+	}
+
+	select {
+	case r := <-requestsC:
+		t.Fatalf("got unexpected delivery %s", r.body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestDropAfterMaxAttempts(t *testing.T) {
+	endpoint, requestsC := newEndpoint(t, http.StatusInternalServerError, http.StatusInternalServerError)
+	s, _ := newService(t, webhook.Options{
+		Endpoints:     []string{endpoint},
+		MaxAttempts:   2,
+		RetryInterval: 10 * time.Millisecond,
+	})
+
+	s.PeerBlocklisted(swarm.MustParseHexAddress("01"), time.Minute)
+	receive(t, requestsC)
+	receive(t, requestsC)
+
+	select {
+	case r := <-requestsC:
+		t.Fatalf("got delivery after max attempts %s", r.body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+type radiusSetter struct {
+	radius uint8
+}
+
+func (r *radiusSetter) SetRadius(radius uint8) {
+	r.radius = radius
+}
+
+func TestRadiusSetter(t *testing.T) {
+	endpoint, requestsC := newEndpoint(t)
+	s, _ := newService(t, webhook.Options{Endpoints: []string{endpoint}})
+
+	next := new(radiusSetter)
+	r := s.RadiusSetter(next)
+
+	// the initial radius and the unchanged ones are not notified
+	for _, radius := range []uint8{0, 0, 2, 2} {
+		r.SetRadius(radius)
+		if next.radius != radius {
+			t.Fatalf("got radius %d, want %d", next.radius, radius)
+		}
+	}
+
+	var e struct {
+		Data struct {
+			Radius uint8 `json:"radius"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(receive(t, requestsC).body, &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Data.Radius != 2 {
+		t.Fatalf("got radius %d, want 2", e.Data.Radius)
+	}
+
+	select {
+	case r := <-requestsC:
+		t.Fatalf("got unexpected delivery %s", r.body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}